@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strconv"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// enumCompletion lists the values of an argument bound to an enum field.
+type enumCompletion struct {
+	argument string
+	values   []string
+}
+
+// enumValueNames returns the names of the values of an enum.
+func enumValueNames(ed protoreflect.EnumDescriptor) []string {
+	values := ed.Values()
+	out := make([]string, values.Len())
+	for i := range values.Len() {
+		out[i] = string(values.Get(i).Name())
+	}
+	return out
+}
+
+// promptRef returns the expression of the completion reference of a
+// prompt.
+func promptRef(g *protogen.GeneratedFile, p *mcpPrompt) string {
+	return g.QualifiedGoIdent(protomcpPackage.Ident("PromptReference")) + "(" + strconv.Quote(p.name) + ")"
+}
+
+// resourceRef returns the expression of the completion reference of a
+// resource template.
+func resourceRef(g *protogen.GeneratedFile, r *mcpResource) string {
+	return g.QualifiedGoIdent(protomcpPackage.Ident("ResourceReference")) + "(" + strconv.Quote(r.uri) + ")"
+}
+
+// completionRefs returns the expressions of the completion references of
+// the resource templates and prompts of a service.
+func completionRefs(g *protogen.GeneratedFile, resources []*mcpResource, prompts []*mcpPrompt) []string {
+	var out []string
+	for _, r := range resources {
+		if r.template {
+			out = append(out, resourceRef(g, r))
+		}
+	}
+	for _, p := range prompts {
+		out = append(out, promptRef(g, p))
+	}
+	return out
+}
+
+// genEnumCompletions emits the registration of the completers of the
+// arguments bound to enum fields.
+func genEnumCompletions(g *protogen.GeneratedFile, ref string, enums []enumCompletion) {
+	enumCompleter := g.QualifiedGoIdent(protomcpPackage.Ident("EnumCompleter"))
+	for _, e := range enums {
+		args := make([]any, 0, 2*len(e.values)+6)
+		args = append(args, "s.AddCompletion(", ref, ", ", strconv.Quote(e.argument), ", ", enumCompleter, "(")
+		for i, v := range e.values {
+			if i > 0 {
+				args = append(args, ", ")
+			}
+			args = append(args, strconv.Quote(v))
+		}
+		g.P(append(args, "))")...)
+	}
+}
+
+// genServiceCompleter emits the registration of the service as completer
+// of the arguments of its prompts and resource templates, when it
+// implements protomcp.Completer.
+func genServiceCompleter(g *protogen.GeneratedFile, refs []string) {
+	if len(refs) == 0 {
+		return
+	}
+
+	g.P("if c, ok := srv.(", protomcpPackage.Ident("Completer"), "); ok {")
+	for _, ref := range refs {
+		g.P("s.AddCompletion(", ref, `, "", c)`)
+	}
+	g.P("}")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"protomcp.org/protomcp/pkg/generator/testutils"
+	"protomcp.org/protomcp/pkg/protomcp/options"
+)
+
+// newCompletionFile returns the prompt test file with a Role enum bound
+// to a prompt argument and to a variable of the GetUser resource
+// template.
+func newCompletionFile() *descriptorpb.FileDescriptorProto {
+	file := newPromptFile(&options.PromptOptions{
+		Name:      "review_user",
+		Arguments: "api.v1.ReviewRequest",
+	})
+	file.EnumType = append(file.EnumType, testutils.NewEnum("Role",
+		testutils.NewEnumValue("ROLE_UNSPECIFIED", 0),
+		testutils.NewEnumValue("ROLE_ADMIN", 1),
+	))
+
+	file.MessageType[0].Field = append(file.MessageType[0].Field,
+		testutils.NewEnumField("role", 2, ".api.v1.Role"))
+	file.MessageType[2].Field = append(file.MessageType[2].Field,
+		testutils.NewEnumField("role", 3, ".api.v1.Role"))
+
+	setMethodOption(file.Service[0].Method[0], options.E_Mcp, &options.MCPMethodOptions{
+		Resource: &options.ResourceOptions{UriTemplate: "users://{user_id}/{role}"},
+	})
+	return file
+}
+
+func TestGenCompletions(t *testing.T) {
+	t.Run("enums", testGenCompletionsEnums)
+	t.Run("service completer", testGenCompletionsService)
+	t.Run("nothing to complete", testGenCompletionsNone)
+}
+
+func testGenCompletionsEnums(t *testing.T) {
+	content := generateContent(t, newCompletionFile())
+
+	testutils.AssertContains(t, content, `s.AddCompletion(protomcp.ResourceReference("users://{user_id}/{role}"), `+
+		`"role", protomcp.EnumCompleter("ROLE_UNSPECIFIED", "ROLE_ADMIN"))`)
+	testutils.AssertContains(t, content, `s.AddCompletion(protomcp.PromptReference("review_user"), `+
+		`"role", protomcp.EnumCompleter("ROLE_UNSPECIFIED", "ROLE_ADMIN"))`)
+	testutils.AssertContains(t, content, `{Name: "role", Required: true},`)
+}
+
+func testGenCompletionsService(t *testing.T) {
+	content := generateContent(t, newCompletionFile())
+
+	testutils.AssertContains(t, content, `if c, ok := srv.(protomcp.Completer); ok {
+		s.AddCompletion(protomcp.ResourceReference("users://{user_id}/{role}"), "", c)
+		s.AddCompletion(protomcp.PromptReference("review_user"), "", c)
+	}`)
+}
+
+func testGenCompletionsNone(t *testing.T) {
+	content := generateContent(t, newTestFile())
+
+	testutils.AssertFalse(t, strings.Contains(content, "AddCompletion"), "completion added")
+}
@@ -23,6 +23,8 @@
 //   - HTTP/2 and QUIC transport servers
 //   - Mock implementations for testing
 //
+// Streaming methods are not supported yet, and are skipped with a warning.
+//
 // # Options
 //
 // The plugin supports various options through --protomcp_opt:
@@ -39,6 +41,14 @@
 //   - buf.validate: Validation rules for messages
 //   - protomcp.jsonrpc: JSON-RPC method options
 //   - protomcp.mcp: MCP tool/resource definitions
+//   - protomcp.mcp_service: MCP service options and prompt definitions
+//
+// The protomcp options are defined in proto/protomcp/options.proto, to be
+// imported as "protomcp/options.proto":
+//
+//	rpc GetUser(GetUserRequest) returns (User) {
+//	  option (protomcp.mcp).tool = { name: "get_user" title: "Get user" };
+//	}
 //
 // The generated code integrates with pkg/protomcp for runtime support.
 package main
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"protomcp.org/protomcp/pkg/generator"
+)
+
+const (
+	// pluginName is the name the plugin reports in generated headers.
+	pluginName = "protoc-gen-protomcp"
+
+	// fileSuffix is appended to the generated filename prefix of each
+	// proto file to name its output.
+	fileSuffix = "_protomcp.pb.go"
+)
+
+var (
+	contextPackage  = protogen.GoImportPath("context")
+	protomcpPackage = protogen.GoImportPath("protomcp.org/protomcp/pkg/protomcp")
+)
+
+// Generate emits a `<file>_protomcp.pb.go` for every file in the request's
+// FileToGenerate that defines at least one service. Files without services
+// produce no output.
+func Generate(gen *protogen.Plugin) error {
+	gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+
+	for _, file := range gen.Files {
+		if !file.Generate {
+			continue
+		}
+
+		if len(file.Services) == 0 {
+			generator.Debug("%s: no services, skipping", file.Desc.Path())
+			continue
+		}
+
+		if err := generateFile(gen, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateFile emits the protomcp companion file for a single proto file.
+func generateFile(gen *protogen.Plugin, file *protogen.File) error {
+	filename := file.GeneratedFilenamePrefix + fileSuffix
+	generator.Debug("generating %s from %s", filename, file.Desc.Path())
+
+	g := gen.NewGeneratedFile(filename, file.GoImportPath)
+	genHeader(gen, file, g)
+
+	for _, svc := range file.Services {
+		generator.Trace("service %s: %d methods", svc.Desc.FullName(), len(svc.Methods))
+		if err := genService(g, svc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// genHeader writes the standard "generated code" preamble and the package
+// clause.
+func genHeader(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile) {
+	g.P("// Code generated by ", pluginName, ". DO NOT EDIT.")
+	g.P("// versions:")
+	g.P("// - ", pluginName, " ", pluginVersion())
+	g.P("// - protoc             ", protocVersion(gen))
+	if file.Proto.GetOptions().GetDeprecated() {
+		g.P("// ", file.Desc.Path(), " is a deprecated file.")
+	} else {
+		g.P("// source: ", file.Desc.Path())
+	}
+	g.P()
+	g.P("package ", file.GoPackageName)
+	g.P()
+}
+
+// protocVersion renders the compiler version recorded in the request, or
+// "(unknown)" when protoc did not provide one.
+func protocVersion(gen *protogen.Plugin) string {
+	v := gen.Request.GetCompilerVersion()
+	if v == nil {
+		return "(unknown)"
+	}
+
+	var buf generator.LazyBuffer
+	buf.Printf("v%d.%d.%d", v.GetMajor(), v.GetMinor(), v.GetPatch())
+	if s := v.GetSuffix(); s != "" {
+		buf.WriteString("-", s)
+	}
+	return buf.String()
+}
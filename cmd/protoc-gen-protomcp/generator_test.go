@@ -0,0 +1,209 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"protomcp.org/protomcp/pkg/generator/testutils"
+)
+
+// newTestFile returns a proto file with a UserService exposing a single
+// unary GetUser method.
+func newTestFile() *descriptorpb.FileDescriptorProto {
+	file := testutils.NewFileDescriptor("api.proto", "api.v1", "example.com/api/v1;apiv1")
+	file.Syntax = proto.String("proto3")
+	file.MessageType = append(file.MessageType,
+		testutils.NewMessage("GetUserRequest",
+			testutils.NewField("user_id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		),
+		testutils.NewMessage("User",
+			testutils.NewField("user_id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			testutils.NewField("name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		),
+	)
+	file.Service = append(file.Service, testutils.NewService("UserService",
+		testutils.NewMethod("GetUser", ".api.v1.GetUserRequest", ".api.v1.User"),
+	))
+	return file
+}
+
+// generateContent runs the plugin over the given files and returns the
+// content of the single file it is expected to produce.
+func generateContent(t *testing.T, files ...*descriptorpb.FileDescriptorProto) string {
+	t.Helper()
+
+	response := testutils.RunGenerator(t, testutils.NewCodeGenRequest(files...), Generate)
+	testutils.AssertFileCount(t, response, 1)
+	return response.File[0].GetContent()
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("file with services", testGenerateWithServices)
+	t.Run("file without services", testGenerateWithoutServices)
+	t.Run("dependency not generated", testGenerateDependency)
+	t.Run("supported features", testGenerateSupportedFeatures)
+}
+
+func testGenerateWithServices(t *testing.T) {
+	response := testutils.RunGenerator(t, testutils.NewCodeGenRequest(newTestFile()), Generate)
+
+	testutils.AssertFileCount(t, response, 1)
+	testutils.AssertEqual(t, response.File[0].GetName(), "example.com/api/v1/api_protomcp.pb.go", "filename")
+
+	content := response.File[0].GetContent()
+	testutils.AssertContains(t, content, "// Code generated by protoc-gen-protomcp. DO NOT EDIT.")
+	testutils.AssertContains(t, content, "// source: api.proto")
+	testutils.AssertContains(t, content, "package apiv1")
+}
+
+func testGenerateWithoutServices(t *testing.T) {
+	file := newTestFile()
+	file.Service = nil
+
+	response := testutils.RunGenerator(t, testutils.NewCodeGenRequest(file), Generate)
+	testutils.AssertFileCount(t, response, 0)
+}
+
+func testGenerateDependency(t *testing.T) {
+	dep := newTestFile()
+	dep.Name = proto.String("dep.proto")
+	dep.Package = proto.String("dep.v1")
+	dep.Options.GoPackage = proto.String("example.com/dep/v1")
+	dep.Service[0].Method[0].InputType = proto.String(".dep.v1.GetUserRequest")
+	dep.Service[0].Method[0].OutputType = proto.String(".dep.v1.User")
+
+	req := testutils.NewCodeGenRequest(dep, newTestFile())
+	req.FileToGenerate = []string{"api.proto"}
+
+	response := testutils.RunGenerator(t, req, Generate)
+	testutils.AssertFileCount(t, response, 1)
+	testutils.AssertEqual(t, response.File[0].GetName(), "example.com/api/v1/api_protomcp.pb.go", "filename")
+}
+
+func testGenerateSupportedFeatures(t *testing.T) {
+	response := testutils.RunGenerator(t, testutils.NewCodeGenRequest(newTestFile()), Generate)
+
+	want := uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
+	testutils.AssertEqual(t, response.GetSupportedFeatures()&want, want, "supported features")
+}
+
+func TestGenerateDeprecatedFile(t *testing.T) {
+	file := newTestFile()
+	file.Options.Deprecated = proto.Bool(true)
+
+	content := generateContent(t, file)
+	testutils.AssertContains(t, content, "// api.proto is a deprecated file.")
+}
+
+func TestProtocVersion(t *testing.T) {
+	t.Run("unknown", func(t *testing.T) {
+		gen := &protogen.Plugin{Request: &pluginpb.CodeGeneratorRequest{}}
+		testutils.AssertEqual(t, protocVersion(gen), "(unknown)", "version")
+	})
+
+	t.Run("release", func(t *testing.T) {
+		gen := &protogen.Plugin{Request: &pluginpb.CodeGeneratorRequest{
+			CompilerVersion: &pluginpb.Version{
+				Major: proto.Int32(5),
+				Minor: proto.Int32(29),
+				Patch: proto.Int32(3),
+			},
+		}}
+		testutils.AssertEqual(t, protocVersion(gen), "v5.29.3", "version")
+	})
+
+	t.Run("with suffix", func(t *testing.T) {
+		gen := &protogen.Plugin{Request: &pluginpb.CodeGeneratorRequest{
+			CompilerVersion: &pluginpb.Version{
+				Major:  proto.Int32(6),
+				Minor:  proto.Int32(30),
+				Patch:  proto.Int32(0),
+				Suffix: proto.String("rc1"),
+			},
+		}}
+		testutils.AssertEqual(t, protocVersion(gen), "v6.30.0-rc1", "version")
+	})
+}
+
+// newCompileFile returns the proto file of testdata/api.pb.go, with a
+// service exposing tools, resources, prompts and completions.
+func newCompileFile() *descriptorpb.FileDescriptorProto {
+	file := newCompletionFile()
+	file.Service[0].Method = append(file.Service[0].Method,
+		testutils.NewMethod("DeleteUser", ".api.v1.GetUserRequest", ".api.v1.User"))
+	return file
+}
+
+// TestGenerateCompiles builds the code generated for newCompileFile along
+// with the output of protoc-gen-go for the same file, kept in testdata.
+func TestGenerateCompiles(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	content := generateContent(t, newCompileFile())
+	testutils.AssertContains(t, content, "s.AddTool(")
+	testutils.AssertContains(t, content, "s.AddResourceTemplate(")
+	testutils.AssertContains(t, content, "s.AddPromptTemplate(")
+	testutils.AssertContains(t, content, "s.AddCompletion(")
+
+	dir := newCompileModule(t)
+	writeCompileFile(t, filepath.Join(dir, "api_protomcp.pb.go"), []byte(content))
+
+	cmd := exec.Command(goTool, "build", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated code doesn't compile: %v\n%s", err, out)
+	}
+}
+
+// newCompileModule returns a temporary module holding testdata/api.pb.go,
+// using the runtime of this repository.
+func newCompileModule(t *testing.T) string {
+	t.Helper()
+
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runtime := filepath.Join(root, "pkg", "protomcp")
+
+	goMod := "module example.com/api/v1\n\ngo 1.23.0\n\n" +
+		"require protomcp.org/protomcp/pkg/protomcp v0.0.0-00010101000000-000000000000\n\n" +
+		"replace (\n" +
+		"\tprotomcp.org/protomcp/pkg/generator => " + filepath.Join(root, "pkg", "generator") + "\n" +
+		"\tprotomcp.org/protomcp/pkg/protomcp => " + runtime + "\n" +
+		")\n"
+
+	dir := t.TempDir()
+	writeCompileFile(t, filepath.Join(dir, "go.mod"), []byte(goMod))
+	for src, dst := range map[string]string{
+		filepath.Join(runtime, "go.sum"):       "go.sum",
+		filepath.Join("testdata", "api.pb.go"): "api.pb.go",
+	} {
+		b, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeCompileFile(t, filepath.Join(dir, dst), b)
+	}
+	return dir
+}
+
+// writeCompileFile writes a file of the temporary module.
+func writeCompileFile(t *testing.T, name string, data []byte) {
+	t.Helper()
+
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strconv"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// emptyMessage is the full name of google.protobuf.Empty.
+const emptyMessage = "google.protobuf.Empty"
+
+// genRegisterJSONRPC emits the `Register<Service>JSONRPC` function routing
+// the JSON-RPC methods of a service to a `<Service>Server`. Methods
+// returning google.protobuf.Empty can also be called as notifications,
+// unless `(protomcp.jsonrpc)` options say otherwise.
+func genRegisterJSONRPC(g *protogen.GeneratedFile, svc *protogen.Service) {
+	dispatcher := g.QualifiedGoIdent(protomcpPackage.Ident("Dispatcher"))
+	newHandler := g.QualifiedGoIdent(protomcpPackage.Ident("NewMethodHandler"))
+
+	g.P("// ", registerJSONRPCName(svc), " registers the methods of srv on d under")
+	g.P("// their fully-qualified names, or the names set by their options.")
+	g.P("func ", registerJSONRPCName(svc), "(d *", dispatcher, ", srv ", serverName(svc), ") {")
+	for _, m := range serverMethods(svc) {
+		g.P("d.", registerFunc(m), "(", jsonrpcMethodName(m), ", ", newHandler, "(srv.", m.GoName, "))")
+	}
+	g.P("}")
+	g.P()
+}
+
+// registerJSONRPCName returns the Go name of the JSON-RPC registration
+// function of a service.
+func registerJSONRPCName(svc *protogen.Service) string {
+	return "Register" + svc.GoName + "JSONRPC"
+}
+
+// jsonrpcMethodName returns the Go expression of the JSON-RPC method name
+// of a method.
+func jsonrpcMethodName(m *protogen.Method) string {
+	if name := jsonrpcMethodOptions(m).GetMethod(); name != "" {
+		return strconv.Quote(name)
+	}
+	return methodNameConst(m)
+}
+
+// registerFunc returns the Dispatcher method used to register a method.
+func registerFunc(m *protogen.Method) string {
+	if isNotification(m) {
+		return "RegisterNotification"
+	}
+	return "Register"
+}
+
+// isNotification tells if a method can be called as a JSON-RPC
+// notification.
+func isNotification(m *protogen.Method) bool {
+	if opts := jsonrpcMethodOptions(m); opts != nil && opts.Notification != nil {
+		return opts.GetNotification()
+	}
+	return m.Output.Desc.FullName() == emptyMessage
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"protomcp.org/protomcp/pkg/generator/testutils"
+	"protomcp.org/protomcp/pkg/protomcp/options"
+)
+
+func TestGenRegisterJSONRPC(t *testing.T) {
+	t.Run("unary methods", testGenRegisterJSONRPCUnary)
+	t.Run("streaming methods skipped", testGenRegisterJSONRPCStreaming)
+	t.Run("empty response", testGenRegisterJSONRPCNotification)
+	t.Run("options", testGenRegisterJSONRPCOptions)
+}
+
+func testGenRegisterJSONRPCUnary(t *testing.T) {
+	content := generateContent(t, newTestFile())
+
+	testutils.AssertContains(t, content,
+		"func RegisterUserServiceJSONRPC(d *protomcp.Dispatcher, srv UserServiceServer) {")
+	testutils.AssertContains(t, content,
+		"d.Register(UserService_GetUser_FullMethodName, protomcp.NewMethodHandler(srv.GetUser))")
+}
+
+func testGenRegisterJSONRPCStreaming(t *testing.T) {
+	file := newTestFile()
+	watch := testutils.NewMethod("WatchUser", ".api.v1.GetUserRequest", ".api.v1.User")
+	watch.ServerStreaming = proto.Bool(true)
+	file.Service[0].Method = append(file.Service[0].Method, watch)
+
+	content := generateContent(t, file)
+	testutils.AssertFalse(t, strings.Contains(content, "srv.WatchUser"), "streaming method registered")
+}
+
+func testGenRegisterJSONRPCNotification(t *testing.T) {
+	file := newTestFile()
+	file.Dependency = append(file.Dependency, "google/protobuf/empty.proto")
+	file.Service[0].Method = append(file.Service[0].Method,
+		testutils.NewMethod("Touch", ".api.v1.GetUserRequest", ".google.protobuf.Empty"))
+
+	req := testutils.NewCodeGenRequest(protodesc.ToFileDescriptorProto(emptypb.File_google_protobuf_empty_proto), file)
+	req.FileToGenerate = []string{file.GetName()}
+
+	response := testutils.RunGenerator(t, req, Generate)
+	testutils.AssertFileCount(t, response, 1)
+	content := response.File[0].GetContent()
+
+	testutils.AssertContains(t, content,
+		"d.Register(UserService_GetUser_FullMethodName, protomcp.NewMethodHandler(srv.GetUser))")
+	testutils.AssertContains(t, content,
+		"d.RegisterNotification(UserService_Touch_FullMethodName, protomcp.NewMethodHandler(srv.Touch))")
+}
+
+func testGenRegisterJSONRPCOptions(t *testing.T) {
+	file := newTestFile()
+	m := file.Service[0].Method[0]
+	setMethodOption(m, options.E_Jsonrpc, &options.JSONRPCMethodOptions{
+		Method:       "users.get",
+		Notification: proto.Bool(true),
+	})
+
+	content := generateContent(t, file)
+	testutils.AssertContains(t, content,
+		`d.RegisterNotification("users.get", protomcp.NewMethodHandler(srv.GetUser))`)
+}
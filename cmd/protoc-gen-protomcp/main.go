@@ -1,12 +1,46 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"runtime/debug"
+
+	"google.golang.org/protobuf/compiler/protogen"
 )
 
 func main() {
-	// TODO: Implement protoc plugin for Go code generation
-	_, _ = fmt.Fprintf(os.Stderr, "protoc-gen-protomcp: not yet implemented\n")
-	os.Exit(1)
+	if len(os.Args) == 2 && os.Args[1] == "--version" {
+		_, _ = fmt.Fprintf(os.Stdout, "%s %s\n", pluginName, pluginVersion())
+		os.Exit(0)
+	}
+
+	var flags flag.FlagSet
+	logging := flags.Bool("logging", false, "enable debug logging during generation")
+
+	opts := protogen.Options{
+		ParamFunc: flags.Set,
+	}
+
+	opts.Run(func(gen *protogen.Plugin) error {
+		if *logging {
+			enableLogging()
+		}
+		return Generate(gen)
+	})
+}
+
+// enableLogging turns on the generator's debug output for the
+// remainder of the run.
+func enableLogging() {
+	_ = os.Setenv("PROTOMCP_DEBUG", "1")
+}
+
+// pluginVersion returns the version of the plugin as recorded in the
+// build information, or "(devel)" when unavailable.
+func pluginVersion() string {
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" {
+		return bi.Main.Version
+	}
+	return "(devel)"
 }
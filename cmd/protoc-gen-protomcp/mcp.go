@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"protomcp.org/protomcp/pkg/generator"
+)
+
+var jsonPackage = protogen.GoImportPath("encoding/json")
+
+// mcpTool is the MCP tool generated for a method.
+type mcpTool struct {
+	method       *protogen.Method
+	annotations  *toolAnnotations
+	name         string
+	title        string
+	description  string
+	inputSchema  string
+	outputSchema string
+}
+
+// genRegisterMCP emits the `Register<Service>MCP` function exposing the
+// unary methods of a service as MCP tools or resources, as customised by
+// their `(protomcp.mcp)` options, along with the prompts of the service
+// and the completion of their arguments.
+func genRegisterMCP(g *protogen.GeneratedFile, svc *protogen.Service) error {
+	tools, err := mcpTools(svc)
+	if err != nil {
+		return err
+	}
+	resources, err := mcpResources(svc)
+	if err != nil {
+		return err
+	}
+	prompts, err := mcpPrompts(svc)
+	if err != nil {
+		return err
+	}
+
+	server := g.QualifiedGoIdent(protomcpPackage.Ident("MCPServer"))
+	newHandler := g.QualifiedGoIdent(protomcpPackage.Ident("NewMethodHandler"))
+
+	g.P("// ", registerMCPName(svc), " registers the methods of srv on s as MCP")
+	g.P("// tools and resources, along with its prompts. If srv implements")
+	g.P("// protomcp.Completer it completes their arguments.")
+	g.P("func ", registerMCPName(svc), "(s *", server, ", srv ", serverName(svc), ") {")
+	for _, t := range tools {
+		g.P("s.AddTool(", toolLiteral(g, t), ", ", newHandler, "(srv.", t.method.GoName, "))")
+	}
+	for _, r := range resources {
+		genAddResource(g, r)
+	}
+	for _, p := range prompts {
+		genAddPrompt(g, p)
+	}
+	genServiceCompleter(g, completionRefs(g, resources, prompts))
+	g.P("}")
+	g.P()
+	return nil
+}
+
+// toolLiteral returns the protomcp.Tool literal describing a tool.
+func toolLiteral(g *protogen.GeneratedFile, t *mcpTool) string {
+	var buf generator.LazyBuffer
+	buf.WriteString("&", g.QualifiedGoIdent(protomcpPackage.Ident("Tool")), "{\n")
+	buf.WriteString("Name: ", strconv.Quote(t.name), ",\n")
+	if t.title != "" {
+		buf.WriteString("Title: ", strconv.Quote(t.title), ",\n")
+	}
+	if t.description != "" {
+		buf.WriteString("Description: ", goString(t.description), ",\n")
+	}
+	rawMessage := g.QualifiedGoIdent(jsonPackage.Ident("RawMessage"))
+	buf.WriteString("InputSchema: ", rawMessage, "(", goString(t.inputSchema), "),\n")
+	if t.outputSchema != "" {
+		buf.WriteString("OutputSchema: ", rawMessage, "(", goString(t.outputSchema), "),\n")
+	}
+	if t.annotations != nil {
+		buf.WriteString("Annotations: ", annotationsLiteral(g, t.annotations), ",\n")
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// mcpTools returns the tools generated for the methods of a service.
+// Hidden methods, those exposed as resources, and those whose request
+// isn't encoded as a JSON object and so can't take tool arguments, are
+// skipped.
+func mcpTools(svc *protogen.Service) ([]*mcpTool, error) {
+	if mcpServiceOptions(svc).GetHidden() {
+		generator.Trace("%s: hidden from MCP", svc.Desc.FullName())
+		return nil, nil
+	}
+
+	var out []*mcpTool
+	names := make(map[string]string)
+	for _, m := range serverMethods(svc) {
+		t, err := mcpToolOf(m)
+		switch {
+		case err != nil:
+			return nil, fmt.Errorf("%s: %w", fullMethodName(m), err)
+		case t == nil:
+			continue
+		case names[t.name] != "":
+			return nil, fmt.Errorf("%s: tool %q already used by %s", fullMethodName(m), t.name, names[t.name])
+		}
+
+		names[t.name] = fullMethodName(m)
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// mcpToolOf returns the tool generated for a method, or nil if the method
+// isn't exposed as a tool.
+func mcpToolOf(m *protogen.Method) (*mcpTool, error) {
+	opts := mcpMethodOptions(m)
+	switch {
+	case opts.GetTool().GetHidden():
+		generator.Trace("%s: hidden from MCP", fullMethodName(m))
+		return nil, nil
+	case opts.GetResource() != nil:
+		return nil, nil
+	}
+
+	schema := messageSchema(m.Input)
+	if !isObjectSchema(schema) {
+		generator.Trace("%s: request isn't an object, not a tool", fullMethodName(m))
+		return nil, nil
+	}
+	return newMCPTool(m, schema)
+}
+
+// newMCPTool describes the tool of a method given the schema of its
+// request. The output schema is only set when the response is encoded
+// as a JSON object, as MCP requires for structured content.
+func newMCPTool(m *protogen.Method, input jsonSchema) (*mcpTool, error) {
+	opts := mcpMethodOptions(m).GetTool()
+	t := &mcpTool{
+		method:      m,
+		name:        toolName(m),
+		title:       opts.GetTitle(),
+		description: commentText(m.Comments.Leading),
+		annotations: newToolAnnotations(m),
+	}
+	if name := opts.GetName(); name != "" {
+		t.name = name
+	}
+	if desc := opts.GetDescription(); desc != "" {
+		t.description = desc
+	}
+
+	var err error
+	t.inputSchema, err = marshalSchema(input)
+	if err != nil {
+		return nil, fmt.Errorf("input schema: %w", err)
+	}
+
+	if output := messageSchema(m.Output); isObjectSchema(output) {
+		t.outputSchema, err = marshalSchema(output)
+		if err != nil {
+			return nil, fmt.Errorf("output schema: %w", err)
+		}
+	}
+	return t, nil
+}
+
+// toolAnnotations are the behaviour hints of a tool. Nil hints are left
+// to the defaults of the MCP specification.
+type toolAnnotations struct {
+	readOnly    *bool
+	destructive *bool
+	idempotent  *bool
+	openWorld   *bool
+}
+
+// newToolAnnotations derives the hints of a tool from the idempotency
+// level of its method, overridden by the hints set in its
+// `(protomcp.mcp).tool` options. It returns nil if there are none.
+func newToolAnnotations(m *protogen.Method) *toolAnnotations {
+	a := new(toolAnnotations)
+	switch idempotencyLevel(m) {
+	case descriptorpb.MethodOptions_NO_SIDE_EFFECTS:
+		a.readOnly = proto.Bool(true)
+	case descriptorpb.MethodOptions_IDEMPOTENT:
+		a.idempotent = proto.Bool(true)
+	}
+
+	if opts := mcpMethodOptions(m).GetTool(); opts != nil {
+		a.readOnly = coalesce(opts.ReadOnly, a.readOnly)
+		a.destructive = coalesce(opts.Destructive, a.destructive)
+		a.idempotent = coalesce(opts.Idempotent, a.idempotent)
+		a.openWorld = coalesce(opts.OpenWorld, a.openWorld)
+	}
+
+	if *a == (toolAnnotations{}) {
+		return nil
+	}
+	return a
+}
+
+// annotationsLiteral returns the protomcp.ToolAnnotations literal of some
+// tool hints.
+func annotationsLiteral(g *protogen.GeneratedFile, a *toolAnnotations) string {
+	hints := []struct {
+		v    *bool
+		name string
+	}{
+		{a.readOnly, "ReadOnlyHint"},
+		{a.destructive, "DestructiveHint"},
+		{a.idempotent, "IdempotentHint"},
+		{a.openWorld, "OpenWorldHint"},
+	}
+
+	var buf generator.LazyBuffer
+	boolFunc := g.QualifiedGoIdent(protomcpPackage.Ident("Bool"))
+	buf.WriteString("&", g.QualifiedGoIdent(protomcpPackage.Ident("ToolAnnotations")), "{\n")
+	for _, h := range hints {
+		if h.v != nil {
+			buf.WriteString(h.name, ": ", boolFunc, "(", strconv.FormatBool(*h.v), "),\n")
+		}
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// idempotencyLevel returns the idempotency level of a method.
+func idempotencyLevel(m *protogen.Method) descriptorpb.MethodOptions_IdempotencyLevel {
+	opts, ok := m.Desc.Options().(*descriptorpb.MethodOptions)
+	if !ok {
+		return descriptorpb.MethodOptions_IDEMPOTENCY_UNKNOWN
+	}
+	return opts.GetIdempotencyLevel()
+}
+
+// coalesce returns the first non-nil value.
+func coalesce[T any](values ...*T) *T {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// registerMCPName returns the Go name of the MCP registration function of
+// a service.
+func registerMCPName(svc *protogen.Service) string {
+	return "Register" + svc.GoName + "MCP"
+}
+
+// toolName returns the MCP tool name of a method.
+func toolName(m *protogen.Method) string {
+	return string(m.Parent.Desc.Name()) + "_" + string(m.Desc.Name())
+}
+
+// goString returns a Go string literal, raw when possible for readability.
+func goString(s string) string {
+	if strings.ContainsAny(s, "`\r") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
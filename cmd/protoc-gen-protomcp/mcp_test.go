@@ -0,0 +1,213 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"protomcp.org/protomcp/pkg/generator/testutils"
+	"protomcp.org/protomcp/pkg/protomcp/options"
+)
+
+func TestGenRegisterMCP(t *testing.T) {
+	t.Run("tools", testGenRegisterMCPTools)
+	t.Run("description", testGenRegisterMCPDescription)
+	t.Run("non-object request", testGenRegisterMCPNonObject)
+	t.Run("non-object response", testGenRegisterMCPNonObjectOutput)
+	t.Run("tool options", testGenRegisterMCPToolOptions)
+	t.Run("hidden tool", testGenRegisterMCPHiddenTool)
+	t.Run("hidden service", testGenRegisterMCPHiddenService)
+	t.Run("duplicate tool", testGenRegisterMCPDuplicateTool)
+}
+
+func testGenRegisterMCPTools(t *testing.T) {
+	content := generateContent(t, newTestFile())
+
+	testutils.AssertContains(t, content,
+		"func RegisterUserServiceMCP(s *protomcp.MCPServer, srv UserServiceServer) {")
+	testutils.AssertContains(t, content, `s.AddTool(&protomcp.Tool{
+		Name:         "UserService_GetUser",
+		InputSchema:  json.RawMessage(`+"`"+`{"properties":{"userId":{"type":"string"}},"type":"object"}`+"`"+`),
+		OutputSchema: json.RawMessage(`+"`"+`{"properties":{"name":{"type":"string"},`+
+		`"userId":{"type":"string"}},"type":"object"}`+"`"+`),
+	}, protomcp.NewMethodHandler(srv.GetUser))`)
+	testutils.AssertContains(t, content, `json "encoding/json"`)
+}
+
+func testGenRegisterMCPDescription(t *testing.T) {
+	file := newTestFile()
+	file.SourceCodeInfo = &descriptorpb.SourceCodeInfo{
+		Location: []*descriptorpb.SourceCodeInfo_Location{
+			newLocation(" GetUser returns a user\n by `id`.\n", 6, 0, 2, 0),
+		},
+	}
+
+	content := generateContent(t, file)
+	testutils.AssertContains(t, content, `Description:  "GetUser returns a user\nby `+"`id`"+`.",`)
+}
+
+func testGenRegisterMCPNonObject(t *testing.T) {
+	file := newTestFile()
+	file.Dependency = append(file.Dependency, "google/protobuf/wrappers.proto")
+	file.Service[0].Method = append(file.Service[0].Method,
+		testutils.NewMethod("Lookup", ".google.protobuf.StringValue", ".api.v1.User"))
+
+	req := testutils.NewCodeGenRequest(append(wellKnownFiles(), file)...)
+	req.FileToGenerate = []string{file.GetName()}
+	response := testutils.RunGenerator(t, req, Generate)
+	testutils.AssertFileCount(t, response, 1)
+
+	content := response.File[0].GetContent()
+	testutils.AssertContains(t, content, "protomcp.NewMethodHandler(srv.Lookup)")
+	testutils.AssertFalse(t, strings.Contains(content, `"UserService_Lookup"`), "non-object request as tool")
+}
+
+func testGenRegisterMCPNonObjectOutput(t *testing.T) {
+	file := newTestFile()
+	file.Dependency = append(file.Dependency, "google/protobuf/wrappers.proto")
+	file.Service[0].Method = append(file.Service[0].Method,
+		testutils.NewMethod("GetName", ".api.v1.GetUserRequest", ".google.protobuf.StringValue"))
+
+	req := testutils.NewCodeGenRequest(append(wellKnownFiles(), file)...)
+	req.FileToGenerate = []string{file.GetName()}
+	response := testutils.RunGenerator(t, req, Generate)
+	testutils.AssertFileCount(t, response, 1)
+
+	testutils.AssertContains(t, response.File[0].GetContent(), `s.AddTool(&protomcp.Tool{
+		Name:        "UserService_GetName",
+		InputSchema: json.RawMessage(`+"`"+`{"properties":{"userId":{"type":"string"}},"type":"object"}`+"`"+`),
+	}, protomcp.NewMethodHandler(srv.GetName))`)
+}
+
+func testGenRegisterMCPToolOptions(t *testing.T) {
+	file := newTestFile()
+	setMethodOption(file.Service[0].Method[0], options.E_Mcp, &options.MCPMethodOptions{
+		Tool: &options.ToolOptions{
+			Name:        "get_user",
+			Title:       "Get user",
+			Description: "Looks up a user.",
+		},
+	})
+
+	content := generateContent(t, file)
+	testutils.AssertContains(t, content, `s.AddTool(&protomcp.Tool{
+		Name:         "get_user",
+		Title:        "Get user",
+		Description:  `+"`Looks up a user.`"+`,`)
+}
+
+func testGenRegisterMCPHiddenTool(t *testing.T) {
+	file := newTestFile()
+	setMethodOption(file.Service[0].Method[0], options.E_Mcp, &options.MCPMethodOptions{
+		Tool: &options.ToolOptions{Hidden: true},
+	})
+
+	content := generateContent(t, file)
+	testutils.AssertContains(t, content, "func RegisterUserServiceMCP(")
+	testutils.AssertFalse(t, strings.Contains(content, "s.AddTool("), "hidden tool added")
+	testutils.AssertContains(t, content, "d.Register(UserService_GetUser_FullMethodName")
+}
+
+func testGenRegisterMCPHiddenService(t *testing.T) {
+	file := newTestFile()
+	setServiceOption(file.Service[0], options.E_McpService, &options.MCPServiceOptions{Hidden: true})
+
+	content := generateContent(t, file)
+	testutils.AssertFalse(t, strings.Contains(content, "s.AddTool("), "hidden service tool added")
+}
+
+func testGenRegisterMCPDuplicateTool(t *testing.T) {
+	file := newTestFile()
+	file.Service[0].Method = append(file.Service[0].Method,
+		testutils.NewMethod("FindUser", ".api.v1.GetUserRequest", ".api.v1.User"))
+	setMethodOption(file.Service[0].Method[1], options.E_Mcp, &options.MCPMethodOptions{
+		Tool: &options.ToolOptions{Name: "UserService_GetUser"},
+	})
+
+	plugin, err := testutils.NewPlugin(t, file)
+	testutils.AssertNoError(t, err, "plugin")
+	testutils.AssertError(t, Generate(plugin), "duplicate tool name")
+}
+
+// toolAnnotationsTestCase represents a test case for the generated tool
+// annotations
+type toolAnnotationsTestCase struct {
+	opts  *options.ToolOptions
+	name  string
+	want  string
+	level descriptorpb.MethodOptions_IdempotencyLevel
+}
+
+// test runs the tool annotations test case
+func (tc toolAnnotationsTestCase) test(t *testing.T) {
+	file := newTestFile()
+	m := file.Service[0].Method[0]
+	m.Options = &descriptorpb.MethodOptions{IdempotencyLevel: tc.level.Enum()}
+	if tc.opts != nil {
+		setMethodOption(m, options.E_Mcp, &options.MCPMethodOptions{Tool: tc.opts})
+	}
+
+	content := generateContent(t, file)
+	if tc.want == "" {
+		testutils.AssertFalse(t, strings.Contains(content, "Annotations:"), "annotations generated")
+		return
+	}
+	testutils.AssertContains(t, content, tc.want)
+}
+
+func TestGenToolAnnotations(t *testing.T) {
+	tests := []toolAnnotationsTestCase{
+		{
+			name: "unknown",
+		},
+		{
+			name:  "no side effects",
+			level: descriptorpb.MethodOptions_NO_SIDE_EFFECTS,
+			want: `Annotations: &protomcp.ToolAnnotations{
+			ReadOnlyHint: protomcp.Bool(true),
+		},`,
+		},
+		{
+			name:  "idempotent",
+			level: descriptorpb.MethodOptions_IDEMPOTENT,
+			want: `Annotations: &protomcp.ToolAnnotations{
+			IdempotentHint: protomcp.Bool(true),
+		},`,
+		},
+		{
+			name:  "overridden",
+			level: descriptorpb.MethodOptions_NO_SIDE_EFFECTS,
+			opts: &options.ToolOptions{
+				ReadOnly:    proto.Bool(false),
+				Destructive: proto.Bool(false),
+				OpenWorld:   proto.Bool(false),
+			},
+			want: `Annotations: &protomcp.ToolAnnotations{
+			ReadOnlyHint:    protomcp.Bool(false),
+			DestructiveHint: protomcp.Bool(false),
+			OpenWorldHint:   protomcp.Bool(false),
+		},`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestToolName(t *testing.T) {
+	plugin, err := testutils.NewPlugin(t, newTestFile())
+	if err != nil {
+		t.Fatalf("failed to create plugin: %v", err)
+	}
+
+	m := plugin.Files[0].Services[0].Methods[0]
+	testutils.AssertEqual(t, toolName(m), "UserService_GetUser", "tool name")
+}
+
+func TestGoString(t *testing.T) {
+	testutils.AssertEqual(t, goString(`{"a":"b"}`), "`{\"a\":\"b\"}`", "raw string")
+	testutils.AssertEqual(t, goString("a `b`"), "\"a `b`\"", "quoted string")
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"protomcp.org/protomcp/pkg/protomcp/options"
+)
+
+// getExtension returns the value of an extension of some descriptor
+// options, or nil if not set.
+func getExtension[T proto.Message](opts proto.Message, xt protoreflect.ExtensionType) T {
+	var zero T
+	if opts == nil || !proto.HasExtension(opts, xt) {
+		return zero
+	}
+
+	v, ok := proto.GetExtension(opts, xt).(T)
+	if !ok {
+		return zero
+	}
+	return v
+}
+
+// mcpServiceOptions returns the `(protomcp.mcp_service)` options of a
+// service, or nil.
+func mcpServiceOptions(svc *protogen.Service) *options.MCPServiceOptions {
+	return getExtension[*options.MCPServiceOptions](svc.Desc.Options(), options.E_McpService)
+}
+
+// mcpMethodOptions returns the `(protomcp.mcp)` options of a method, or
+// nil.
+func mcpMethodOptions(m *protogen.Method) *options.MCPMethodOptions {
+	return getExtension[*options.MCPMethodOptions](m.Desc.Options(), options.E_Mcp)
+}
+
+// jsonrpcMethodOptions returns the `(protomcp.jsonrpc)` options of a
+// method, or nil.
+func jsonrpcMethodOptions(m *protogen.Method) *options.JSONRPCMethodOptions {
+	return getExtension[*options.JSONRPCMethodOptions](m.Desc.Options(), options.E_Jsonrpc)
+}
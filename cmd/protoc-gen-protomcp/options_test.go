@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"protomcp.org/protomcp/pkg/generator/testutils"
+	"protomcp.org/protomcp/pkg/protomcp/options"
+)
+
+// setMethodOption sets an extension on the options of a method.
+func setMethodOption(m *descriptorpb.MethodDescriptorProto, xt protoreflect.ExtensionType, v proto.Message) {
+	if m.Options == nil {
+		m.Options = &descriptorpb.MethodOptions{}
+	}
+	proto.SetExtension(m.Options, xt, v)
+}
+
+// setServiceOption sets an extension on the options of a service.
+func setServiceOption(svc *descriptorpb.ServiceDescriptorProto, xt protoreflect.ExtensionType, v proto.Message) {
+	if svc.Options == nil {
+		svc.Options = &descriptorpb.ServiceOptions{}
+	}
+	proto.SetExtension(svc.Options, xt, v)
+}
+
+func TestMethodOptions(t *testing.T) {
+	t.Run("unset", testMethodOptionsUnset)
+	t.Run("set", testMethodOptionsSet)
+}
+
+func testMethodOptionsUnset(t *testing.T) {
+	plugin, err := testutils.NewPlugin(t, newTestFile())
+	testutils.AssertNoError(t, err, "plugin")
+
+	svc := plugin.Files[0].Services[0]
+	testutils.AssertNil(t, mcpServiceOptions(svc), "mcp_service")
+	testutils.AssertNil(t, mcpMethodOptions(svc.Methods[0]), "mcp")
+	testutils.AssertNil(t, jsonrpcMethodOptions(svc.Methods[0]), "jsonrpc")
+	testutils.AssertEqual(t, mcpMethodOptions(svc.Methods[0]).GetTool().GetName(), "", "tool name")
+}
+
+func testMethodOptionsSet(t *testing.T) {
+	file := newTestFile()
+	setServiceOption(file.Service[0], options.E_McpService, &options.MCPServiceOptions{Hidden: true})
+	setMethodOption(file.Service[0].Method[0], options.E_Mcp, &options.MCPMethodOptions{
+		Tool: &options.ToolOptions{Name: "get_user", ReadOnly: proto.Bool(true)},
+	})
+
+	plugin, err := testutils.NewPlugin(t, file)
+	testutils.AssertNoError(t, err, "plugin")
+
+	svc := plugin.Files[0].Services[0]
+	testutils.AssertTrue(t, mcpServiceOptions(svc).GetHidden(), "hidden")
+
+	tool := mcpMethodOptions(svc.Methods[0]).GetTool()
+	testutils.AssertEqual(t, tool.GetName(), "get_user", "tool name")
+	testutils.AssertTrue(t, tool.GetReadOnly(), "read only")
+	testutils.AssertNil(t, tool.Destructive, "destructive")
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"protomcp.org/protomcp/pkg/generator"
+	"protomcp.org/protomcp/pkg/protomcp/options"
+	"protomcp.org/protomcp/pkg/protomcp/template"
+)
+
+// mcpPrompt is an MCP prompt declared in the options of a service.
+type mcpPrompt struct {
+	name        string
+	title       string
+	description string
+	template    string
+	arguments   []promptArgument
+}
+
+// promptArgument is an argument of an MCP prompt, derived from a field of
+// its arguments message.
+type promptArgument struct {
+	name        string
+	description string
+	enum        []string
+	required    bool
+}
+
+// mcpPrompts returns the prompts declared in the `(protomcp.mcp_service)`
+// options of a service.
+func mcpPrompts(svc *protogen.Service) ([]*mcpPrompt, error) {
+	opts := mcpServiceOptions(svc)
+	if opts.GetHidden() {
+		return nil, nil
+	}
+
+	var out []*mcpPrompt
+	names := make(map[string]bool)
+	for _, po := range opts.GetPrompts() {
+		p, err := newMCPPrompt(svc.Desc.ParentFile(), po)
+		switch {
+		case err != nil:
+			return nil, fmt.Errorf("%s: prompt %q: %w", svc.Desc.FullName(), po.GetName(), err)
+		case names[p.name]:
+			return nil, fmt.Errorf("%s: duplicate prompt %q", svc.Desc.FullName(), p.name)
+		}
+
+		names[p.name] = true
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// newMCPPrompt describes a prompt, checking its template only references
+// its arguments.
+func newMCPPrompt(file protoreflect.FileDescriptor, opts *options.PromptOptions) (*mcpPrompt, error) {
+	if opts.GetName() == "" {
+		return nil, errors.New("prompt without name")
+	}
+
+	tmpl, err := template.ParsePrompt(opts.GetTemplate())
+	if err != nil {
+		return nil, err
+	}
+
+	p := &mcpPrompt{
+		name:        opts.GetName(),
+		title:       opts.GetTitle(),
+		description: opts.GetDescription(),
+		template:    opts.GetTemplate(),
+	}
+	if name := opts.GetArguments(); name != "" {
+		p.arguments, err = promptArguments(file, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.checkTemplate(tmpl); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// checkTemplate checks a template only references arguments of the
+// prompt.
+func (p *mcpPrompt) checkTemplate(tmpl *template.Prompt) error {
+	for _, v := range tmpl.Variables() {
+		if !p.hasArgument(v) {
+			return fmt.Errorf("template references undeclared argument %q", v)
+		}
+	}
+	return nil
+}
+
+// hasArgument tells if the prompt takes an argument.
+func (p *mcpPrompt) hasArgument(name string) bool {
+	for _, arg := range p.arguments {
+		if arg.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// promptArguments returns the arguments described by the scalar fields of
+// a message. Fields without explicit presence are required.
+func promptArguments(file protoreflect.FileDescriptor, name string) ([]promptArgument, error) {
+	md := findMessage(file, protoreflect.FullName(strings.TrimPrefix(name, ".")))
+	if md == nil {
+		return nil, fmt.Errorf("arguments message %q not found", name)
+	}
+
+	fields := md.Fields()
+	out := make([]promptArgument, 0, fields.Len())
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if fd.IsList() || fd.IsMap() || fd.Message() != nil {
+			return nil, fmt.Errorf("argument bound to non-scalar field %s", fd.FullName())
+		}
+
+		out = append(out, newPromptArgument(fd))
+	}
+	return out, nil
+}
+
+// newPromptArgument describes the argument of a scalar field.
+func newPromptArgument(fd protoreflect.FieldDescriptor) promptArgument {
+	loc := fd.ParentFile().SourceLocations().ByDescriptor(fd)
+	arg := promptArgument{
+		name:        string(fd.Name()),
+		description: commentText(protogen.Comments(loc.LeadingComments)),
+		required:    !fd.HasPresence() || fd.Cardinality() == protoreflect.Required,
+	}
+	if ed := fd.Enum(); ed != nil {
+		arg.enum = enumValueNames(ed)
+	}
+	return arg
+}
+
+// enums returns the completions of the arguments bound to enum fields.
+func (p *mcpPrompt) enums() []enumCompletion {
+	var out []enumCompletion
+	for _, arg := range p.arguments {
+		if len(arg.enum) > 0 {
+			out = append(out, enumCompletion{argument: arg.name, values: arg.enum})
+		}
+	}
+	return out
+}
+
+// findMessage finds a message by full name in a file or its imports.
+func findMessage(file protoreflect.FileDescriptor, name protoreflect.FullName) protoreflect.MessageDescriptor {
+	if md := findNestedMessage(file.Messages(), name); md != nil {
+		return md
+	}
+
+	imports := file.Imports()
+	for i := range imports.Len() {
+		if md := findMessage(imports.Get(i).FileDescriptor, name); md != nil {
+			return md
+		}
+	}
+	return nil
+}
+
+// findNestedMessage finds a message by full name among some messages and
+// those nested in them.
+func findNestedMessage(msgs protoreflect.MessageDescriptors,
+	name protoreflect.FullName) protoreflect.MessageDescriptor {
+	for i := range msgs.Len() {
+		md := msgs.Get(i)
+		switch {
+		case md.FullName() == name:
+			return md
+		case strings.HasPrefix(string(name), string(md.FullName())+"."):
+			return findNestedMessage(md.Messages(), name)
+		}
+	}
+	return nil
+}
+
+// genAddPrompt emits the registration of a prompt.
+func genAddPrompt(g *protogen.GeneratedFile, p *mcpPrompt) {
+	var buf generator.LazyBuffer
+	buf.WriteString("&", g.QualifiedGoIdent(protomcpPackage.Ident("Prompt")), "{\n")
+	buf.WriteString("Name: ", strconv.Quote(p.name), ",\n")
+	if p.title != "" {
+		buf.WriteString("Title: ", strconv.Quote(p.title), ",\n")
+	}
+	if p.description != "" {
+		buf.WriteString("Description: ", goString(p.description), ",\n")
+	}
+	if len(p.arguments) > 0 {
+		buf.WriteString("Arguments: []", g.QualifiedGoIdent(protomcpPackage.Ident("PromptArgument")), "{\n")
+		for _, arg := range p.arguments {
+			buf.WriteString(promptArgumentLiteral(arg), ",\n")
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}")
+
+	generator.Trace("prompt %s: %d arguments", p.name, len(p.arguments))
+	g.P("s.AddPromptTemplate(", buf.String(), ", ", goString(p.template), ")")
+	genEnumCompletions(g, promptRef(g, p), p.enums())
+}
+
+// promptArgumentLiteral returns the protomcp.PromptArgument literal of an
+// argument, without its type.
+func promptArgumentLiteral(arg promptArgument) string {
+	var buf generator.LazyBuffer
+	buf.WriteString("{Name: ", strconv.Quote(arg.name))
+	if arg.description != "" {
+		buf.WriteString(", Description: ", goString(arg.description))
+	}
+	if arg.required {
+		buf.WriteString(", Required: true")
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"protomcp.org/protomcp/pkg/generator/testutils"
+	"protomcp.org/protomcp/pkg/protomcp/options"
+)
+
+// newPromptFile returns the test file with a ReviewRequest message, whose
+// focus field is optional, and UserService declaring the given prompt.
+func newPromptFile(opts *options.PromptOptions) *descriptorpb.FileDescriptorProto {
+	focus := testutils.NewField("focus", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING)
+	focus.Proto3Optional = proto.Bool(true)
+	focus.OneofIndex = proto.Int32(0)
+
+	review := testutils.NewMessage("ReviewRequest",
+		testutils.NewField("user_id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		focus,
+	)
+	review.OneofDecl = []*descriptorpb.OneofDescriptorProto{{Name: proto.String("_focus")}}
+
+	file := newTestFile()
+	file.MessageType = append(file.MessageType, review)
+	file.SourceCodeInfo = &descriptorpb.SourceCodeInfo{
+		Location: []*descriptorpb.SourceCodeInfo_Location{
+			newLocation(" user_id identifies the user.\n", 4, 2, 2, 0),
+		},
+	}
+	setServiceOption(file.Service[0], options.E_McpService, &options.MCPServiceOptions{
+		Prompts: []*options.PromptOptions{opts},
+	})
+	return file
+}
+
+func TestGenPrompts(t *testing.T) {
+	content := generateContent(t, newPromptFile(&options.PromptOptions{
+		Name:        "review_user",
+		Title:       "Review user",
+		Description: "Reviews a user.",
+		Arguments:   ".api.v1.ReviewRequest",
+		Template:    "Review {{user_id}}, focusing on {{ focus }}.",
+	}))
+
+	testutils.AssertContains(t, content, `s.AddPromptTemplate(&protomcp.Prompt{
+		Name:        "review_user",
+		Title:       "Review user",
+		Description: `+"`Reviews a user.`"+`,
+		Arguments: []protomcp.PromptArgument{
+			{Name: "user_id", Description: `+"`user_id identifies the user.`"+`, Required: true},
+			{Name: "focus"},
+		},
+	}, `+"`Review {{user_id}}, focusing on {{ focus }}.`"+`)`)
+}
+
+// promptErrorTestCase represents a test case for invalid prompt options
+type promptErrorTestCase struct {
+	name string
+	opts *options.PromptOptions
+	want string
+}
+
+// test runs the invalid prompt options test case
+func (tc promptErrorTestCase) test(t *testing.T) {
+	file := newPromptFile(tc.opts)
+	file.MessageType[2].Field = append(file.MessageType[2].Field,
+		repeated(testutils.NewField("tags", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING)))
+	if tc.opts.GetName() == "dup" {
+		setServiceOption(file.Service[0], options.E_McpService, &options.MCPServiceOptions{
+			Prompts: []*options.PromptOptions{tc.opts, tc.opts},
+		})
+	}
+
+	plugin, err := testutils.NewPlugin(t, file)
+	testutils.AssertNoError(t, err, "plugin")
+
+	err = Generate(plugin)
+	testutils.AssertError(t, err, "generate")
+	testutils.AssertContains(t, err.Error(), tc.want)
+}
+
+func TestGenPromptsErrors(t *testing.T) {
+	tests := []promptErrorTestCase{
+		{"no name", &options.PromptOptions{Template: "Hi"}, "prompt without name"},
+		{"bad template", &options.PromptOptions{Name: "p", Template: "Hi {{name"}, "unterminated"},
+		{"unknown message", &options.PromptOptions{Name: "p", Arguments: "api.v1.Nope"},
+			`arguments message "api.v1.Nope" not found`},
+		{"undeclared argument", &options.PromptOptions{Name: "p", Template: "Hi {{name}}"},
+			`undeclared argument "name"`},
+		{"non-scalar field", &options.PromptOptions{Name: "p", Arguments: "api.v1.ReviewRequest"},
+			"argument bound to non-scalar field api.v1.ReviewRequest.tags"},
+		{"duplicate", &options.PromptOptions{Name: "dup"}, `duplicate prompt "dup"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestFindMessage(t *testing.T) {
+	file := newTestFile()
+	file.MessageType[0].NestedType = append(file.MessageType[0].NestedType, testutils.NewMessage("Filter"))
+
+	plugin, err := testutils.NewPlugin(t, file)
+	testutils.AssertNoError(t, err, "plugin")
+
+	fd := plugin.Files[0].Desc
+	testutils.AssertNotNil(t, findMessage(fd, "api.v1.User"), "top level")
+	testutils.AssertNotNil(t, findMessage(fd, "api.v1.GetUserRequest.Filter"), "nested")
+	testutils.AssertNil(t, findMessage(fd, "api.v1.Filter"), "unknown")
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/compiler/protogen"
+
+	"protomcp.org/protomcp/pkg/generator"
+	"protomcp.org/protomcp/pkg/protomcp/options"
+	"protomcp.org/protomcp/pkg/protomcp/template"
+)
+
+// mcpResource is the MCP resource, or resource template, generated for a
+// method.
+type mcpResource struct {
+	method      *protogen.Method
+	uri         string
+	name        string
+	title       string
+	description string
+	mimeType    string
+	enums       []enumCompletion
+	template    bool
+}
+
+// mcpResources returns the resources generated for the methods of a
+// service annotated with `(protomcp.mcp).resource` options.
+func mcpResources(svc *protogen.Service) ([]*mcpResource, error) {
+	if mcpServiceOptions(svc).GetHidden() {
+		return nil, nil
+	}
+
+	var out []*mcpResource
+	for _, m := range serverMethods(svc) {
+		opts := mcpMethodOptions(m).GetResource()
+		if opts == nil {
+			continue
+		}
+
+		r, err := newMCPResource(m, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fullMethodName(m), err)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// newMCPResource describes the resource of a method, checking every
+// variable of its URI template binds to a scalar field of the request.
+func newMCPResource(m *protogen.Method, opts *options.ResourceOptions) (*mcpResource, error) {
+	if opts.GetUriTemplate() == "" {
+		return nil, errors.New("resource without uri_template")
+	}
+
+	tmpl, err := template.ParseURI(opts.GetUriTemplate())
+	if err != nil {
+		return nil, err
+	}
+
+	vars := tmpl.Variables()
+	for _, v := range vars {
+		if err := checkTemplateVariable(m.Input, v); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &mcpResource{
+		method:      m,
+		uri:         opts.GetUriTemplate(),
+		name:        opts.GetName(),
+		title:       opts.GetTitle(),
+		description: opts.GetDescription(),
+		mimeType:    opts.GetMimeType(),
+		enums:       templateEnums(m.Input, vars),
+		template:    len(vars) > 0,
+	}
+	if r.name == "" {
+		r.name = toolName(m)
+	}
+	if r.description == "" {
+		r.description = commentText(m.Comments.Leading)
+	}
+	return r, nil
+}
+
+// checkTemplateVariable checks a URI template variable binds to a scalar
+// field of a message, by name or JSON name.
+func checkTemplateVariable(msg *protogen.Message, name string) error {
+	f := templateField(msg, name)
+	switch {
+	case f == nil:
+		return fmt.Errorf("URI template variable %q isn't a field of %s", name, msg.Desc.FullName())
+	case f.Desc.IsList() || f.Desc.IsMap() || f.Message != nil:
+		return fmt.Errorf("URI template variable %q bound to non-scalar field %s",
+			name, f.Desc.FullName())
+	}
+	return nil
+}
+
+// templateField returns the field of a message a URI template variable
+// binds to, by name or JSON name, or nil.
+func templateField(msg *protogen.Message, name string) *protogen.Field {
+	for _, f := range msg.Fields {
+		if string(f.Desc.Name()) == name || f.Desc.JSONName() == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// templateEnums returns the completions of the URI template variables
+// bound to enum fields.
+func templateEnums(msg *protogen.Message, vars []string) []enumCompletion {
+	var out []enumCompletion
+	for _, v := range vars {
+		if f := templateField(msg, v); f != nil && f.Enum != nil {
+			out = append(out, enumCompletion{argument: v, values: enumValueNames(f.Enum.Desc)})
+		}
+	}
+	return out
+}
+
+// genAddResource emits the registration of a resource.
+func genAddResource(g *protogen.GeneratedFile, r *mcpResource) {
+	newHandler := g.QualifiedGoIdent(protomcpPackage.Ident("NewMethodHandler"))
+	add, typ, uriField := "AddResource", "Resource", "URI"
+	if r.template {
+		add, typ, uriField = "AddResourceTemplate", "ResourceTemplate", "URITemplate"
+	}
+
+	var buf generator.LazyBuffer
+	buf.WriteString("&", g.QualifiedGoIdent(protomcpPackage.Ident(typ)), "{\n")
+	buf.WriteString(uriField, ": ", strconv.Quote(r.uri), ",\n")
+	buf.WriteString("Name: ", strconv.Quote(r.name), ",\n")
+	if r.title != "" {
+		buf.WriteString("Title: ", strconv.Quote(r.title), ",\n")
+	}
+	if r.description != "" {
+		buf.WriteString("Description: ", goString(r.description), ",\n")
+	}
+	if r.mimeType != "" {
+		buf.WriteString("MimeType: ", strconv.Quote(r.mimeType), ",\n")
+	}
+	buf.WriteString("}")
+
+	generator.Trace("%s: resource %s", fullMethodName(r.method), r.uri)
+	g.P("s.", add, "(", buf.String(), ", ", newHandler, "(srv.", r.method.GoName, "))")
+	genEnumCompletions(g, resourceRef(g, r), r.enums)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"protomcp.org/protomcp/pkg/generator/testutils"
+	"protomcp.org/protomcp/pkg/protomcp/options"
+)
+
+// newResourceFile returns the test file with GetUser annotated as the
+// given resource.
+func newResourceFile(opts *options.ResourceOptions) *descriptorpb.FileDescriptorProto {
+	file := newTestFile()
+	setMethodOption(file.Service[0].Method[0], options.E_Mcp, &options.MCPMethodOptions{Resource: opts})
+	return file
+}
+
+func TestGenResources(t *testing.T) {
+	t.Run("template", testGenResourcesTemplate)
+	t.Run("fixed", testGenResourcesFixed)
+}
+
+func testGenResourcesTemplate(t *testing.T) {
+	content := generateContent(t, newResourceFile(&options.ResourceOptions{
+		UriTemplate: "users://{user_id}",
+		Title:       "User",
+		MimeType:    "application/json",
+	}))
+
+	testutils.AssertContains(t, content, `s.AddResourceTemplate(&protomcp.ResourceTemplate{
+		URITemplate: "users://{user_id}",
+		Name:        "UserService_GetUser",
+		Title:       "User",
+		MimeType:    "application/json",
+	}, protomcp.NewMethodHandler(srv.GetUser))`)
+	testutils.AssertFalse(t, strings.Contains(content, "s.AddTool("), "resource added as tool")
+}
+
+func testGenResourcesFixed(t *testing.T) {
+	content := generateContent(t, newResourceFile(&options.ResourceOptions{
+		UriTemplate: "users://me",
+		Name:        "me",
+		Description: "The current user.",
+	}))
+
+	testutils.AssertContains(t, content, `s.AddResource(&protomcp.Resource{
+		URI:         "users://me",
+		Name:        "me",
+		Description: `+"`The current user.`"+`,
+	}, protomcp.NewMethodHandler(srv.GetUser))`)
+}
+
+// resourceErrorTestCase represents a test case for invalid resource
+// options
+type resourceErrorTestCase struct {
+	name     string
+	template string
+	want     string
+}
+
+// test runs the invalid resource options test case
+func (tc resourceErrorTestCase) test(t *testing.T) {
+	file := newResourceFile(&options.ResourceOptions{UriTemplate: tc.template})
+	file.MessageType[0].Field = append(file.MessageType[0].Field,
+		repeated(testutils.NewField("tags", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING)))
+
+	plugin, err := testutils.NewPlugin(t, file)
+	testutils.AssertNoError(t, err, "plugin")
+
+	err = Generate(plugin)
+	testutils.AssertError(t, err, "generate")
+	testutils.AssertContains(t, err.Error(), tc.want)
+}
+
+func TestGenResourcesErrors(t *testing.T) {
+	tests := []resourceErrorTestCase{
+		{"no template", "", "resource without uri_template"},
+		{"invalid template", "users://{id", "unterminated expression"},
+		{"unknown field", "users://{id}", `variable "id" isn't a field of api.v1.GetUserRequest`},
+		{"repeated field", "users://{tags}", `variable "tags" bound to non-scalar field`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestCheckTemplateVariable(t *testing.T) {
+	plugin, err := testutils.NewPlugin(t, newTestFile())
+	testutils.AssertNoError(t, err, "plugin")
+
+	msg := plugin.Files[0].Messages[0]
+	testutils.AssertNoError(t, checkTemplateVariable(msg, "user_id"), "proto name")
+	testutils.AssertNoError(t, checkTemplateVariable(msg, "userId"), "JSON name")
+	testutils.AssertError(t, checkTemplateVariable(msg, "name"), "unknown field")
+}
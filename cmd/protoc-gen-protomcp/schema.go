@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// jsonSchema is a JSON Schema document. Maps are encoded with sorted keys,
+// so the generated schemas are stable.
+type jsonSchema = map[string]any
+
+// int64Pattern and uint64Pattern match the string form protojson uses for
+// 64-bit integers.
+const (
+	int64Pattern  = "^-?[0-9]+$"
+	uint64Pattern = "^[0-9]+$"
+)
+
+// schemaBuilder derives JSON Schemas from message descriptors, following
+// the protojson mapping. Recursive messages are moved to `$defs`.
+type schemaBuilder struct {
+	defs  map[string]jsonSchema
+	refs  map[protoreflect.FullName]bool // recursive messages
+	stack map[protoreflect.FullName]bool // messages being built
+}
+
+// messageSchema returns the JSON Schema of the protojson encoding of a
+// message.
+func messageSchema(msg *protogen.Message) jsonSchema {
+	b := &schemaBuilder{
+		defs:  make(map[string]jsonSchema),
+		refs:  make(map[protoreflect.FullName]bool),
+		stack: make(map[protoreflect.FullName]bool),
+	}
+
+	name := msg.Desc.FullName()
+	if s, ok := wellKnownSchema(name); ok {
+		return s
+	}
+
+	// the root is always inlined
+	b.stack[name] = true
+	s := b.object(msg)
+	if b.refs[name] {
+		// but recursive references need a definition too
+		b.defs[string(name)] = b.object(msg)
+	}
+
+	if len(b.defs) > 0 {
+		s["$defs"] = b.defs
+	}
+	return s
+}
+
+// marshalSchema encodes a schema as compact JSON.
+func marshalSchema(s jsonSchema) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// isObjectSchema tells if a schema describes a JSON object.
+func isObjectSchema(s jsonSchema) bool {
+	return s["type"] == "object"
+}
+
+// message returns the schema of a message, or a reference to it if it is
+// recursive.
+func (b *schemaBuilder) message(msg *protogen.Message) jsonSchema {
+	name := msg.Desc.FullName()
+	if s, ok := wellKnownSchema(name); ok {
+		return s
+	}
+
+	if b.stack[name] {
+		b.refs[name] = true
+		return schemaRef(name)
+	}
+
+	b.stack[name] = true
+	s := b.object(msg)
+	delete(b.stack, name)
+
+	if b.refs[name] {
+		b.defs[string(name)] = s
+		return schemaRef(name)
+	}
+	return s
+}
+
+// object returns the schema of the fields of a message.
+func (b *schemaBuilder) object(msg *protogen.Message) jsonSchema {
+	s := jsonSchema{"type": "object"}
+	if len(msg.Fields) == 0 {
+		return s
+	}
+
+	props := make(map[string]any, len(msg.Fields))
+	for _, f := range msg.Fields {
+		props[f.Desc.JSONName()] = b.field(f)
+	}
+	s["properties"] = props
+	return s
+}
+
+// field returns the schema of a field, including its cardinality and
+// documentation.
+func (b *schemaBuilder) field(f *protogen.Field) jsonSchema {
+	var s jsonSchema
+	switch {
+	case f.Desc.IsMap():
+		s = jsonSchema{
+			"type":                 "object",
+			"additionalProperties": b.single(f.Message.Fields[1]),
+		}
+	case f.Desc.IsList():
+		s = jsonSchema{
+			"type":  "array",
+			"items": b.single(f),
+		}
+	default:
+		s = b.single(f)
+	}
+
+	if desc := commentText(f.Comments.Leading); desc != "" {
+		s["description"] = desc
+	}
+	return s
+}
+
+// single returns the schema of a single value of a field.
+func (b *schemaBuilder) single(f *protogen.Field) jsonSchema {
+	switch f.Desc.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return b.message(f.Message)
+	case protoreflect.EnumKind:
+		return enumSchema(f.Enum)
+	default:
+		return scalarSchema(f.Desc.Kind())
+	}
+}
+
+// schemaRef returns a reference to the definition of a message.
+func schemaRef(name protoreflect.FullName) jsonSchema {
+	return jsonSchema{"$ref": "#/$defs/" + string(name)}
+}
+
+// enumSchema returns the schema of an enum, encoded by name.
+func enumSchema(e *protogen.Enum) jsonSchema {
+	if e.Desc.FullName() == "google.protobuf.NullValue" {
+		return jsonSchema{"type": "null"}
+	}
+
+	names := make([]string, len(e.Values))
+	for i, v := range e.Values {
+		names[i] = string(v.Desc.Name())
+	}
+	return jsonSchema{"type": "string", "enum": names}
+}
+
+// scalarSchema returns the schema of a scalar kind.
+func scalarSchema(kind protoreflect.Kind) jsonSchema {
+	switch kind {
+	case protoreflect.BoolKind:
+		return jsonSchema{"type": "boolean"}
+	case protoreflect.StringKind:
+		return jsonSchema{"type": "string"}
+	case protoreflect.BytesKind:
+		return jsonSchema{"type": "string", "contentEncoding": "base64"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return jsonSchema{"type": "integer"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return jsonSchema{"type": "integer", "minimum": 0}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return jsonSchema{"type": []string{"integer", "string"}, "pattern": int64Pattern}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return jsonSchema{"type": []string{"integer", "string"}, "minimum": 0, "pattern": uint64Pattern}
+	default:
+		return jsonSchema{"type": "number"}
+	}
+}
+
+// wellKnownSchema returns the schema of the well-known types with a
+// special JSON mapping.
+func wellKnownSchema(name protoreflect.FullName) (jsonSchema, bool) {
+	if kind, ok := wrapperKinds[name]; ok {
+		return scalarSchema(kind), true
+	}
+
+	switch name {
+	case "google.protobuf.Timestamp":
+		return jsonSchema{"type": "string", "format": "date-time"}, true
+	case "google.protobuf.Duration":
+		return jsonSchema{"type": "string", "pattern": `^-?[0-9]+(\.[0-9]+)?s$`}, true
+	case "google.protobuf.FieldMask":
+		return jsonSchema{"type": "string"}, true
+	case "google.protobuf.Struct":
+		return jsonSchema{"type": "object"}, true
+	case "google.protobuf.ListValue":
+		return jsonSchema{"type": "array"}, true
+	case "google.protobuf.Value":
+		return jsonSchema{}, true
+	case "google.protobuf.Any":
+		return jsonSchema{
+			"type":       "object",
+			"properties": map[string]any{"@type": jsonSchema{"type": "string"}},
+			"required":   []string{"@type"},
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// wrapperKinds maps the wrapper well-known types to the kind they wrap.
+var wrapperKinds = map[protoreflect.FullName]protoreflect.Kind{
+	"google.protobuf.BoolValue":   protoreflect.BoolKind,
+	"google.protobuf.BytesValue":  protoreflect.BytesKind,
+	"google.protobuf.DoubleValue": protoreflect.DoubleKind,
+	"google.protobuf.FloatValue":  protoreflect.FloatKind,
+	"google.protobuf.Int32Value":  protoreflect.Int32Kind,
+	"google.protobuf.Int64Value":  protoreflect.Int64Kind,
+	"google.protobuf.StringValue": protoreflect.StringKind,
+	"google.protobuf.UInt32Value": protoreflect.Uint32Kind,
+	"google.protobuf.UInt64Value": protoreflect.Uint64Kind,
+}
+
+// commentText returns the text of a comment, without markers nor
+// surrounding whitespace.
+func commentText(c protogen.Comments) string {
+	lines := strings.Split(strings.TrimSpace(string(c)), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Join(lines, "\n")
+}
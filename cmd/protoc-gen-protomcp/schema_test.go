@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"protomcp.org/protomcp/pkg/generator/testutils"
+)
+
+// newSchemaFile returns a file with a message covering the protojson
+// mapping of every kind of field.
+func newSchemaFile() *descriptorpb.FileDescriptorProto {
+	file := testutils.NewFileDescriptor("schema.proto", "test.v1", "example.com/test/v1;testv1")
+	file.Syntax = proto.String("proto3")
+	file.Dependency = []string{
+		"google/protobuf/timestamp.proto",
+		"google/protobuf/struct.proto",
+		"google/protobuf/wrappers.proto",
+	}
+	file.EnumType = append(file.EnumType, testutils.NewEnum("Color",
+		testutils.NewEnumValue("COLOR_UNSPECIFIED", 0),
+		testutils.NewEnumValue("COLOR_RED", 1),
+	))
+
+	labels := testutils.NewMessage("LabelsEntry",
+		testutils.NewField("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		testutils.NewField("value", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+	)
+	labels.Options = &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)}
+
+	all := testutils.NewMessage("All",
+		testutils.NewField("display_name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		testutils.NewField("count", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+		testutils.NewField("id", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+		testutils.NewField("size", 4, descriptorpb.FieldDescriptorProto_TYPE_UINT64),
+		testutils.NewField("flag", 5, descriptorpb.FieldDescriptorProto_TYPE_BOOL),
+		testutils.NewField("data", 6, descriptorpb.FieldDescriptorProto_TYPE_BYTES),
+		testutils.NewField("ratio", 7, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE),
+		testutils.NewEnumField("color", 8, ".test.v1.Color"),
+		repeated(testutils.NewField("tags", 9, descriptorpb.FieldDescriptorProto_TYPE_STRING)),
+		repeated(messageField("labels", 10, ".test.v1.All.LabelsEntry")),
+		messageField("created", 11, ".google.protobuf.Timestamp"),
+		messageField("extra", 12, ".google.protobuf.Struct"),
+		messageField("limit", 13, ".google.protobuf.Int32Value"),
+		messageField("child", 14, ".test.v1.Node"),
+		testutils.NewField("mask", 15, descriptorpb.FieldDescriptorProto_TYPE_UINT32),
+	)
+	all.NestedType = append(all.NestedType, labels)
+
+	node := testutils.NewMessage("Node",
+		testutils.NewField("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		repeated(messageField("children", 2, ".test.v1.Node")),
+	)
+
+	file.MessageType = append(file.MessageType, all, node)
+	file.SourceCodeInfo = &descriptorpb.SourceCodeInfo{
+		Location: []*descriptorpb.SourceCodeInfo_Location{
+			newLocation(" The name\n shown to users.\n", 4, 0, 2, 0),
+		},
+	}
+	return file
+}
+
+// messageField creates a message-typed field.
+func messageField(name string, number int32, typeName string) *descriptorpb.FieldDescriptorProto {
+	f := testutils.NewField(name, number, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE)
+	f.TypeName = proto.String(typeName)
+	return f
+}
+
+// repeated makes a field repeated.
+func repeated(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+	f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return f
+}
+
+// wellKnownFiles returns the descriptors of the well-known types used by
+// tests.
+func wellKnownFiles() []*descriptorpb.FileDescriptorProto {
+	files := []protoreflect.FileDescriptor{
+		timestamppb.File_google_protobuf_timestamp_proto,
+		structpb.File_google_protobuf_struct_proto,
+		wrapperspb.File_google_protobuf_wrappers_proto,
+	}
+
+	out := make([]*descriptorpb.FileDescriptorProto, len(files))
+	for i, f := range files {
+		out[i] = protodesc.ToFileDescriptorProto(f)
+	}
+	return out
+}
+
+// newTestMessage returns a message of a file, resolved with the
+// well-known types available.
+func newTestMessage(t *testing.T, file *descriptorpb.FileDescriptorProto, name string) *protogen.Message {
+	t.Helper()
+
+	req := testutils.NewCodeGenRequest(append(wellKnownFiles(), file)...)
+	req.FileToGenerate = []string{file.GetName()}
+	plugin, err := protogen.Options{}.New(req)
+	if err != nil {
+		t.Fatalf("failed to create plugin: %v", err)
+	}
+
+	for _, m := range plugin.FilesByPath[file.GetName()].Messages {
+		if string(m.Desc.Name()) == name {
+			return m
+		}
+	}
+	t.Fatalf("message %q not found", name)
+	return nil
+}
+
+// schemaProperties returns the encoded schema of each property of a
+// message.
+func schemaProperties(t *testing.T, m *protogen.Message) map[string]string {
+	t.Helper()
+
+	var decoded struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	s, err := marshalSchema(messageSchema(m))
+	testutils.AssertNoError(t, err, "marshal")
+	testutils.AssertNoError(t, json.Unmarshal([]byte(s), &decoded), "unmarshal")
+
+	out := make(map[string]string, len(decoded.Properties))
+	for k, v := range decoded.Properties {
+		out[k] = string(v)
+	}
+	return out
+}
+
+func TestMessageSchemaFields(t *testing.T) {
+	props := schemaProperties(t, newTestMessage(t, newSchemaFile(), "All"))
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"displayName", `{"description":"The name\nshown to users.","type":"string"}`},
+		{"count", `{"type":"integer"}`},
+		{"id", `{"pattern":"^-?[0-9]+$","type":["integer","string"]}`},
+		{"size", `{"minimum":0,"pattern":"^[0-9]+$","type":["integer","string"]}`},
+		{"flag", `{"type":"boolean"}`},
+		{"data", `{"contentEncoding":"base64","type":"string"}`},
+		{"ratio", `{"type":"number"}`},
+		{"color", `{"enum":["COLOR_UNSPECIFIED","COLOR_RED"],"type":"string"}`},
+		{"tags", `{"items":{"type":"string"},"type":"array"}`},
+		{"labels", `{"additionalProperties":{"type":"integer"},"type":"object"}`},
+		{"created", `{"format":"date-time","type":"string"}`},
+		{"extra", `{"type":"object"}`},
+		{"limit", `{"type":"integer"}`},
+		{"mask", `{"minimum":0,"type":"integer"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testutils.AssertEqual(t, props[tc.name], tc.want, "schema of %s", tc.name)
+		})
+	}
+}
+
+func TestMessageSchemaRecursive(t *testing.T) {
+	t.Run("nested", testMessageSchemaRecursiveNested)
+	t.Run("root", testMessageSchemaRecursiveRoot)
+}
+
+func testMessageSchemaRecursiveNested(t *testing.T) {
+	s, err := marshalSchema(messageSchema(newTestMessage(t, newSchemaFile(), "All")))
+	testutils.AssertNoError(t, err, "marshal")
+
+	testutils.AssertContains(t, s, `"child":{"$ref":"#/$defs/test.v1.Node"}`)
+	testutils.AssertContains(t, s, `"$defs":{"test.v1.Node":{"properties":{`+
+		`"children":{"items":{"$ref":"#/$defs/test.v1.Node"},"type":"array"},`+
+		`"name":{"type":"string"}},"type":"object"}}`)
+}
+
+func testMessageSchemaRecursiveRoot(t *testing.T) {
+	s, err := marshalSchema(messageSchema(newTestMessage(t, newSchemaFile(), "Node")))
+	testutils.AssertNoError(t, err, "marshal")
+
+	testutils.AssertEqual(t, s, `{"$defs":{"test.v1.Node":{"properties":{`+
+		`"children":{"items":{"$ref":"#/$defs/test.v1.Node"},"type":"array"},`+
+		`"name":{"type":"string"}},"type":"object"}},"properties":{`+
+		`"children":{"items":{"$ref":"#/$defs/test.v1.Node"},"type":"array"},`+
+		`"name":{"type":"string"}},"type":"object"}`, "schema")
+}
+
+func TestWellKnownSchema(t *testing.T) {
+	tests := []struct {
+		name protoreflect.FullName
+		want string
+	}{
+		{"google.protobuf.Duration", `{"pattern":"^-?[0-9]+(\\.[0-9]+)?s$","type":"string"}`},
+		{"google.protobuf.Value", `{}`},
+		{"google.protobuf.ListValue", `{"type":"array"}`},
+		{"google.protobuf.StringValue", `{"type":"string"}`},
+		{"google.protobuf.Any", `{"properties":{"@type":{"type":"string"}},"required":["@type"],"type":"object"}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.name), func(t *testing.T) {
+			s, ok := wellKnownSchema(tc.name)
+			testutils.AssertTrue(t, ok, "well-known")
+			got, err := marshalSchema(s)
+			testutils.AssertNoError(t, err, "marshal")
+			testutils.AssertEqual(t, got, tc.want, "schema")
+		})
+	}
+
+	_, ok := wellKnownSchema("test.v1.All")
+	testutils.AssertFalse(t, ok, "user message is well-known")
+}
+
+func TestCommentText(t *testing.T) {
+	testutils.AssertEqual(t, commentText(" Line one.\n  Line two.\n"), "Line one.\nLine two.", "comment")
+	testutils.AssertEqual(t, commentText(""), "", "empty comment")
+}
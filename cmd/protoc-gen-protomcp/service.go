@@ -0,0 +1,168 @@
+package main
+
+import (
+	"strconv"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"protomcp.org/protomcp/pkg/generator"
+)
+
+// deprecationComment is added to the documentation of deprecated symbols.
+const deprecationComment = "// Deprecated: Do not use."
+
+// genService emits the declarations generated for a single service.
+func genService(g *protogen.GeneratedFile, svc *protogen.Service) error {
+	warnStreamingMethods(svc)
+	genServiceNames(g, svc)
+	genServerInterface(g, svc)
+	genUnimplementedServer(g, svc)
+	genRegisterJSONRPC(g, svc)
+	return genRegisterMCP(g, svc)
+}
+
+// genServiceNames emits constants holding the fully-qualified names of
+// the service and each of its methods.
+func genServiceNames(g *protogen.GeneratedFile, svc *protogen.Service) {
+	g.P("const (")
+	g.P("// ", serviceNameConst(svc), " is the fully-qualified name of the ", svc.GoName, " service.")
+	g.P(serviceNameConst(svc), " = ", strconv.Quote(string(svc.Desc.FullName())))
+	for _, m := range svc.Methods {
+		g.P()
+		g.P("// ", methodNameConst(m), " is the fully-qualified name of the ", svc.GoName, ".", m.GoName, " method.")
+		g.P(methodNameConst(m), " = ", strconv.Quote(fullMethodName(m)))
+	}
+	g.P(")")
+	g.P()
+}
+
+// genServerInterface emits the protocol-agnostic `<Service>Server`
+// interface every dispatcher routes to.
+func genServerInterface(g *protogen.GeneratedFile, svc *protogen.Service) {
+	g.P("// ", serverName(svc), " is the server API for the ", svc.GoName, " service.")
+	g.P("// It is independent of the protocol used to reach it, and implementations")
+	g.P("// should embed ", unimplementedName(svc), " for forward compatibility.")
+	if svc.Comments.Leading != "" {
+		g.P("//")
+		g.P(trimComment(svc.Comments.Leading))
+	}
+	if isDeprecatedService(svc) {
+		g.P("//")
+		g.P(deprecationComment)
+	}
+	g.P("type ", serverName(svc), " interface {")
+	for _, m := range serverMethods(svc) {
+		if isDeprecatedMethod(m) {
+			g.P(deprecationComment)
+		}
+		g.P(m.Comments.Leading, m.GoName, serverSignature(g, m))
+	}
+	g.P("}")
+	g.P()
+}
+
+// genUnimplementedServer emits the `Unimplemented<Service>Server` struct
+// whose methods report CodeMethodNotFound.
+func genUnimplementedServer(g *protogen.GeneratedFile, svc *protogen.Service) {
+	notFound := g.QualifiedGoIdent(protomcpPackage.Ident("NewMethodNotFoundError"))
+
+	g.P("// ", unimplementedName(svc), " must be embedded by implementations of")
+	g.P("// ", serverName(svc), " to remain compatible with future versions of the")
+	g.P("// service. Methods not overridden report a method not found error.")
+	g.P("type ", unimplementedName(svc), " struct{}")
+	g.P()
+	for _, m := range serverMethods(svc) {
+		g.P("func (", unimplementedName(svc), ") ", m.GoName, serverSignature(g, m), " {")
+		g.P("return nil, ", notFound, "(", methodNameConst(m), ")")
+		g.P("}")
+		g.P()
+	}
+	g.P("var _ ", serverName(svc), " = ", unimplementedName(svc), "{}")
+	g.P()
+}
+
+// serverSignature returns the parameters and results of a server method.
+func serverSignature(g *protogen.GeneratedFile, m *protogen.Method) string {
+	var buf generator.LazyBuffer
+	buf.WriteString("(", g.QualifiedGoIdent(contextPackage.Ident("Context")))
+	buf.WriteString(", *", g.QualifiedGoIdent(m.Input.GoIdent), ")")
+	buf.WriteString(" (*", g.QualifiedGoIdent(m.Output.GoIdent), ", error)")
+	return buf.String()
+}
+
+// serverMethods returns the methods of a service exposed on its server
+// interface. Streaming methods are not supported yet.
+func serverMethods(svc *protogen.Service) []*protogen.Method {
+	out := make([]*protogen.Method, 0, len(svc.Methods))
+	for _, m := range svc.Methods {
+		if isUnary(m) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// warnStreamingMethods warns about the methods of a service left out of
+// its server interface for streaming.
+func warnStreamingMethods(svc *protogen.Service) {
+	for _, m := range svc.Methods {
+		if !isUnary(m) {
+			generator.Warn("%s: streaming method skipped", fullMethodName(m))
+		}
+	}
+}
+
+// isUnary tells if a method is neither client nor server streaming.
+func isUnary(m *protogen.Method) bool {
+	return !m.Desc.IsStreamingClient() && !m.Desc.IsStreamingServer()
+}
+
+// isDeprecatedService tells if a service is marked as deprecated.
+func isDeprecatedService(svc *protogen.Service) bool {
+	opts, ok := svc.Desc.Options().(*descriptorpb.ServiceOptions)
+	return ok && opts.GetDeprecated()
+}
+
+// isDeprecatedMethod tells if a method is marked as deprecated.
+func isDeprecatedMethod(m *protogen.Method) bool {
+	opts, ok := m.Desc.Options().(*descriptorpb.MethodOptions)
+	return ok && opts.GetDeprecated()
+}
+
+// trimComment returns a comment block without its trailing newline.
+func trimComment(c protogen.Comments) string {
+	s := c.String()
+	if n := len(s); n > 0 && s[n-1] == '\n' {
+		s = s[:n-1]
+	}
+	return s
+}
+
+// serverName returns the Go name of the server interface of a service.
+func serverName(svc *protogen.Service) string {
+	return svc.GoName + "Server"
+}
+
+// unimplementedName returns the Go name of the embeddable unimplemented
+// server of a service.
+func unimplementedName(svc *protogen.Service) string {
+	return "Unimplemented" + svc.GoName + "Server"
+}
+
+// serviceNameConst returns the Go identifier of the service name constant.
+func serviceNameConst(svc *protogen.Service) string {
+	return svc.GoName + "_ServiceName"
+}
+
+// methodNameConst returns the Go identifier of a method name constant.
+func methodNameConst(m *protogen.Method) string {
+	return m.Parent.GoName + "_" + m.GoName + "_FullMethodName"
+}
+
+// fullMethodName returns the `pkg.Service/Method` name of a method, as used
+// for routing by the protomcp dispatchers.
+func fullMethodName(m *protogen.Method) string {
+	var buf generator.LazyBuffer
+	return buf.WriteString(string(m.Parent.Desc.FullName()), "/", string(m.Desc.Name())).String()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"protomcp.org/protomcp/pkg/generator"
+	"protomcp.org/protomcp/pkg/generator/testutils"
+)
+
+func TestGenServiceNames(t *testing.T) {
+	content := generateContent(t, newTestFile())
+
+	testutils.AssertContains(t, content, `UserService_ServiceName = "api.v1.UserService"`)
+	testutils.AssertContains(t, content, `UserService_GetUser_FullMethodName = "api.v1.UserService/GetUser"`)
+}
+
+func TestFullMethodName(t *testing.T) {
+	plugin, err := testutils.NewPlugin(t, newTestFile())
+	if err != nil {
+		t.Fatalf("failed to create plugin: %v", err)
+	}
+
+	m := plugin.Files[0].Services[0].Methods[0]
+	testutils.AssertEqual(t, fullMethodName(m), "api.v1.UserService/GetUser", "full method name")
+	testutils.AssertEqual(t, methodNameConst(m), "UserService_GetUser_FullMethodName", "constant")
+	testutils.AssertEqual(t, serviceNameConst(m.Parent), "UserService_ServiceName", "service constant")
+}
+
+func TestGenServerInterface(t *testing.T) {
+	t.Run("unary methods", testGenServerInterfaceUnary)
+	t.Run("streaming methods skipped", testGenServerInterfaceStreaming)
+	t.Run("comments", testGenServerInterfaceComments)
+	t.Run("deprecated", testGenServerInterfaceDeprecated)
+}
+
+func testGenServerInterfaceUnary(t *testing.T) {
+	content := generateContent(t, newTestFile())
+
+	testutils.AssertContains(t, content, "type UserServiceServer interface {")
+	testutils.AssertContains(t, content, "GetUser(context.Context, *GetUserRequest) (*User, error)")
+	testutils.AssertContains(t, content, `import (
+	context "context"`)
+}
+
+func testGenServerInterfaceStreaming(t *testing.T) {
+	file := newTestFile()
+	watch := testutils.NewMethod("WatchUser", ".api.v1.GetUserRequest", ".api.v1.User")
+	watch.ServerStreaming = proto.Bool(true)
+	file.Service[0].Method = append(file.Service[0].Method, watch)
+
+	var warnings bytes.Buffer
+	origOutput := generator.WarnOutput
+	generator.WarnOutput = &warnings
+	t.Cleanup(func() { generator.WarnOutput = origOutput })
+
+	content := generateContent(t, file)
+	testutils.AssertContains(t, content, `UserService_WatchUser_FullMethodName = "api.v1.UserService/WatchUser"`)
+	testutils.AssertFalse(t, strings.Contains(content, "WatchUser(context.Context"), "streaming method in interface")
+	testutils.AssertEqual(t, warnings.String(), "[WARN] api.v1.UserService/WatchUser: streaming method skipped\n",
+		"warnings")
+}
+
+func testGenServerInterfaceComments(t *testing.T) {
+	file := newTestFile()
+	file.SourceCodeInfo = &descriptorpb.SourceCodeInfo{
+		Location: []*descriptorpb.SourceCodeInfo_Location{
+			newLocation(" UserService manages users.\n", 6, 0),
+			newLocation(" GetUser returns a user by id.\n", 6, 0, 2, 0),
+		},
+	}
+
+	content := generateContent(t, file)
+	testutils.AssertContains(t, content, `//
+// UserService manages users.
+type UserServiceServer interface {`)
+	testutils.AssertContains(t, content, `	// GetUser returns a user by id.
+	GetUser(context.Context`)
+}
+
+func testGenServerInterfaceDeprecated(t *testing.T) {
+	file := newTestFile()
+	file.Service[0].Options = &descriptorpb.ServiceOptions{Deprecated: proto.Bool(true)}
+	file.Service[0].Method[0].Options = &descriptorpb.MethodOptions{Deprecated: proto.Bool(true)}
+
+	content := generateContent(t, file)
+	testutils.AssertContains(t, content, `//
+// Deprecated: Do not use.
+type UserServiceServer interface {
+	// Deprecated: Do not use.
+	GetUser(`)
+}
+
+func TestGenUnimplementedServer(t *testing.T) {
+	content := generateContent(t, newTestFile())
+
+	testutils.AssertContains(t, content, "type UnimplementedUserServiceServer struct{}")
+	testutils.AssertContains(t, content,
+		"func (UnimplementedUserServiceServer) GetUser(context.Context, *GetUserRequest) (*User, error) {")
+	testutils.AssertContains(t, content,
+		"return nil, protomcp.NewMethodNotFoundError(UserService_GetUser_FullMethodName)")
+	testutils.AssertContains(t, content, `protomcp "protomcp.org/protomcp/pkg/protomcp"`)
+	testutils.AssertContains(t, content, "var _ UserServiceServer = UnimplementedUserServiceServer{}")
+}
+
+// newLocation creates a source location carrying a leading comment.
+func newLocation(comment string, path ...int32) *descriptorpb.SourceCodeInfo_Location {
+	return &descriptorpb.SourceCodeInfo_Location{
+		Path:            path,
+		Span:            []int32{0, 0, 0},
+		LeadingComments: proto.String(comment),
+	}
+}
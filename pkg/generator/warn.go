@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"io"
+	"os"
+)
+
+// WarnOutput is the output writer for warnings. Defaults to os.Stderr.
+// Can be overridden for testing purposes to capture warnings.
+//
+// Example:
+//
+//	var buf bytes.Buffer
+//	generator.WarnOutput = &buf
+//	generator.Warn("test message")
+//	output := buf.String()
+var WarnOutput io.Writer = os.Stderr
+
+// Warn prints a warning about the input of a generator, such as a
+// definition it doesn't support. Unlike Debug and Trace, warnings are
+// always printed, as protoc shows the standard error of plugins to the
+// user.
+//
+// The warning output format is:
+//
+//	[WARN] formatted message
+//
+// Example:
+//
+//	// This will output: [WARN] api.v1.UserService/WatchUser: streaming method skipped
+//	generator.Warn("%s: streaming method skipped", methodName)
+func Warn(format string, args ...any) {
+	s := makeWarnString(format, args...)
+	_, _ = io.WriteString(WarnOutput, s)
+}
+
+// makeWarnString constructs a warning string without location info.
+// It handles both formatted messages (with args) and plain string messages.
+//
+// Output format:
+//
+//	[WARN] message\n  (with message)
+//	[WARN]\n          (without message)
+func makeWarnString(format string, args ...any) string {
+	var buf LazyBuffer
+
+	buf.WriteString("[WARN]")
+
+	if format == "" {
+		return buf.WriteString("\n").String()
+	}
+
+	buf.WriteString(" ")
+	if len(args) > 0 {
+		// Format the warning message
+		buf.Printf(format, args...)
+	} else {
+		// Raw message
+		buf.WriteString(format)
+	}
+
+	return buf.WriteString("\n").String()
+}
@@ -0,0 +1,50 @@
+package generator
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWarn(t *testing.T) {
+	origOutput := WarnOutput
+	t.Cleanup(func() {
+		WarnOutput = origOutput
+	})
+
+	var buf bytes.Buffer
+	WarnOutput = &buf
+
+	Warn("%s: streaming method skipped", "api.v1.UserService.WatchUser")
+
+	output := buf.String()
+	expected := "[WARN] api.v1.UserService.WatchUser: streaming method skipped\n"
+	if output != expected {
+		t.Errorf("Warn() = %q, want %q", output, expected)
+	}
+}
+
+func TestMakeWarnString(t *testing.T) {
+	t.Run("empty format", func(t *testing.T) {
+		got := makeWarnString("")
+		expected := "[WARN]\n"
+		if got != expected {
+			t.Errorf("makeWarnString() = %q, want %q", got, expected)
+		}
+	})
+
+	t.Run("simple message", func(t *testing.T) {
+		got := makeWarnString("Hello world")
+		expected := "[WARN] Hello world\n"
+		if got != expected {
+			t.Errorf("makeWarnString() = %q, want %q", got, expected)
+		}
+	})
+
+	t.Run("formatted message", func(t *testing.T) {
+		got := makeWarnString("Processing %s with value %d", "test", 123)
+		expected := "[WARN] Processing test with value 123\n"
+		if got != expected {
+			t.Errorf("makeWarnString() = %q, want %q", got, expected)
+		}
+	})
+}
@@ -0,0 +1,195 @@
+// Package mcplog provides a darvaza.org/slog Logger forwarding the entries
+// logged while handling an MCP request to its client, as
+// notifications/message.
+package mcplog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"darvaza.org/slog"
+
+	"protomcp.org/protomcp/pkg/protomcp"
+)
+
+// messageKey is the field of the data sent to clients holding the message
+// of an entry, as the log/slog LogHandler of protomcp does.
+const messageKey = "msg"
+
+var _ slog.Logger = (*Logger)(nil)
+
+// Logger is a darvaza.org/slog Logger sending its entries to the client of
+// the MCP session of a request once the client has chosen a minimum level
+// with logging/setLevel. All entries are also passed to the next Logger.
+type Logger struct {
+	ctx    context.Context
+	sess   *protomcp.Session
+	next   slog.Logger
+	fields map[string]any
+	name   string
+	level  slog.LogLevel
+}
+
+// New returns a Logger for the request of ctx, forwarding its entries to
+// the client under the given logger name and passing them to next unless
+// nil. The MCPServer must have its logging enabled with EnableLogging.
+func New(ctx context.Context, name string, next slog.Logger) *Logger {
+	sess, _ := protomcp.SessionFromContext(ctx)
+	return &Logger{
+		ctx:  ctx,
+		sess: sess,
+		next: next,
+		name: name,
+	}
+}
+
+// Debug returns a Logger for debug entries.
+func (l *Logger) Debug() slog.Logger { return l.WithLevel(slog.Debug) }
+
+// Info returns a Logger for informational entries.
+func (l *Logger) Info() slog.Logger { return l.WithLevel(slog.Info) }
+
+// Warn returns a Logger for warnings.
+func (l *Logger) Warn() slog.Logger { return l.WithLevel(slog.Warn) }
+
+// Error returns a Logger for errors.
+func (l *Logger) Error() slog.Logger { return l.WithLevel(slog.Error) }
+
+// Fatal returns a Logger for fatal errors, terminating the program once
+// printed by the next Logger.
+func (l *Logger) Fatal() slog.Logger { return l.WithLevel(slog.Fatal) }
+
+// Panic returns a Logger for errors panicking once printed.
+func (l *Logger) Panic() slog.Logger { return l.WithLevel(slog.Panic) }
+
+// WithLevel returns a Logger for entries of the given level.
+func (l *Logger) WithLevel(level slog.LogLevel) slog.Logger {
+	c := *l
+	c.level = level
+	if l.next != nil {
+		c.next = l.next.WithLevel(level)
+	}
+	return &c
+}
+
+// WithStack passes a call stack to the next Logger. Clients aren't sent
+// stacks.
+func (l *Logger) WithStack(skip int) slog.Logger {
+	if l.next == nil {
+		return l
+	}
+
+	c := *l
+	c.next = l.next.WithStack(skip + 1)
+	return &c
+}
+
+// WithField returns a Logger adding a field to its entries.
+func (l *Logger) WithField(label string, value any) slog.Logger {
+	return l.WithFields(map[string]any{label: value})
+}
+
+// WithFields returns a Logger adding fields to its entries. Fields without
+// label are ignored.
+func (l *Logger) WithFields(fields map[string]any) slog.Logger {
+	c := *l
+	c.fields = make(map[string]any, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		c.fields[k] = v
+	}
+	for k, v := range fields {
+		if k != "" {
+			c.fields[k] = v
+		}
+	}
+	if l.next != nil {
+		c.next = l.next.WithFields(fields)
+	}
+	return &c
+}
+
+// Enabled tells if entries of the level of the Logger are sent to the
+// client or wanted by the next Logger.
+func (l *Logger) Enabled() bool {
+	return l.clientEnabled() || (l.next != nil && l.next.Enabled())
+}
+
+// WithEnabled returns the Logger and whether it is enabled.
+func (l *Logger) WithEnabled() (slog.Logger, bool) {
+	return l, l.Enabled()
+}
+
+// clientEnabled tells if the client wants entries of the level of the
+// Logger.
+func (l *Logger) clientEnabled() bool {
+	level, ok := LoggingLevelOf(l.level)
+	return ok && l.sess != nil && l.sess.LogEnabled(level)
+}
+
+// Print logs an entry with its arguments formatted as fmt.Sprint does.
+func (l *Logger) Print(args ...any) {
+	l.emit(fmt.Sprint(args...))
+}
+
+// Println logs an entry with its arguments formatted as fmt.Sprintln does.
+func (l *Logger) Println(args ...any) {
+	l.emit(strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+// Printf logs an entry with its arguments formatted as fmt.Sprintf does.
+func (l *Logger) Printf(format string, args ...any) {
+	l.emit(fmt.Sprintf(format, args...))
+}
+
+// emit sends an entry to the client and passes it to the next Logger,
+// which then terminates fatal and panic entries. Without one they panic.
+// Errors sending the entry to the client are ignored.
+func (l *Logger) emit(msg string) {
+	if l.clientEnabled() {
+		level, _ := LoggingLevelOf(l.level)
+		_ = l.sess.Log(l.ctx, level, l.name, l.data(msg))
+	}
+
+	switch {
+	case l.next != nil:
+		l.next.Print(msg)
+	case l.level == slog.Fatal, l.level == slog.Panic:
+		panic(msg)
+	}
+}
+
+// data returns the content of an entry sent to clients, an object with
+// its message and fields. Errors are sent as their text.
+func (l *Logger) data(msg string) map[string]any {
+	out := make(map[string]any, len(l.fields)+1)
+	for k, v := range l.fields {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		out[k] = v
+	}
+	out[messageKey] = msg
+	return out
+}
+
+// LoggingLevelOf returns the LoggingLevel of a darvaza.org/slog level,
+// and false if it has none.
+func LoggingLevelOf(level slog.LogLevel) (protomcp.LoggingLevel, bool) {
+	switch level {
+	case slog.Debug:
+		return protomcp.LoggingLevelDebug, true
+	case slog.Info:
+		return protomcp.LoggingLevelInfo, true
+	case slog.Warn:
+		return protomcp.LoggingLevelWarning, true
+	case slog.Error:
+		return protomcp.LoggingLevelError, true
+	case slog.Fatal:
+		return protomcp.LoggingLevelCritical, true
+	case slog.Panic:
+		return protomcp.LoggingLevelAlert, true
+	default:
+		return "", false
+	}
+}
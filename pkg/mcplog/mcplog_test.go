@@ -0,0 +1,266 @@
+package mcplog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"darvaza.org/slog"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"protomcp.org/protomcp/pkg/protomcp"
+)
+
+// testClient is the client end of an MCP server served by ServeIO over
+// pipes.
+type testClient struct {
+	in  *io.PipeWriter
+	out *bufio.Reader
+}
+
+// newTestClient serves an MCP server with a tool logging through a
+// Logger passing its entries to next, and initialises its session.
+func newTestClient(t *testing.T, next slog.Logger) *testClient {
+	t.Helper()
+
+	s := protomcp.NewMCPServer("test-server", "0.1.0")
+	s.EnableLogging()
+	s.AddTool(&protomcp.Tool{
+		Name:        "work",
+		InputSchema: json.RawMessage(`{"type":"object"}`),
+	}, protomcp.NewMethodHandler(func(ctx context.Context, req *emptypb.Empty) (*emptypb.Empty, error) {
+		log := New(ctx, "tools", next)
+		log.Debug().Print("starting")
+		log.Info().WithField("req", 7).WithFields(map[string]any{"n": 1, "": 2}).Printf("working %d", 1)
+		log.Error().WithField("err", errors.New("boom")).Println("failed", 2)
+		return req, nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = protomcp.ServeIO(ctx, s, inR, outW)
+		_ = outW.Close()
+	}()
+	t.Cleanup(func() {
+		_ = inW.Close()
+		<-done
+		cancel()
+	})
+
+	c := &testClient{in: inW, out: bufio.NewReader(outR)}
+	c.send(t, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{`+
+		`"protocolVersion":"2025-06-18","capabilities":{},`+
+		`"clientInfo":{"name":"test","version":"1.0"}}}`)
+	c.recv(t)
+	c.send(t, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	return c
+}
+
+// send writes a message to the server.
+func (c *testClient) send(t *testing.T, msg string) {
+	t.Helper()
+
+	if _, err := io.WriteString(c.in, msg+"\n"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+}
+
+// recv reads a message from the server.
+func (c *testClient) recv(t *testing.T) string {
+	t.Helper()
+
+	line, err := c.out.ReadString('\n')
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	return strings.TrimSuffix(line, "\n")
+}
+
+// expect reads a message from the server and compares it with want.
+func (c *testClient) expect(t *testing.T, want string) {
+	t.Helper()
+
+	if got := c.recv(t); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// workRequest is the tools/call request of the work tool.
+const workRequest = `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"work"}}`
+
+// workResponse is the response to workRequest.
+const workResponse = `{"id":3,"result":{"structuredContent":{},` +
+	`"content":[{"type":"text","text":"{}"}]},"jsonrpc":"2.0"}`
+
+func TestLogger(t *testing.T) {
+	t.Run("level set", testLoggerLevelSet)
+	t.Run("level unset", testLoggerLevelUnset)
+	t.Run("next", testLoggerNext)
+	t.Run("fatal", testLoggerFatal)
+}
+
+func testLoggerLevelSet(t *testing.T) {
+	c := newTestClient(t, nil)
+
+	c.send(t, `{"jsonrpc":"2.0","id":2,"method":"logging/setLevel","params":{"level":"info"}}`)
+	c.expect(t, `{"id":2,"result":{},"jsonrpc":"2.0"}`)
+
+	c.send(t, workRequest)
+	c.expect(t, `{"jsonrpc":"2.0","method":"notifications/message","params":{`+
+		`"data":{"msg":"working 1","n":1,"req":7},"level":"info","logger":"tools"}}`)
+	c.expect(t, `{"jsonrpc":"2.0","method":"notifications/message","params":{`+
+		`"data":{"err":"boom","msg":"failed 2"},"level":"error","logger":"tools"}}`)
+	c.expect(t, workResponse)
+}
+
+func testLoggerLevelUnset(t *testing.T) {
+	c := newTestClient(t, nil)
+
+	c.send(t, workRequest)
+	c.expect(t, workResponse)
+}
+
+func testLoggerNext(t *testing.T) {
+	next := newTestLogger()
+	c := newTestClient(t, next)
+
+	c.send(t, workRequest)
+	c.expect(t, workResponse)
+
+	want := []string{
+		"Debug starting map[]",
+		"Info working 1 map[:2 n:1 req:7]",
+		"Error failed 2 map[err:boom]",
+	}
+	if got := next.entries(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("next entries = %q, want %q", got, want)
+	}
+}
+
+func testLoggerFatal(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "bye" {
+			t.Errorf("recovered %v, want bye", r)
+		}
+	}()
+
+	New(context.Background(), "tools", nil).Fatal().Print("bye")
+	t.Error("fatal entry didn't panic")
+}
+
+// testLevelNames are the names of the levels in the entries of a
+// testLogger.
+var testLevelNames = map[slog.LogLevel]string{
+	slog.Panic: "Panic",
+	slog.Fatal: "Fatal",
+	slog.Error: "Error",
+	slog.Warn:  "Warn",
+	slog.Info:  "Info",
+	slog.Debug: "Debug",
+}
+
+// testLogger is a slog.Logger recording the entries printed.
+type testLogger struct {
+	mu     *sync.Mutex
+	out    *[]string
+	fields map[string]any
+	level  slog.LogLevel
+}
+
+// newTestLogger returns a testLogger without entries.
+func newTestLogger() *testLogger {
+	return &testLogger{mu: new(sync.Mutex), out: new([]string)}
+}
+
+// entries returns the entries printed by the testLogger and its
+// descendants.
+func (l *testLogger) entries() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]string(nil), *l.out...)
+}
+
+func (l *testLogger) Debug() slog.Logger { return l.WithLevel(slog.Debug) }
+func (l *testLogger) Info() slog.Logger  { return l.WithLevel(slog.Info) }
+func (l *testLogger) Warn() slog.Logger  { return l.WithLevel(slog.Warn) }
+func (l *testLogger) Error() slog.Logger { return l.WithLevel(slog.Error) }
+func (l *testLogger) Fatal() slog.Logger { return l.WithLevel(slog.Fatal) }
+func (l *testLogger) Panic() slog.Logger { return l.WithLevel(slog.Panic) }
+
+func (l *testLogger) WithLevel(level slog.LogLevel) slog.Logger {
+	c := *l
+	c.level = level
+	return &c
+}
+
+func (l *testLogger) WithStack(int) slog.Logger { return l }
+
+func (l *testLogger) WithField(label string, value any) slog.Logger {
+	return l.WithFields(map[string]any{label: value})
+}
+
+func (l *testLogger) WithFields(fields map[string]any) slog.Logger {
+	c := *l
+	c.fields = make(map[string]any, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		c.fields[k] = v
+	}
+	for k, v := range fields {
+		c.fields[k] = v
+	}
+	return &c
+}
+
+func (*testLogger) Enabled() bool                      { return true }
+func (l *testLogger) WithEnabled() (slog.Logger, bool) { return l, true }
+
+func (l *testLogger) Print(args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	*l.out = append(*l.out, fmt.Sprintf("%s %s %v", testLevelNames[l.level], fmt.Sprint(args...), l.fields))
+}
+
+func (l *testLogger) Println(args ...any) { l.Print(fmt.Sprintln(args...)) }
+
+func (l *testLogger) Printf(format string, args ...any) { l.Print(fmt.Sprintf(format, args...)) }
+
+// loggingLevelTestCase represents a test case for LoggingLevelOf.
+type loggingLevelTestCase struct {
+	name  string
+	want  protomcp.LoggingLevel
+	level slog.LogLevel
+	ok    bool
+}
+
+func (tc loggingLevelTestCase) test(t *testing.T) {
+	got, ok := LoggingLevelOf(tc.level)
+	if got != tc.want || ok != tc.ok {
+		t.Errorf("LoggingLevelOf(%d) = %q, %v, want %q, %v", tc.level, got, ok, tc.want, tc.ok)
+	}
+}
+
+func TestLoggingLevelOf(t *testing.T) {
+	for _, tc := range []loggingLevelTestCase{
+		{name: "undefined", level: slog.UndefinedLevel},
+		{name: "debug", level: slog.Debug, want: protomcp.LoggingLevelDebug, ok: true},
+		{name: "info", level: slog.Info, want: protomcp.LoggingLevelInfo, ok: true},
+		{name: "warn", level: slog.Warn, want: protomcp.LoggingLevelWarning, ok: true},
+		{name: "error", level: slog.Error, want: protomcp.LoggingLevelError, ok: true},
+		{name: "fatal", level: slog.Fatal, want: protomcp.LoggingLevelCritical, ok: true},
+		{name: "panic", level: slog.Panic, want: protomcp.LoggingLevelAlert, ok: true},
+	} {
+		t.Run(tc.name, tc.test)
+	}
+}
@@ -0,0 +1,186 @@
+package protomcp
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// batchIDPrefix starts the ids given to batched requests before they are
+// dispatched, followed by a sequence number unique to the connection.
+// Their responses are then routed to their own batch whatever ids the
+// client chose, and get their original id back. Clients can't use ids
+// starting with it.
+const batchIDPrefix = "\x00batch:"
+
+// batchedRequest is a batched request waiting for its response.
+type batchedRequest struct {
+	batch *batch
+	id    jsonrpc2.ID // as given by the client
+}
+
+// batch collects the responses to a JSON-RPC batch request until all its
+// requests have been answered.
+type batch struct {
+	responses []json.RawMessage
+	remaining int
+}
+
+// readBatch splits a batch request, queuing its valid messages to be
+// returned by ReadObject. Invalid entries are answered as part of the batch
+// response.
+func (s *messageStream) readBatch(raw json.RawMessage) {
+	var items []json.RawMessage
+	switch {
+	case json.Unmarshal(raw, &items) != nil:
+		_ = s.writeError(nil, NewError(CodeParseError, "parse error"))
+	case len(items) == 0:
+		e := invalidRequest(nil, "empty batch")
+		_ = s.writeError(e.id, e.err)
+	default:
+		s.queue = append(s.queue, s.newBatch(items)...)
+	}
+}
+
+// newBatch registers the requests of a batch as pending, returning the
+// messages to dispatch.
+func (s *messageStream) newBatch(items []json.RawMessage) []json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := &batch{}
+	valid := make([]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		if e := checkMessage(item); e != nil {
+			b.addError(e)
+			continue
+		}
+
+		valid = append(valid, s.tag(b, item))
+	}
+
+	if b.remaining == 0 && len(b.responses) > 0 {
+		// nothing else to wait for
+		_ = s.stream.WriteObject(b.responses)
+	}
+	return valid
+}
+
+// tag gives a batched request an id of its own, registering it as
+// pending on b. Notifications are returned unchanged. Must be called with
+// the lock held.
+func (s *messageStream) tag(b *batch, item json.RawMessage) json.RawMessage {
+	var m map[string]json.RawMessage
+	if json.Unmarshal(item, &m) != nil || m["method"] == nil {
+		return item
+	}
+
+	id, _ := parseID(m["id"])
+	if id == nil {
+		return item
+	}
+
+	s.seq++
+	key := batchIDPrefix + strconv.FormatUint(s.seq, 10)
+	m["id"], _ = json.Marshal(key)
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return item
+	}
+
+	s.pending[key] = batchedRequest{batch: b, id: *id}
+	b.remaining++
+	return raw
+}
+
+// batched checks if obj is the response to a pending batched request,
+// returning its batch and its encoded form with the original id. Must be
+// called with the lock held.
+func (s *messageStream) batched(obj any) (*batch, json.RawMessage) {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, nil
+	}
+
+	var resp jsonrpc2.Response
+	if _, ok := responseKey(raw); !ok || json.Unmarshal(raw, &resp) != nil {
+		return nil, nil
+	}
+
+	req, ok := s.pending[resp.ID.Str]
+	if !ok || !resp.ID.IsString {
+		return nil, nil
+	}
+	delete(s.pending, resp.ID.Str)
+
+	resp.ID = req.id
+	if raw, err = json.Marshal(&resp); err != nil {
+		return nil, nil
+	}
+	return req.batch, raw
+}
+
+// writeBatched adds a response to its batch, writing the batch response
+// once complete. Must be called with the lock held.
+func (s *messageStream) writeBatched(b *batch, raw json.RawMessage) error {
+	b.responses = append(b.responses, raw)
+	b.remaining--
+	if b.remaining > 0 {
+		return nil
+	}
+	return s.stream.WriteObject(b.responses)
+}
+
+// addError adds the error response of an invalid entry.
+func (b *batch) addError(e *messageError) {
+	if raw, err := json.Marshal(e.response()); err == nil {
+		b.responses = append(b.responses, raw)
+	}
+}
+
+// clientID returns the id given by the client to a request, undoing the
+// tagging of batched requests still pending.
+func (s *messageStream) clientID(id jsonrpc2.ID) jsonrpc2.ID {
+	if !id.IsString || !strings.HasPrefix(id.Str, batchIDPrefix) {
+		return id
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req, ok := s.pending[id.Str]; ok {
+		return req.id
+	}
+	return id
+}
+
+// requestKey returns the key identifying the response to a valid message,
+// if it is a request expecting one.
+func requestKey(raw json.RawMessage) (string, bool) {
+	var m rawMessage
+	if json.Unmarshal(raw, &m) != nil || m.Method == nil {
+		return "", false
+	}
+	return idKey(m.ID)
+}
+
+// responseKey returns the key identifying the request an outbound message
+// responds to, if it is a response.
+func responseKey(raw json.RawMessage) (string, bool) {
+	var m rawMessage
+	if json.Unmarshal(raw, &m) != nil || m.Method != nil {
+		return "", false
+	}
+	return idKey(m.ID)
+}
+
+// idKey returns a key for a raw id, if it isn't null.
+func idKey(raw json.RawMessage) (string, bool) {
+	id, ok := parseID(raw)
+	if !ok || id == nil {
+		return "", false
+	}
+	return id.String(), true
+}
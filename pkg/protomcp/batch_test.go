@@ -0,0 +1,380 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// recvBatch reads the next message as a batch response, sorted by id.
+func (p *testPeer) recvBatch(t *testing.T) []*testResponse {
+	t.Helper()
+
+	var out []*testResponse
+	if err := json.Unmarshal(p.recv(t), &out); err != nil {
+		t.Fatalf("recv: expected batch response: %v", err)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return string(out[i].ID) < string(out[j].ID)
+	})
+	return out
+}
+
+func TestDispatcherBatch(t *testing.T) {
+	t.Run("mixed", testDispatcherBatchMixed)
+	t.Run("notifications only", testDispatcherBatchNotifications)
+	t.Run("empty", testDispatcherBatchEmpty)
+	t.Run("invalid entries only", testDispatcherBatchInvalid)
+	t.Run("parse error", testDispatcherBatchParseError)
+	t.Run("worker limit", testDispatcherBatchWorkers)
+	t.Run("duplicate ids", testDispatcherBatchDuplicateIDs)
+	t.Run("concurrent batches", testDispatcherBatchConcurrent)
+	t.Run("single request", testDispatcherBatchSingle)
+	t.Run("reserved id", testDispatcherBatchReservedID)
+	t.Run("escaped string id", testDispatcherBatchEscapedID)
+}
+
+func testDispatcherBatchMixed(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	p.send(t, `[
+		{"jsonrpc":"2.0","id":1,"method":"test.Echo/Echo","params":{"a":1}},
+		{"jsonrpc":"2.0","method":"test.Echo/Echo","params":{}},
+		{"jsonrpc":"2.0","id":2,"method":"test.Echo/Nope"},
+		{"foo":"boo"},
+		{"jsonrpc":"2.0","id":"3","method":"test.Echo/Fail"}
+	]`)
+
+	resp := p.recvBatch(t)
+	if len(resp) != 4 {
+		t.Fatalf("got %d responses, want 4", len(resp))
+	}
+	resp[0].assertError(t, `"3"`, CodeInternalError)
+	resp[1].assertResult(t, "1", `{"a":1}`)
+	resp[2].assertError(t, "2", CodeMethodNotFound)
+	resp[3].assertError(t, "null", CodeInvalidRequest)
+}
+
+func testDispatcherBatchNotifications(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	p.send(t, `[
+		{"jsonrpc":"2.0","method":"test.Echo/Echo","params":{}},
+		{"jsonrpc":"2.0","method":"test.Echo/Nope"}
+	]`)
+
+	// nothing is returned for the batch
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"test.Echo/Echo","params":{}}`)
+	resp.assertResult(t, "1", `{}`)
+}
+
+func testDispatcherBatchEmpty(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	resp := p.call(t, `[]`)
+	resp.assertError(t, "null", CodeInvalidRequest)
+}
+
+func testDispatcherBatchInvalid(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	p.send(t, `[1,2]`)
+	resp := p.recvBatch(t)
+	if len(resp) != 2 {
+		t.Fatalf("got %d responses, want 2", len(resp))
+	}
+	for _, r := range resp {
+		r.assertError(t, "null", CodeInvalidRequest)
+	}
+}
+
+func testDispatcherBatchParseError(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	resp := p.call(t, `[{"jsonrpc": "2.0", "method": "test.Echo/Echo"},{"jsonrpc": "2.0", "method"]`)
+	resp.assertError(t, "null", CodeParseError)
+}
+
+func testDispatcherBatchWorkers(t *testing.T) {
+	gate := newTestGate()
+	d := NewDispatcher()
+	d.MaxWorkers = 2
+	d.HandleFunc("wait", gate.handle)
+
+	p := newTestPeer(t, d)
+	p.send(t, `[
+		{"jsonrpc":"2.0","id":1,"method":"wait"},
+		{"jsonrpc":"2.0","id":2,"method":"wait"},
+		{"jsonrpc":"2.0","id":3,"method":"wait"}
+	]`)
+
+	// two run concurrently, the third waits for a worker
+	gate.waitEntered(t, 2)
+	gate.assertNotEntered(t, 3)
+	gate.release()
+
+	if resp := p.recvBatch(t); len(resp) != 3 {
+		t.Fatalf("got %d responses, want 3", len(resp))
+	}
+	if got := gate.maxRunning(); got != 2 {
+		t.Errorf("max concurrent handlers = %d, want 2", got)
+	}
+}
+
+// testGate is a handler blocking until released, tracking how many
+// instances run concurrently.
+type testGate struct {
+	entered chan struct{}
+	done    chan struct{}
+	running int
+	max     int
+	mu      sync.Mutex
+}
+
+func newTestGate() *testGate {
+	return &testGate{
+		entered: make(chan struct{}, 16),
+		done:    make(chan struct{}),
+	}
+}
+
+func (g *testGate) handle(context.Context, json.RawMessage) (any, error) {
+	g.mu.Lock()
+	g.running++
+	g.max = max(g.max, g.running)
+	g.mu.Unlock()
+
+	g.entered <- struct{}{}
+	<-g.done
+
+	g.mu.Lock()
+	g.running--
+	g.mu.Unlock()
+	return true, nil
+}
+
+// waitEntered waits for n handlers to start.
+func (g *testGate) waitEntered(t *testing.T, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		select {
+		case <-g.entered:
+		case <-time.After(testTimeout):
+			t.Fatalf("only %d handlers started, want %d", i, n)
+		}
+	}
+}
+
+// assertNotEntered checks the nth handler doesn't start.
+func (g *testGate) assertNotEntered(t *testing.T, n int) {
+	t.Helper()
+
+	select {
+	case <-g.entered:
+		t.Fatalf("handler %d started", n)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// release unblocks all handlers.
+func (g *testGate) release() {
+	close(g.done)
+}
+
+func (g *testGate) maxRunning() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.max
+}
+
+func testDispatcherBatchDuplicateIDs(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	p.send(t, `[
+		{"jsonrpc":"2.0","id":1,"method":"test.Echo/Echo","params":{"a":1}},
+		{"jsonrpc":"2.0","id":1,"method":"test.Echo/Echo","params":{"a":2}}
+	]`)
+
+	resp := p.recvBatch(t)
+	if len(resp) != 2 {
+		t.Fatalf("got %d responses, want 2", len(resp))
+	}
+	results := make([]string, 0, len(resp))
+	for _, r := range resp {
+		if string(r.ID) != "1" {
+			t.Errorf("got id %s, want 1", r.ID)
+		}
+		results = append(results, string(r.Result))
+	}
+	sort.Strings(results)
+	if got, want := strings.Join(results, ","), `{"a":1},{"a":2}`; got != want {
+		t.Errorf("got results %s, want %s", got, want)
+	}
+}
+
+// newTestGateDispatcher returns a test dispatcher with a "wait" method
+// blocking on a gate.
+func newTestGateDispatcher() (*Dispatcher, *testGate) {
+	gate := newTestGate()
+	d := newTestDispatcher()
+	d.HandleFunc("wait", gate.handle)
+	return d, gate
+}
+
+func testDispatcherBatchConcurrent(t *testing.T) {
+	d, gate := newTestGateDispatcher()
+	p := newTestPeer(t, d)
+
+	p.send(t, `[
+		{"jsonrpc":"2.0","id":1,"method":"wait"},
+		{"jsonrpc":"2.0","id":2,"method":"test.Echo/Echo","params":{"a":1}}
+	]`)
+	gate.waitEntered(t, 1)
+
+	p.send(t, `[{"jsonrpc":"2.0","id":1,"method":"test.Echo/Echo","params":{"a":2}}]`)
+	resp := p.recvBatch(t)
+	if len(resp) != 1 {
+		t.Fatalf("second batch: got %d responses, want 1", len(resp))
+	}
+	resp[0].assertResult(t, "1", `{"a":2}`)
+
+	gate.release()
+	resp = p.recvBatch(t)
+	if len(resp) != 2 {
+		t.Fatalf("first batch: got %d responses, want 2", len(resp))
+	}
+	resp[0].assertResult(t, "1", `true`)
+	resp[1].assertResult(t, "2", `{"a":1}`)
+}
+
+func testDispatcherBatchSingle(t *testing.T) {
+	d, gate := newTestGateDispatcher()
+	p := newTestPeer(t, d)
+
+	p.send(t, `[
+		{"jsonrpc":"2.0","id":1,"method":"wait"},
+		{"jsonrpc":"2.0","id":2,"method":"wait"}
+	]`)
+	gate.waitEntered(t, 2)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"test.Echo/Echo","params":{"a":1}}`)
+	resp.assertResult(t, "1", `{"a":1}`)
+
+	gate.release()
+	batch := p.recvBatch(t)
+	if len(batch) != 2 {
+		t.Fatalf("got %d responses, want 2", len(batch))
+	}
+	batch[0].assertResult(t, "1", `true`)
+	batch[1].assertResult(t, "2", `true`)
+}
+
+func testDispatcherBatchReservedID(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":"\u0000batch:1:1","method":"test.Echo/Echo"}`)
+	resp.assertError(t, `"\u0000batch:1:1"`, CodeInvalidRequest)
+}
+
+func testDispatcherBatchEscapedID(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	p.send(t, `[{"jsonrpc":"2.0","id":"a\u0007b\"c","method":"test.Echo/Echo","params":{}}]`)
+	resp := p.recvBatch(t)
+	if len(resp) != 1 {
+		t.Fatalf("got %d responses, want 1", len(resp))
+	}
+	resp[0].assertResult(t, `"a\u0007b\"c"`, `{}`)
+}
+
+func TestIDKey(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+		ok   bool
+	}{
+		{"number", "1", "1", true},
+		{"string", `"1"`, `"1"`, true},
+		{"null", "null", "", false},
+		{"absent", "", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := idKey(json.RawMessage(tc.raw))
+			if got != tc.want || ok != tc.ok {
+				t.Errorf("idKey() = %q, %v, want %q, %v", got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestRequestKey(t *testing.T) {
+	if _, ok := requestKey(json.RawMessage(`{"jsonrpc":"2.0","method":"a"}`)); ok {
+		t.Error("notification has a request key")
+	}
+	if _, ok := responseKey(json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"a"}`)); ok {
+		t.Error("request has a response key")
+	}
+	key, _ := requestKey(json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"a"}`))
+	if want, _ := responseKey(json.RawMessage(`{"jsonrpc":"2.0","id":1,"result":1}`)); key != want {
+		t.Errorf("request key %q doesn't match response key %q", key, want)
+	}
+}
+
+// clientIDTestCase represents a test case for messageStream.clientID.
+type clientIDTestCase struct {
+	s    *messageStream
+	name string
+	id   jsonrpc2.ID
+	want jsonrpc2.ID
+}
+
+func (tc clientIDTestCase) test(t *testing.T) {
+	if got := tc.s.clientID(tc.id); got != tc.want {
+		t.Errorf("clientID(%v) = %v, want %v", tc.id, got, tc.want)
+	}
+}
+
+// tagTestRequest tags a request of a batch, returning its new id.
+func tagTestRequest(t *testing.T, s *messageStream, id string) jsonrpc2.ID {
+	t.Helper()
+
+	raw := s.tag(&batch{}, json.RawMessage(`{"jsonrpc":"2.0","id":`+id+`,"method":"a"}`))
+	var m rawMessage
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatal(err)
+	}
+	tagged, ok := parseID(m.ID)
+	if !ok || tagged == nil {
+		t.Fatalf("invalid tagged id %s", m.ID)
+	}
+	return *tagged
+}
+
+func TestClientID(t *testing.T) {
+	s := newMessageStream(nil)
+	number := tagTestRequest(t, s, "1")
+	escaped := tagTestRequest(t, s, `"a\u0007b\"c"`)
+
+	tests := []clientIDTestCase{
+		{s, "number", jsonrpc2.ID{Num: 1}, jsonrpc2.ID{Num: 1}},
+		{s, "string", jsonrpc2.ID{Str: "a", IsString: true}, jsonrpc2.ID{Str: "a", IsString: true}},
+		{s, "tagged number", number, jsonrpc2.ID{Num: 1}},
+		{s, "tagged string with escapes", escaped, jsonrpc2.ID{Str: "a\ab\"c", IsString: true}},
+		{s, "unknown tag", jsonrpc2.ID{Str: batchIDPrefix + "9", IsString: true},
+			jsonrpc2.ID{Str: batchIDPrefix + "9", IsString: true}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
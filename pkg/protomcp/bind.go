@@ -0,0 +1,104 @@
+package protomcp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// fieldsDecoder returns a decoder setting the fields of a message from
+// text values, such as URI template variables. Fields are found by name
+// or JSON name, and must be singular scalars or enums.
+func fieldsDecoder(values map[string]string) func(proto.Message) error {
+	return func(m proto.Message) error {
+		msg := m.ProtoReflect()
+		for name, value := range values {
+			if err := setField(msg, name, value); err != nil {
+				return NewError(CodeInvalidParams, "invalid params: %s: %v", name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// setField sets a field of a message from its text value.
+func setField(msg protoreflect.Message, name, value string) error {
+	fields := msg.Descriptor().Fields()
+	fd := fields.ByName(protoreflect.Name(name))
+	if fd == nil {
+		fd = fields.ByJSONName(name)
+	}
+
+	switch {
+	case fd == nil:
+		return errors.New("unknown field")
+	case fd.IsList(), fd.IsMap(), fd.Message() != nil:
+		return errors.New("unsupported field type")
+	}
+
+	v, err := parseScalar(fd, value)
+	if err != nil {
+		return err
+	}
+	msg.Set(fd, v)
+	return nil
+}
+
+// parseScalar parses the text value of a scalar or enum field.
+func parseScalar(fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(s)), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(s)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.EnumKind:
+		return parseEnum(fd.Enum(), s)
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(s, 32)
+		return protoreflect.ValueOfFloat32(float32(f)), err
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(s, 64)
+		return protoreflect.ValueOfFloat64(f), err
+	default:
+		return parseInteger(fd.Kind(), s)
+	}
+}
+
+// parseInteger parses the text value of an integer field.
+func parseInteger(kind protoreflect.Kind, s string) (protoreflect.Value, error) {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfInt32(int32(n)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(n), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(n)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(n), err
+	default:
+		return protoreflect.Value{}, errors.New("unsupported field type")
+	}
+}
+
+// parseEnum parses an enum value given by name or number.
+func parseEnum(ed protoreflect.EnumDescriptor, s string) (protoreflect.Value, error) {
+	if v := ed.Values().ByName(protoreflect.Name(s)); v != nil {
+		return protoreflect.ValueOfEnum(v.Number()), nil
+	}
+
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return protoreflect.Value{}, fmt.Errorf("unknown %s value", ed.FullName())
+	}
+	return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+}
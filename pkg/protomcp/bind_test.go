@@ -0,0 +1,79 @@
+package protomcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/typepb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// compactJSON removes the insignificant whitespace of a JSON document.
+func compactJSON(t *testing.T, s string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+// fieldsDecoderTestCase represents a test case for fieldsDecoder
+type fieldsDecoderTestCase struct {
+	msg    proto.Message
+	values map[string]string
+	name   string
+	want   string
+	ok     bool
+}
+
+// test runs the fieldsDecoder test case
+func (tc fieldsDecoderTestCase) test(t *testing.T) {
+	t.Helper()
+
+	err := fieldsDecoder(tc.values)(tc.msg)
+	if (err == nil) != tc.ok {
+		t.Fatalf("decode error = %v, want ok %v", err, tc.ok)
+	}
+	if !tc.ok {
+		if !isInvalidParams(err) {
+			t.Errorf("decode error = %v, want invalid params", err)
+		}
+		return
+	}
+
+	if got := protojson.Format(tc.msg); compactJSON(t, got) != tc.want {
+		t.Errorf("decoded %s, want %s", got, tc.want)
+	}
+}
+
+func TestFieldsDecoder(t *testing.T) {
+	tests := []fieldsDecoderTestCase{
+		{&apipb.Method{}, map[string]string{"name": "Get"}, "string", `{"name":"Get"}`, true},
+		{&apipb.Method{}, map[string]string{"requestStreaming": "true"}, "JSON name",
+			`{"requestStreaming":true}`, true},
+		{&apipb.Method{}, map[string]string{"syntax": "SYNTAX_EDITIONS"}, "enum name",
+			`{"syntax":"SYNTAX_EDITIONS"}`, true},
+		{&apipb.Method{}, map[string]string{"syntax": "1"}, "enum number", `{"syntax":"SYNTAX_PROTO3"}`, true},
+		{&typepb.Field{}, map[string]string{"number": "7"}, "int32", `{"number":7}`, true},
+		{&wrapperspb.UInt64Value{}, map[string]string{"value": "18446744073709551615"}, "uint64",
+			`"18446744073709551615"`, true},
+		{&wrapperspb.DoubleValue{}, map[string]string{"value": "1.5"}, "double", `1.5`, true},
+		{&wrapperspb.BytesValue{}, map[string]string{"value": "hi"}, "bytes", `"aGk="`, true},
+		{&apipb.Method{}, map[string]string{"nope": "x"}, "unknown field", "", false},
+		{&apipb.Method{}, map[string]string{"options": "x"}, "repeated field", "", false},
+		{&apipb.Method{}, map[string]string{"requestStreaming": "maybe"}, "invalid bool", "", false},
+		{&apipb.Method{}, map[string]string{"syntax": "NOPE"}, "invalid enum", "", false},
+		{&typepb.Field{}, map[string]string{"number": "x"}, "invalid int", "", false},
+		{&wrapperspb.UInt32Value{}, map[string]string{"value": "-1"}, "negative uint", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
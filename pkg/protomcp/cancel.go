@@ -0,0 +1,64 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// MethodCancelled is the notification cancelling a request in progress.
+const MethodCancelled = "notifications/cancelled"
+
+// ErrRequestCancelled is the cause of the cancellation of the context of
+// requests cancelled by the client.
+var ErrRequestCancelled = errors.New("request cancelled by client")
+
+// CancelledParams are the params of notifications/cancelled.
+type CancelledParams struct {
+	Reason    string      `json:"reason,omitempty"`
+	RequestID jsonrpc2.ID `json:"requestId"`
+}
+
+// withCancel returns a copy of ctx the client can cancel through
+// notifications/cancelled while the request with the given id is handled,
+// and the function to call once done. Batched requests are cancelled by
+// their original id.
+func withCancel(ctx context.Context, id jsonrpc2.ID) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	sess, ok := SessionFromContext(ctx)
+	if !ok {
+		return ctx, func() { cancel(nil) }
+	}
+
+	sess.startRequest(id, cancel)
+	return ctx, func() {
+		sess.endRequest(id)
+		cancel(nil)
+	}
+}
+
+// handleCancelled implements notifications/cancelled. The response to the
+// cancelled request is still sent, as clients ignore it, so that batches
+// complete. Unknown or completed requests are ignored.
+func handleCancelled(ctx context.Context, params json.RawMessage) (any, error) {
+	var p CancelledParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	sess, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, NewError(CodeInternalError, "no session")
+	}
+
+	cause := ErrRequestCancelled
+	if p.Reason != "" {
+		cause = fmt.Errorf("%w: %s", ErrRequestCancelled, p.Reason)
+	}
+	sess.cancelRequest(p.RequestID, cause)
+	return nil, nil
+}
@@ -0,0 +1,119 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// newTestCancelServer returns an initialised MCP peer with a tool
+// blocking until cancelled, and the channel telling it started. Requests
+// are handled one at a time.
+func newTestCancelServer(t *testing.T) (*testPeer, <-chan struct{}) {
+	t.Helper()
+
+	started := make(chan struct{}, 1)
+	s := newTestMCPServer()
+	s.AddTool(&Tool{
+		Name:        "export",
+		InputSchema: json.RawMessage(`{"type":"object"}`),
+	}, NewMethodHandler(func(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, context.Cause(ctx)
+	}))
+
+	s.Dispatcher().MaxWorkers = 1
+	p := newTestPeer(t, s)
+	p.initialize(t)
+	return p, started
+}
+
+// waitStarted waits for the tool to start.
+func waitStarted(t *testing.T, started <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-started:
+	case <-time.After(testTimeout):
+		t.Fatal("tool not started")
+	}
+}
+
+func TestMCPServerCancel(t *testing.T) {
+	t.Run("with reason", testMCPServerCancelReason)
+	t.Run("without reason", testMCPServerCancelNoReason)
+	t.Run("unknown request", testMCPServerCancelUnknown)
+	t.Run("batched request", testMCPServerCancelBatched)
+	t.Run("queued request", testMCPServerCancelQueued)
+}
+
+func testMCPServerCancelReason(t *testing.T) {
+	p, started := newTestCancelServer(t)
+
+	p.send(t, `{"jsonrpc":"2.0","id":"x1","method":"tools/call","params":{"name":"export"}}`)
+	waitStarted(t, started)
+
+	p.send(t, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"x1","reason":"bored"}}`)
+	p.recvResponse(t).assertResult(t, `"x1"`, `{"content":[{"type":"text",`+
+		`"text":"request cancelled by client: bored"}],"isError":true}`)
+}
+
+func testMCPServerCancelNoReason(t *testing.T) {
+	p, started := newTestCancelServer(t)
+
+	p.send(t, `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"export"}}`)
+	waitStarted(t, started)
+
+	p.send(t, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7}}`)
+	p.recvResponse(t).assertResult(t, "7", `{"content":[{"type":"text",`+
+		`"text":"request cancelled by client"}],"isError":true}`)
+}
+
+func testMCPServerCancelUnknown(t *testing.T) {
+	p, _ := newTestCancelServer(t)
+
+	p.send(t, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":99}}`)
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	resp.assertResult(t, "1", `{}`)
+}
+
+func testMCPServerCancelBatched(t *testing.T) {
+	p, started := newTestCancelServer(t)
+
+	p.send(t, `[{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"export"}}]`)
+	waitStarted(t, started)
+
+	p.send(t, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7}}`)
+	resp := p.recvBatch(t)
+	if len(resp) != 1 {
+		t.Fatalf("got %d responses, want 1", len(resp))
+	}
+	resp[0].assertResult(t, "7", `{"content":[{"type":"text",`+
+		`"text":"request cancelled by client"}],"isError":true}`)
+}
+
+func testMCPServerCancelQueued(t *testing.T) {
+	p, started := newTestCancelServer(t)
+
+	p.send(t, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"export"}}`)
+	waitStarted(t, started)
+
+	// waits for the first one
+	p.send(t, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"export"}}`)
+	p.send(t, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":2}}`)
+	p.recvResponse(t).assertError(t, "2", CodeInternalError)
+
+	p.send(t, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}`)
+	p.recvResponse(t).assertResult(t, "1", `{"content":[{"type":"text",`+
+		`"text":"request cancelled by client"}],"isError":true}`)
+
+	select {
+	case <-started:
+		t.Error("cancelled request started")
+	default:
+	}
+}
@@ -0,0 +1,195 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// MethodCompletionComplete is the MCP argument completion method.
+const MethodCompletionComplete = "completion/complete"
+
+// Types of the references of completion requests.
+const (
+	RefTypePrompt   = "ref/prompt"
+	RefTypeResource = "ref/resource"
+)
+
+// MaxCompletionValues is the maximum number of values returned by
+// completion/complete.
+const MaxCompletionValues = 100
+
+// CompleteReference identifies the prompt, or resource template, whose
+// argument is completed.
+type CompleteReference struct {
+	// Type is RefTypePrompt or RefTypeResource.
+	Type string `json:"type"`
+	// Name is the name of the prompt.
+	Name string `json:"name,omitempty"`
+	// URI is the URI template of the resource.
+	URI string `json:"uri,omitempty"`
+}
+
+// PromptReference returns the CompleteReference of a prompt.
+func PromptReference(name string) CompleteReference {
+	return CompleteReference{Type: RefTypePrompt, Name: name}
+}
+
+// ResourceReference returns the CompleteReference of a resource template.
+func ResourceReference(uriTemplate string) CompleteReference {
+	return CompleteReference{Type: RefTypeResource, URI: uriTemplate}
+}
+
+// key returns the identity of the reference.
+func (ref CompleteReference) key() string {
+	if ref.Type == RefTypeResource {
+		return ref.Type + " " + ref.URI
+	}
+	return ref.Type + " " + ref.Name
+}
+
+// CompleteArgument is the argument being completed.
+type CompleteArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CompleteContext carries the values of the arguments already resolved.
+type CompleteContext struct {
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// CompleteParams are the params of completion/complete.
+type CompleteParams struct {
+	Context  *CompleteContext  `json:"context,omitempty"`
+	Ref      CompleteReference `json:"ref"`
+	Argument CompleteArgument  `json:"argument"`
+}
+
+// CompleteResult is the result of completion/complete.
+type CompleteResult struct {
+	Completion Completion `json:"completion"`
+}
+
+// Completion are the values suggested for an argument.
+type Completion struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total,omitempty"`
+	HasMore bool     `json:"hasMore,omitempty"`
+}
+
+// Completer suggests values for the arguments of prompts and resource
+// templates. Generated code registers services implementing it as the
+// completer of their prompts and resource templates.
+type Completer interface {
+	Complete(ctx context.Context, params *CompleteParams) ([]string, error)
+}
+
+// CompleterFunc is a function implementing Completer.
+type CompleterFunc func(ctx context.Context, params *CompleteParams) ([]string, error)
+
+// Complete implements Completer by calling fn.
+func (fn CompleterFunc) Complete(ctx context.Context, params *CompleteParams) ([]string, error) {
+	return fn(ctx, params)
+}
+
+// EnumCompleter returns a Completer suggesting the given values starting
+// with the argument value, ignoring case. Generated code uses it for the
+// arguments bound to enum fields.
+func EnumCompleter(values ...string) Completer {
+	return CompleterFunc(func(_ context.Context, params *CompleteParams) ([]string, error) {
+		prefix := strings.ToLower(params.Argument.Value)
+
+		out := []string{}
+		for _, v := range values {
+			if strings.HasPrefix(strings.ToLower(v), prefix) {
+				out = append(out, v)
+			}
+		}
+		return out, nil
+	})
+}
+
+// AddCompletion registers the Completer of an argument of a prompt or
+// resource template, replacing any previous one. An empty argument name
+// registers the completer of the arguments without one of their own.
+func (s *MCPServer) AddCompletion(ref CompleteReference, argument string, c Completer) {
+	if c == nil {
+		panic(errors.New("protomcp: invalid completer"))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.completers == nil {
+		s.d.HandleFunc(MethodCompletionComplete, s.handleComplete)
+		s.completers = make(map[string]Completer)
+	}
+	if s.caps.Completions == nil {
+		s.caps.Completions = &CompletionsCapability{}
+	}
+
+	s.completers[ref.key()+" "+argument] = c
+}
+
+// completer returns the Completer of an argument, if any, checking the
+// reference exists.
+func (s *MCPServer) completer(ref CompleteReference, argument string) (Completer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ok bool
+	switch ref.Type {
+	case RefTypePrompt:
+		_, ok = s.prompts.get(ref.Name)
+	case RefTypeResource:
+		_, ok = s.templates.get(ref.URI)
+	default:
+		return nil, NewError(CodeInvalidParams, "invalid reference type: %s", ref.Type)
+	}
+	if !ok {
+		return nil, NewError(CodeInvalidParams, "unknown reference: %s", ref.key())
+	}
+
+	if c, ok := s.completers[ref.key()+" "+argument]; ok {
+		return c, nil
+	}
+	return s.completers[ref.key()+" "], nil
+}
+
+// handleComplete implements completion/complete. Arguments without a
+// Completer have no suggestions.
+func (s *MCPServer) handleComplete(ctx context.Context, params json.RawMessage) (any, error) {
+	var p CompleteParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	c, err := s.completer(p.Ref, p.Argument.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	if c != nil {
+		values, err = c.Complete(ctx, &p)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &CompleteResult{Completion: newCompletion(values)}, nil
+}
+
+// newCompletion returns the Completion of some values, truncated to
+// MaxCompletionValues.
+func newCompletion(values []string) Completion {
+	if len(values) <= MaxCompletionValues {
+		return Completion{Values: append([]string{}, values...)}
+	}
+	return Completion{
+		Values:  values[:MaxCompletionValues],
+		Total:   len(values),
+		HasMore: true,
+	}
+}
@@ -0,0 +1,144 @@
+package protomcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// newTestCompletionServer returns an initialised MCP peer with completers
+// for the review prompt and the users resource template.
+func newTestCompletionServer(t *testing.T) *testPeer {
+	t.Helper()
+
+	s := newTestMCPServer()
+	s.AddPromptTemplate(&Prompt{
+		Name:      "review",
+		Arguments: []PromptArgument{{Name: "role"}, {Name: "user_id"}},
+	}, "Review {{user_id}} as {{role}}.")
+	s.AddResourceTemplate(&ResourceTemplate{URITemplate: "users://{id}", Name: "user"},
+		NewMethodHandler(func(_ context.Context, req *emptypb.Empty) (*emptypb.Empty, error) {
+			return req, nil
+		}))
+
+	s.AddCompletion(PromptReference("review"), "role", EnumCompleter("ADMIN", "AUDITOR", "USER"))
+	s.AddCompletion(PromptReference("review"), "", CompleterFunc(
+		func(_ context.Context, p *CompleteParams) ([]string, error) {
+			role := p.Context.Arguments["role"]
+			return []string{p.Argument.Name + ":" + role}, nil
+		}))
+	s.AddCompletion(ResourceReference("users://{id}"), "id", CompleterFunc(
+		func(context.Context, *CompleteParams) ([]string, error) {
+			out := make([]string, 150)
+			for i := range out {
+				out[i] = fmt.Sprint(i)
+			}
+			return out, nil
+		}))
+
+	if s.Capabilities().Completions == nil {
+		t.Error("completions capability not advertised")
+	}
+
+	p := newTestPeer(t, s)
+	p.initialize(t)
+	return p
+}
+
+// completionsCapabilityTestCase represents a test case for the completions
+// capability of each protocol revision
+type completionsCapabilityTestCase struct {
+	version    string
+	advertised bool
+}
+
+// test runs the completions capability test case
+func (tc completionsCapabilityTestCase) test(t *testing.T) {
+	s := NewMCPServer("test-server", "0.1.0")
+	s.ProtocolVersions = []string{tc.version}
+	s.AddCompletion(PromptReference("review"), "", EnumCompleter("ADMIN"))
+
+	result := newTestPeer(t, s).initialize(t)
+	if got := result.Capabilities.Completions != nil; got != tc.advertised {
+		t.Errorf("completions advertised = %v, want %v", got, tc.advertised)
+	}
+}
+
+func TestMCPServerCompletionsCapability(t *testing.T) {
+	tests := []completionsCapabilityTestCase{
+		{ProtocolVersion20250618, true},
+		{ProtocolVersion20250326, true},
+		{ProtocolVersion20241105, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.version, tc.test)
+	}
+}
+
+// completeRequest returns a completion/complete request.
+func completeRequest(ref, argument, value string) string {
+	return `{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{"ref":` + ref +
+		`,"argument":{"name":"` + argument + `","value":"` + value + `"},` +
+		`"context":{"arguments":{"role":"ADMIN"}}}}`
+}
+
+// completeTestCase represents a test case for completion/complete
+type completeTestCase struct {
+	name     string
+	ref      string
+	argument string
+	value    string
+	result   string
+}
+
+// test runs the completion/complete test case
+func (tc completeTestCase) test(t *testing.T) {
+	p := newTestCompletionServer(t)
+
+	resp := p.call(t, completeRequest(tc.ref, tc.argument, tc.value))
+	resp.assertResult(t, "1", `{"completion":`+tc.result+`}`)
+}
+
+func TestMCPServerComplete(t *testing.T) {
+	const (
+		review = `{"type":"ref/prompt","name":"review"}`
+		users  = `{"type":"ref/resource","uri":"users://{id}"}`
+	)
+
+	first := make([]string, MaxCompletionValues)
+	for i := range first {
+		first[i] = fmt.Sprintf("%q", fmt.Sprint(i))
+	}
+	truncated := `{"values":[` + strings.Join(first, ",") + `],"total":150,"hasMore":true}`
+
+	tests := []completeTestCase{
+		{"enum", review, "role", "a", `{"values":["ADMIN","AUDITOR"]}`},
+		{"enum all", review, "role", "", `{"values":["ADMIN","AUDITOR","USER"]}`},
+		{"enum none", review, "role", "x", `{"values":[]}`},
+		{"fallback", review, "user_id", "", `{"values":["user_id:ADMIN"]}`},
+		{"truncated", users, "id", "", truncated},
+		{"no completer", users, "other", "", `{"values":[]}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestMCPServerCompleteErrors(t *testing.T) {
+	p := newTestCompletionServer(t)
+
+	for i, ref := range []string{
+		`{"type":"ref/prompt","name":"nope"}`,
+		`{"type":"ref/resource","uri":"nope://{id}"}`,
+		`{"type":"ref/tool","name":"review"}`,
+	} {
+		req := strings.Replace(completeRequest(ref, "id", ""), `"id":1`, fmt.Sprintf(`"id":%d`, i), 1)
+		resp := p.call(t, req)
+		resp.assertError(t, fmt.Sprint(i), CodeInvalidParams)
+	}
+}
@@ -0,0 +1,21 @@
+package protomcp
+
+// Content types.
+const (
+	ContentTypeText  = "text"
+	ContentTypeImage = "image"
+	ContentTypeAudio = "audio"
+)
+
+// Content is a piece of content of a tool result or a prompt message.
+type Content struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// TextContent returns a text Content.
+func TextContent(text string) Content {
+	return Content{Type: ContentTypeText, Text: text}
+}
@@ -14,7 +14,7 @@
 // # Core Components
 //
 //   - Protocol-specific dispatchers that route messages to service implementations
-//   - Transport abstractions for HTTP/2 and QUIC
+//   - Transport abstractions for HTTP/2, QUIC and WebSocket
 //   - JSON Schema validation for request/response type safety
 //   - Middleware support using darvaza.org/x/web
 //   - Error handling with protocol-specific mappings
@@ -24,7 +24,9 @@
 // This package integrates with:
 //
 //   - darvaza.org/core for error handling and utilities
-//   - darvaza.org/slog for structured logging
+//   - darvaza.org/slog for structured logging, forwarded to MCP clients
+//     by protomcp.org/protomcp/pkg/mcplog
+//   - log/slog through LogHandler, forwarding to MCP clients too
 //   - encoding/json/v2 for modern JSON handling
 //
 // Generated code imports this package to access protocol implementations,
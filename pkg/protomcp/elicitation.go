@@ -0,0 +1,65 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// MethodElicitationCreate is the request asking the client for
+// information from the user.
+const MethodElicitationCreate = "elicitation/create"
+
+// Actions of the user answering an elicitation.
+const (
+	ElicitActionAccept  = "accept"
+	ElicitActionDecline = "decline"
+	ElicitActionCancel  = "cancel"
+)
+
+// ElicitParams are the params of elicitation/create.
+type ElicitParams struct {
+	// Message is presented to the user.
+	Message string `json:"message"`
+	// RequestedSchema is the JSON Schema of the content requested, an
+	// object with primitive properties only.
+	RequestedSchema json.RawMessage `json:"requestedSchema"`
+}
+
+// ElicitResult is the result of elicitation/create.
+type ElicitResult struct {
+	// Content is the data submitted by the user if accepted.
+	Content map[string]any `json:"content,omitempty"`
+	// Action is ElicitActionAccept, ElicitActionDecline or
+	// ElicitActionCancel.
+	Action string `json:"action"`
+}
+
+// Accepted tells if the user accepted the elicitation.
+func (r *ElicitResult) Accepted() bool {
+	return r.Action == ElicitActionAccept
+}
+
+// Elicit asks the client for information from the user, waiting for the
+// answer. It fails with ErrNotSupportedByClient if the client doesn't
+// support elicitation.
+func (s *Session) Elicit(ctx context.Context, params *ElicitParams) (*ElicitResult, error) {
+	if s.ClientCapabilities().Elicitation == nil {
+		return nil, ErrNotSupportedByClient
+	}
+
+	var out ElicitResult
+	if err := s.call(ctx, MethodElicitationCreate, params, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Elicit asks the client of the session of a request for information from
+// the user. See Session.Elicit.
+func Elicit(ctx context.Context, params *ElicitParams) (*ElicitResult, error) {
+	sess, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, ErrNotSupportedByClient
+	}
+	return sess.Elicit(ctx, params)
+}
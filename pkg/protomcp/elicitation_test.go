@@ -0,0 +1,49 @@
+package protomcp
+
+import "testing"
+
+func TestSessionElicit(t *testing.T) {
+	t.Run("accepted", testSessionElicitAccepted)
+	t.Run("declined", testSessionElicitDeclined)
+	t.Run("not supported", testSessionElicitNotSupported)
+}
+
+// confirmRequest is the elicitation/create request sent by the confirm
+// method.
+const confirmRequest = `{"message":"Are you sure?","requestedSchema":{"type":"object"}}`
+
+func testSessionElicitAccepted(t *testing.T) {
+	p := newTestClientPeer(t, `{"elicitation":{}}`)
+
+	p.send(t, `{"jsonrpc":"2.0","id":1,"method":"confirm"}`)
+	assertRequest(t, p.recv(t), "1", MethodElicitationCreate, confirmRequest)
+
+	p.send(t, `{"jsonrpc":"2.0","id":1,"result":{"action":"accept","content":{"sure":true}}}`)
+	p.recvResponse(t).assertResult(t, "1", `{"content":{"sure":true},"action":"accept"}`)
+}
+
+func testSessionElicitDeclined(t *testing.T) {
+	p := newTestClientPeer(t, `{"elicitation":{}}`)
+
+	p.send(t, `{"jsonrpc":"2.0","id":1,"method":"confirm"}`)
+	assertRequest(t, p.recv(t), "1", MethodElicitationCreate, confirmRequest)
+
+	p.send(t, `{"jsonrpc":"2.0","id":1,"result":{"action":"decline"}}`)
+	p.recvResponse(t).assertResult(t, "1", `{"action":"decline"}`)
+}
+
+func testSessionElicitNotSupported(t *testing.T) {
+	p := newTestClientPeer(t, `{"sampling":{}}`)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"confirm"}`)
+	resp.assertError(t, "1", CodeInternalError)
+}
+
+func TestElicitResultAccepted(t *testing.T) {
+	if !(&ElicitResult{Action: ElicitActionAccept}).Accepted() {
+		t.Error("accept not accepted")
+	}
+	if (&ElicitResult{Action: ElicitActionCancel}).Accepted() {
+		t.Error("cancel accepted")
+	}
+}
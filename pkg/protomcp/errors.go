@@ -0,0 +1,65 @@
+package protomcp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode is a JSON-RPC 2.0 error code.
+type ErrorCode int64
+
+// Error codes defined by the JSON-RPC 2.0 specification.
+const (
+	// CodeParseError indicates the server received invalid JSON.
+	CodeParseError ErrorCode = -32700
+	// CodeInvalidRequest indicates the JSON sent is not a valid request object.
+	CodeInvalidRequest ErrorCode = -32600
+	// CodeMethodNotFound indicates the method does not exist or is not available.
+	CodeMethodNotFound ErrorCode = -32601
+	// CodeInvalidParams indicates invalid method parameters.
+	CodeInvalidParams ErrorCode = -32602
+	// CodeInternalError indicates an internal error while handling the request.
+	CodeInternalError ErrorCode = -32603
+)
+
+// Error codes defined by the MCP specification.
+const (
+	// CodeResourceNotFound indicates the requested resource does not exist.
+	CodeResourceNotFound ErrorCode = -32002
+)
+
+// Error is a protocol-agnostic error carrying a JSON-RPC 2.0 error code.
+// Service implementations may return it to control the error reported to
+// clients; any other error is reported as an internal error.
+type Error struct {
+	Data    any
+	Message string
+	Code    ErrorCode
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+// NewError creates an *Error with the given code and formatted message.
+func NewError(code ErrorCode, format string, args ...any) *Error {
+	msg := format
+	if len(args) > 0 {
+		msg = fmt.Sprintf(format, args...)
+	}
+	return &Error{Code: code, Message: msg}
+}
+
+// NewMethodNotFoundError returns the error reported when a method is not
+// implemented. Generated Unimplemented servers use it so that methods added
+// to a service after an implementation was written fail gracefully.
+func NewMethodNotFoundError(method string) *Error {
+	return NewError(CodeMethodNotFound, "method not found: %s", method)
+}
+
+// IsMethodNotFound tells if err is, or wraps, a method not found *Error.
+func IsMethodNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == CodeMethodNotFound
+}
@@ -0,0 +1,65 @@
+package protomcp
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNewError(t *testing.T) {
+	t.Run("plain message", testNewErrorPlain)
+	t.Run("formatted message", testNewErrorFormatted)
+}
+
+func testNewErrorPlain(t *testing.T) {
+	err := NewError(CodeInvalidParams, "bad params")
+	if err.Code != CodeInvalidParams {
+		t.Errorf("Code = %d, want %d", err.Code, CodeInvalidParams)
+	}
+	if err.Message != "bad params" {
+		t.Errorf("Message = %q, want %q", err.Message, "bad params")
+	}
+}
+
+func testNewErrorFormatted(t *testing.T) {
+	err := NewError(CodeInternalError, "failed %s: %d", "op", 42)
+	if err.Message != "failed op: 42" {
+		t.Errorf("Message = %q, want %q", err.Message, "failed op: 42")
+	}
+	if got, want := err.Error(), "failed op: 42 (-32603)"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestNewMethodNotFoundError(t *testing.T) {
+	err := NewMethodNotFoundError("pkg.Service/Method")
+
+	if err.Code != CodeMethodNotFound {
+		t.Errorf("Code = %d, want %d", err.Code, CodeMethodNotFound)
+	}
+	if want := "method not found: pkg.Service/Method"; err.Message != want {
+		t.Errorf("Message = %q, want %q", err.Message, want)
+	}
+}
+
+func TestIsMethodNotFound(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		want bool
+	}{
+		{nil, "nil", false},
+		{errors.New("plain"), "plain error", false},
+		{NewError(CodeInvalidParams, "bad"), "other code", false},
+		{NewMethodNotFoundError("a/b"), "method not found", true},
+		{fmt.Errorf("wrapped: %w", NewMethodNotFoundError("a/b")), "wrapped", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsMethodNotFound(tc.err); got != tc.want {
+				t.Errorf("IsMethodNotFound() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
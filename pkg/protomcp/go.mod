@@ -1,3 +1,23 @@
 module protomcp.org/protomcp/pkg/protomcp
 
 go 1.23.0
+
+require (
+	darvaza.org/core v0.17.4
+	github.com/gorilla/websocket v1.5.3
+	github.com/quic-go/quic-go v0.54.1
+	github.com/sourcegraph/jsonrpc2 v0.2.3
+	golang.org/x/net v0.42.0
+	google.golang.org/protobuf v1.36.6
+)
+
+require (
+	github.com/quic-go/qpack v0.5.1 // indirect
+	go.uber.org/mock v0.5.0 // indirect
+	golang.org/x/crypto v0.40.0 // indirect
+	golang.org/x/mod v0.25.0 // indirect
+	golang.org/x/sync v0.16.0 // indirect
+	golang.org/x/sys v0.34.0 // indirect
+	golang.org/x/text v0.27.0 // indirect
+	golang.org/x/tools v0.34.0 // indirect
+)
@@ -0,0 +1,94 @@
+package protomcp
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+// HTTP3Server serves an http.Handler over HTTP/3 on QUIC, such as the
+// StreamableHTTPHandler of an MCPServer or a mux mounting it alongside
+// other handlers.
+//
+// The TLS configuration can be shared with the HTTP/2 server of the same
+// handler, as it is cloned to negotiate HTTP/3.
+type HTTP3Server struct {
+	onShutdown func() // closes the sessions of the handler
+	srv        http3.Server
+}
+
+// NewHTTP3Server returns an HTTP3Server serving handler on the UDP
+// address addr, ":https" if empty. The TLS configuration is required, and
+// serving fails without it.
+//
+// When handler is a Server, a StreamableHTTPHandler, an SSEHandler or a
+// WebSocketHandler, its sessions and connections are terminated on
+// shutdown.
+func NewHTTP3Server(addr string, handler http.Handler, tlsConfig *tls.Config) *HTTP3Server {
+	return &HTTP3Server{
+		onShutdown: shutdownHandler(handler),
+		srv: http3.Server{
+			Addr:      addr,
+			Handler:   handler,
+			TLSConfig: tlsConfig,
+		},
+	}
+}
+
+// shutdownHandler returns the function terminating the sessions and
+// connections held by a handler, if any.
+func shutdownHandler(h http.Handler) func() {
+	switch h := h.(type) {
+	case *Server:
+		return h.closeHandlers
+	case closeHandler:
+		return func() { _ = h.Close() }
+	default:
+		return nil
+	}
+}
+
+// ListenAndServe listens on the UDP address of the server and serves
+// HTTP/3 until shut down, when it returns http.ErrServerClosed.
+func (s *HTTP3Server) ListenAndServe() error {
+	return s.srv.ListenAndServe()
+}
+
+// Serve serves HTTP/3 on a UDP connection until shut down, when it
+// returns http.ErrServerClosed. The connection isn't closed.
+func (s *HTTP3Server) Serve(conn net.PacketConn) error {
+	return s.srv.Serve(conn)
+}
+
+// Shutdown terminates the sessions of the handler, stops accepting
+// connections and tells clients to go away, then waits for their requests
+// to complete until ctx is done, when the remaining connections are
+// closed.
+func (s *HTTP3Server) Shutdown(ctx context.Context) error {
+	s.closeHandler()
+	return s.srv.Shutdown(ctx)
+}
+
+// Close closes all connections immediately, and terminates the sessions
+// of the handler.
+func (s *HTTP3Server) Close() error {
+	s.closeHandler()
+	return s.srv.Close()
+}
+
+// closeHandler terminates the sessions of the handler, if any.
+func (s *HTTP3Server) closeHandler() {
+	if s.onShutdown != nil {
+		s.onShutdown()
+	}
+}
+
+// SetAltSvcHeader adds the Alt-Svc header advertising the server to the
+// headers of an HTTP/1 or HTTP/2 response. It fails until the server is
+// listening.
+func (s *HTTP3Server) SetAltSvcHeader(hdr http.Header) error {
+	return s.srv.SetQUICHeaders(hdr)
+}
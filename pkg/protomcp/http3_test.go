@@ -0,0 +1,226 @@
+package protomcp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+// newTestTLSConfig returns the TLS configuration of a server with a
+// self-signed certificate for the loopback address, and the pool of
+// certificates trusting it.
+func newTestTLSConfig(t *testing.T) (*tls.Config, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "protomcp test"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}},
+		MinVersion:   tls.VersionTLS13,
+	}, pool
+}
+
+// testHTTP3 is an HTTP3Server on the loopback interface and its client.
+type testHTTP3 struct {
+	s      *HTTP3Server
+	tr     *http3.Transport
+	served chan error
+	url    string
+}
+
+// newTestHTTP3 serves a handler over HTTP/3 on the loopback interface.
+func newTestHTTP3(t *testing.T, h http.Handler) *testHTTP3 {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp: %v", err)
+	}
+
+	cfg, pool := newTestTLSConfig(t)
+	ts := &testHTTP3{
+		s:      NewHTTP3Server("", h, cfg),
+		tr:     &http3.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS13}},
+		served: make(chan error, 1),
+		url:    "https://" + conn.LocalAddr().String(),
+	}
+	go func() {
+		ts.served <- ts.s.Serve(conn)
+	}()
+
+	t.Cleanup(func() {
+		_ = ts.tr.Close()
+		_ = ts.s.Close()
+		_ = conn.Close()
+	})
+	return ts
+}
+
+// post sends a POST request to the server.
+func (ts *testHTTP3) post(t *testing.T, session, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, ts.url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Content-Type", contentTypeJSON)
+	if session != "" {
+		req.Header.Set(HeaderSessionID, session)
+	}
+
+	resp, err := (&http.Client{Transport: ts.tr, Timeout: testTimeout}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	if resp.Proto != "HTTP/3.0" {
+		t.Errorf("got %s, want HTTP/3.0", resp.Proto)
+	}
+	return resp
+}
+
+func TestHTTP3Server(t *testing.T) {
+	t.Run("streamable", testHTTP3ServerStreamable)
+	t.Run("alt-svc", testHTTP3ServerAltSvc)
+	t.Run("shutdown", testHTTP3ServerShutdown)
+	t.Run("shutdown sessions", testHTTP3ServerShutdownSessions)
+	t.Run("no tls config", testHTTP3ServerNoTLSConfig)
+}
+
+func testHTTP3ServerStreamable(t *testing.T) {
+	h := NewStreamableHTTPHandler(newTestHTTPServer())
+	t.Cleanup(func() { _ = h.Close() })
+	ts := newTestHTTP3(t, h)
+
+	resp := ts.post(t, "", initRequest)
+	session := resp.Header.Get(HeaderSessionID)
+	if resp.StatusCode != http.StatusOK || session == "" {
+		t.Fatalf("initialize: got %s, session %q", resp.Status, session)
+	}
+	resp = ts.post(t, session, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("initialized: got %s", resp.Status)
+	}
+
+	events := newTestEvents(t, ts.post(t, session, `{"jsonrpc":"2.0","id":1,"method":"tools/call",`+
+		`"params":{"name":"reindex","_meta":{"progressToken":"t1"}}}`))
+	assertNotification(t, []byte(events.next(t)), MethodProgress,
+		`{"message":"half","progressToken":"t1","progress":1,"total":2}`)
+	assertResponseID(t, []byte(events.next(t)), "1")
+	events.assertEnd(t)
+}
+
+func testHTTP3ServerAltSvc(t *testing.T) {
+	ts := newTestHTTP3(t, http.NotFoundHandler())
+	_ = ts.post(t, "", `{}`)
+
+	hdr := make(http.Header)
+	if err := ts.s.SetAltSvcHeader(hdr); err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(ts.url, "https://"))
+	if got, want := hdr.Get("Alt-Svc"), `h3=":`+port+`"; ma=2592000`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func testHTTP3ServerShutdown(t *testing.T) {
+	ts := newTestHTTP3(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	_ = ts.post(t, "", `{}`)
+	_ = ts.tr.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	if err := ts.s.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-ts.served; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("serve: got %v, want %v", err, http.ErrServerClosed)
+	}
+}
+
+func testHTTP3ServerShutdownSessions(t *testing.T) {
+	ts := newTestHTTP3(t, NewStreamableHTTPHandler(newTestHTTPServer()))
+
+	session := ts.post(t, "", initRequest).Header.Get(HeaderSessionID)
+	req, err := http.NewRequest(http.MethodGet, ts.url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", contentTypeEventStream)
+	req.Header.Set(HeaderSessionID, session)
+	stream, err := (&http.Client{Transport: ts.tr}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = stream.Body.Close() }()
+	events := newTestEvents(t, stream)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- ts.s.Shutdown(ctx) }()
+
+	// the stream ends before clients go away
+	events.assertEnd(t)
+	_ = ts.tr.Close()
+	if err := <-shutdown; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testHTTP3ServerNoTLSConfig(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	s := NewHTTP3Server(":0", http.NotFoundHandler(), nil)
+	if err := s.Serve(conn); err == nil || errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("got %v, want an error", err)
+	}
+}
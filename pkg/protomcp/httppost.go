@@ -0,0 +1,203 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// httpPost is the content of a POST request of the Streamable HTTP
+// transport.
+type httpPost struct {
+	messages  []json.RawMessage // valid ones
+	responses []json.RawMessage // to invalid ones, then all
+	keys      []string          // ids of the requests
+	batch     bool
+	events    bool // responses can be streamed as events
+}
+
+// parsePost parses the body of a POST request. Invalid entries of a batch
+// are answered as part of the response, but an invalid body is rejected.
+func parsePost(body []byte) (*httpPost, *messageError) {
+	if !json.Valid(body) {
+		return nil, &messageError{err: NewError(CodeParseError, "parse error")}
+	}
+
+	if jsonKind(body) != '[' {
+		if e := checkMessage(body); e != nil {
+			return nil, e
+		}
+		p := &httpPost{}
+		p.add(body)
+		return p, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(body, &items); err != nil || len(items) == 0 {
+		return nil, invalidRequest(nil, "empty batch")
+	}
+
+	p := &httpPost{batch: true}
+	for _, item := range items {
+		p.add(item)
+	}
+	return p, nil
+}
+
+// add adds an entry, or the response rejecting it.
+func (p *httpPost) add(raw json.RawMessage) {
+	if e := checkMessage(raw); e != nil {
+		if out, err := json.Marshal(e.response()); err == nil {
+			p.responses = append(p.responses, out)
+		}
+		return
+	}
+
+	if key, ok := requestKey(raw); ok {
+		p.keys = append(p.keys, key)
+	}
+	p.messages = append(p.messages, raw)
+}
+
+// isInitialize tells if the request starts a new session.
+func (p *httpPost) isInitialize() bool {
+	if p.batch || len(p.messages) != 1 {
+		return false
+	}
+
+	var m struct {
+		Method string `json:"method"`
+	}
+	return json.Unmarshal(p.messages[0], &m) == nil && m.Method == MethodInitialize
+}
+
+// servePost passes the messages of a POST request to their session and
+// replies with the responses to its requests.
+func (h *StreamableHTTPHandler) servePost(w http.ResponseWriter, r *http.Request) {
+	body, ok := readBody(w, r, h.MaxBodySize)
+	if !ok {
+		return
+	}
+
+	p, e := parsePost(body)
+	if e != nil {
+		writeJSON(w, http.StatusBadRequest, e.response())
+		return
+	}
+
+	sess, ok := h.postSession(w, r, p)
+	if !ok {
+		return
+	}
+	defer h.release(sess)
+
+	p.events = accepts(r, contentTypeEventStream)
+
+	if len(p.keys) == 0 {
+		p.serveNotifications(w, r, sess.stream)
+	} else {
+		p.serveRequests(w, r, sess.stream)
+	}
+}
+
+// postSession returns the session of a POST request, starting a new one
+// on initialisation.
+func (h *StreamableHTTPHandler) postSession(w http.ResponseWriter, r *http.Request,
+	p *httpPost) (*httpSession, bool) {
+	if !p.isInitialize() {
+		return h.session(w, r)
+	}
+
+	sess, err := h.newSession()
+	if err != nil {
+		http.Error(w, "session unavailable", http.StatusServiceUnavailable)
+		return nil, false
+	}
+
+	if len(p.keys) > 0 {
+		sess.stream.initialize(p.keys[0])
+	}
+	w.Header().Set(HeaderSessionID, sess.id)
+	return sess, true
+}
+
+// push passes the messages to the session.
+func (p *httpPost) push(ctx context.Context, s *httpStream) error {
+	for _, raw := range p.messages {
+		if err := s.push(ctx, raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serveNotifications handles a POST request without requests, only
+// replying with the errors of invalid entries if any.
+func (p *httpPost) serveNotifications(w http.ResponseWriter, r *http.Request, s *httpStream) {
+	switch err := p.push(r.Context(), s); {
+	case err != nil:
+		pushFailed(w, err)
+	case len(p.responses) > 0:
+		writeJSON(w, http.StatusOK, p.responses)
+	default:
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// serveRequests handles a POST request with requests, waiting for their
+// responses.
+func (p *httpPost) serveRequests(w http.ResponseWriter, r *http.Request, s *httpStream) {
+	ex, err := s.open(p.keys, p.responses, p.events)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer s.end(ex)
+
+	if err := p.push(r.Context(), s); err != nil {
+		pushFailed(w, err)
+		return
+	}
+	p.await(w, r, s, ex)
+}
+
+// pushFailed replies to a POST request whose messages couldn't be passed
+// to the session, either terminated or not taking them before the request
+// was cancelled.
+func pushFailed(w http.ResponseWriter, err error) {
+	if errors.Is(err, jsonrpc2.ErrClosed) {
+		http.Error(w, "session terminated", http.StatusNotFound)
+	} else {
+		http.Error(w, "messages not delivered", http.StatusServiceUnavailable)
+	}
+}
+
+// await replies with the responses as JSON once all are available, or
+// relays the event stream if the exchange turns into one.
+func (p *httpPost) await(w http.ResponseWriter, r *http.Request, s *httpStream, ex *httpExchange) {
+	select {
+	case ev := <-ex.sink.events:
+		sw := newSSEWriter(w)
+		if !sw.send(ev) {
+			sw.relay(r.Context(), ex.sink, s.closed)
+		}
+	case <-ex.ready:
+		p.responses = ex.held
+		p.writeResponses(w)
+	case <-r.Context().Done():
+	case <-s.closed:
+		http.Error(w, "session terminated", http.StatusNotFound)
+	}
+}
+
+// writeResponses replies with the responses as JSON.
+func (p *httpPost) writeResponses(w http.ResponseWriter) {
+	if p.batch {
+		writeJSON(w, http.StatusOK, p.responses)
+	} else {
+		writeJSON(w, http.StatusOK, p.responses[0])
+	}
+}
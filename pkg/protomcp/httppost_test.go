@@ -0,0 +1,68 @@
+package protomcp
+
+import (
+	"strings"
+	"testing"
+)
+
+// parsePostTestCase represents a test case for parsePost
+type parsePostTestCase struct {
+	name       string
+	body       string
+	keys       string
+	code       ErrorCode
+	messages   int
+	responses  int
+	batch      bool
+	initialize bool
+}
+
+// test runs the parsePost test case
+func (tc parsePostTestCase) test(t *testing.T) {
+	p, e := parsePost([]byte(tc.body))
+	switch {
+	case tc.code != 0 && (e == nil || e.err.Code != tc.code):
+		t.Fatalf("got %v, want error %d", e, tc.code)
+	case tc.code != 0:
+		return
+	case e != nil:
+		t.Fatalf("unexpected error: %v", e.err)
+	}
+
+	tc.check(t, p)
+}
+
+// check checks the outcome of a valid test case
+func (tc parsePostTestCase) check(t *testing.T, p *httpPost) {
+	if got := strings.Join(p.keys, ","); got != tc.keys {
+		t.Errorf("keys = %q, want %q", got, tc.keys)
+	}
+	if len(p.messages) != tc.messages || len(p.responses) != tc.responses {
+		t.Errorf("got %d messages and %d responses, want %d and %d",
+			len(p.messages), len(p.responses), tc.messages, tc.responses)
+	}
+	if p.batch != tc.batch || p.isInitialize() != tc.initialize {
+		t.Errorf("batch = %v, initialize = %v", p.batch, p.isInitialize())
+	}
+}
+
+func TestParsePost(t *testing.T) {
+	tests := []parsePostTestCase{
+		{name: "request", body: `{"jsonrpc":"2.0","id":1,"method":"ping"}`, keys: "1", messages: 1},
+		{name: "initialize", body: `{"jsonrpc":"2.0","id":"a","method":"initialize","params":{}}`,
+			keys: `"a"`, messages: 1, initialize: true},
+		{name: "notification", body: `{"jsonrpc":"2.0","method":"notifications/initialized"}`, messages: 1},
+		{name: "response", body: `{"jsonrpc":"2.0","id":1,"result":{}}`, messages: 1},
+		{name: "batch", body: `[{"jsonrpc":"2.0","id":1,"method":"ping"},{"jsonrpc":"2.0","id":2,"method":"ping"},1]`,
+			keys: "1,2", messages: 2, responses: 1, batch: true},
+		{name: "batched initialize", body: `[{"jsonrpc":"2.0","id":1,"method":"initialize"}]`,
+			keys: "1", messages: 1, batch: true},
+		{name: "parse error", body: `{"jsonrpc"`, code: CodeParseError},
+		{name: "invalid", body: `{"jsonrpc":"2.0","id":1}`, code: CodeInvalidRequest},
+		{name: "empty batch", body: `[]`, code: CodeInvalidRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
@@ -0,0 +1,427 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+var _ jsonrpc2.ObjectStream = (*httpStream)(nil)
+
+// httpSinkSize is the number of events buffered by an httpSink.
+const httpSinkSize = 16
+
+// listenerStream is the event stream opened by GET requests. Those of
+// POST requests are numbered from one.
+const listenerStream = 0
+
+// errDuplicateID is returned when a request reuses the id of one in
+// progress.
+var errDuplicateID = errors.New("duplicate request id")
+
+// httpEvent is a message of the server sent as a Server-Sent Event.
+type httpEvent struct {
+	data   json.RawMessage
+	stream uint64
+	seq    uint64 // within the session
+	end    bool   // last of its stream
+}
+
+// id returns the id of the event, identifying its stream.
+func (ev *httpEvent) id() string {
+	return strconv.FormatUint(ev.stream, 10) + "-" + strconv.FormatUint(ev.seq, 10)
+}
+
+// parseEventID returns the stream and the sequence number of an event id.
+func parseEventID(id string) (stream, seq uint64, ok bool) {
+	a, b, ok := strings.Cut(id, "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	stream, err1 := strconv.ParseUint(a, 10, 64)
+	seq, err2 := strconv.ParseUint(b, 10, 64)
+	return stream, seq, err1 == nil && err2 == nil
+}
+
+// httpSink receives the events of a stream for the HTTP response
+// sending them.
+type httpSink struct {
+	events   chan httpEvent
+	done     chan struct{} // closed once detached
+	stream   uint64        // guarded by httpStream.mu
+	detached bool          // guarded by httpStream.mu
+}
+
+// newHTTPSink returns a new httpSink for an event stream.
+func newHTTPSink(stream uint64) *httpSink {
+	return &httpSink{
+		events: make(chan httpEvent, httpSinkSize),
+		done:   make(chan struct{}),
+		stream: stream,
+	}
+}
+
+// send passes events to the sink, dropping them if its HTTP response has
+// ended.
+func (k *httpSink) send(events []httpEvent) {
+	for _, ev := range events {
+		select {
+		case k.events <- ev:
+		case <-k.done:
+			return
+		}
+	}
+}
+
+// httpExchange is a POST request waiting for the responses to the
+// requests it carried. Responses are held until all are available, and
+// sent as JSON, unless the server sends other messages first. The
+// exchange then turns into an event stream, which outlives the POST
+// request until all the responses have been sent, so it can be resumed.
+type httpExchange struct {
+	sink      *httpSink
+	ready     chan struct{}     // closed once all responses are held
+	held      []json.RawMessage // responses, until an event stream
+	keys      []string
+	stream    uint64 // event stream, if any
+	remaining int    // responses not yet routed
+	events    bool   // accepts an event stream
+}
+
+// httpStream is the jsonrpc2.ObjectStream of an MCP session over
+// Streamable HTTP. The messages of the client are pushed by POST
+// requests, while those of the server are routed to event streams, or
+// held as the JSON responses of POST requests.
+//
+// Responses go to the POST request carrying their request. Other messages
+// can't be related to the request causing them, so they go to the oldest
+// POST request accepting events, or to the GET stream once opened.
+// Messages with nowhere to go are dropped.
+//
+// Events are kept in a bounded replay log, so clients can resume the
+// streams interrupted.
+type httpStream struct {
+	in        chan json.RawMessage
+	closed    chan struct{}
+	pending   map[string]*httpExchange // by request id
+	sinks     map[uint64]*httpSink     // by event stream
+	initKey   string                   // of the initialize request, until answered
+	version   string                   // protocol revision negotiated
+	log       []httpEvent              // for replay, oldest first
+	exchanges []*httpExchange          // waiting, oldest first
+	seq       uint64                   // of the last event
+	streams   uint64                   // of the last POST event stream
+	logSize   int
+	listening bool // events go to the GET stream
+	mu        sync.Mutex
+	closeOnce sync.Once
+}
+
+// newHTTPStream returns a new httpStream replaying up to logSize events.
+func newHTTPStream(logSize int) *httpStream {
+	return &httpStream{
+		in:      make(chan json.RawMessage),
+		closed:  make(chan struct{}),
+		pending: make(map[string]*httpExchange),
+		sinks:   make(map[uint64]*httpSink),
+		logSize: logSize,
+	}
+}
+
+// ReadObject implements jsonrpc2.ObjectStream.
+func (s *httpStream) ReadObject(v any) error {
+	select {
+	case raw := <-s.in:
+		return decodeMessage(raw, v)
+	case <-s.closed:
+		return io.EOF
+	}
+}
+
+// WriteObject implements jsonrpc2.ObjectStream. Events are passed to
+// their sink outside the lock, in order as writes are serialised.
+func (s *httpStream) WriteObject(obj any) error {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	k, events := s.route(raw)
+	s.mu.Unlock()
+
+	if k != nil {
+		k.send(events)
+	}
+	return nil
+}
+
+// Close implements jsonrpc2.ObjectStream.
+func (s *httpStream) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+	return nil
+}
+
+// push passes a message of the client to the session.
+func (s *httpStream) push(ctx context.Context, raw json.RawMessage) error {
+	select {
+	case s.in <- raw:
+		return nil
+	case <-s.closed:
+		return jsonrpc2.ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// route handles an outbound message, returning the events to pass to a
+// sink, if any. Must be called with the lock held.
+func (s *httpStream) route(raw json.RawMessage) (*httpSink, []httpEvent) {
+	if key, ok := responseKey(raw); ok {
+		if key == s.initKey {
+			s.initKey = ""
+			s.version = negotiatedVersion(raw)
+		}
+		return s.routeResponse(key, raw)
+	}
+
+	for _, ex := range s.exchanges {
+		if ex.events {
+			return s.routeEvent(ex, raw)
+		}
+	}
+
+	if s.listening {
+		ev := s.logEvent(listenerStream, raw, false)
+		return s.sinks[listenerStream], []httpEvent{ev}
+	}
+	return nil, nil
+}
+
+// negotiatedVersion returns the protocol revision of the response to an
+// initialize request, or empty if it failed.
+func negotiatedVersion(raw json.RawMessage) string {
+	var resp jsonrpc2.Response
+	var result InitializeResult
+	if json.Unmarshal(raw, &resp) != nil || resp.Result == nil || json.Unmarshal(*resp.Result, &result) != nil {
+		return ""
+	}
+	return result.ProtocolVersion
+}
+
+// initialize records the key of the request initialising the session,
+// to learn the protocol revision negotiated from its response.
+func (s *httpStream) initialize(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.initKey = key
+}
+
+// protocolVersion returns the protocol revision negotiated by the
+// session, or empty if not known.
+func (s *httpStream) protocolVersion() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.version
+}
+
+// routeResponse handles the response to a request, if still waited for.
+// Must be called with the lock held.
+func (s *httpStream) routeResponse(key string, raw json.RawMessage) (*httpSink, []httpEvent) {
+	ex, ok := s.pending[key]
+	if !ok {
+		return nil, nil
+	}
+
+	delete(s.pending, key)
+	ex.remaining--
+	last := ex.remaining == 0
+	if last {
+		s.removeExchange(ex)
+	}
+
+	if ex.stream == 0 {
+		ex.held = append(ex.held, raw)
+		if last {
+			close(ex.ready)
+		}
+		return nil, nil
+	}
+
+	ev := s.logEvent(ex.stream, raw, last)
+	return s.sinks[ex.stream], []httpEvent{ev}
+}
+
+// routeEvent sends a message over the event stream of an exchange,
+// starting it with the responses held if needed. Must be called with the
+// lock held.
+func (s *httpStream) routeEvent(ex *httpExchange, raw json.RawMessage) (*httpSink, []httpEvent) {
+	var events []httpEvent
+	if ex.stream == 0 {
+		s.streams++
+		ex.stream = s.streams
+		ex.sink.stream = ex.stream
+		s.sinks[ex.stream] = ex.sink
+
+		for _, held := range ex.held {
+			events = append(events, s.logEvent(ex.stream, held, false))
+		}
+		ex.held = nil
+	}
+
+	events = append(events, s.logEvent(ex.stream, raw, false))
+	return s.sinks[ex.stream], events
+}
+
+// logEvent adds an event to the replay log, discarding the oldest if
+// full. Must be called with the lock held.
+func (s *httpStream) logEvent(stream uint64, raw json.RawMessage, end bool) httpEvent {
+	s.seq++
+	ev := httpEvent{
+		data:   raw,
+		stream: stream,
+		seq:    s.seq,
+		end:    end,
+	}
+
+	if s.logSize > 0 {
+		if n := len(s.log) - s.logSize + 1; n > 0 {
+			s.log = slices.Delete(s.log, 0, n)
+		}
+		s.log = append(s.log, ev)
+	}
+	return ev
+}
+
+// removeExchange stops routing messages to an exchange. Must be called
+// with the lock held.
+func (s *httpStream) removeExchange(ex *httpExchange) {
+	s.exchanges = slices.DeleteFunc(s.exchanges, func(e *httpExchange) bool {
+		return e == ex
+	})
+}
+
+// open registers a POST request waiting for the responses to the
+// requests with the given ids, to be sent after some already available.
+func (s *httpStream) open(keys []string, held []json.RawMessage, events bool) (*httpExchange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if _, ok := s.pending[key]; ok {
+			return nil, errDuplicateID
+		}
+	}
+
+	ex := &httpExchange{
+		sink:      newHTTPSink(0),
+		ready:     make(chan struct{}),
+		held:      held,
+		keys:      keys,
+		remaining: len(keys),
+		events:    events,
+	}
+	for _, key := range keys {
+		s.pending[key] = ex
+	}
+	s.exchanges = append(s.exchanges, ex)
+	return ex, nil
+}
+
+// end detaches a POST request once its HTTP response has ended. Unless
+// turned into an event stream, the responses not yet available are
+// dropped.
+func (s *httpStream) end(ex *httpExchange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ex.stream != 0 {
+		s.detachLocked(ex.sink)
+		return
+	}
+
+	for _, key := range ex.keys {
+		if s.pending[key] == ex {
+			delete(s.pending, key)
+		}
+	}
+	s.removeExchange(ex)
+	s.detachLocked(ex.sink)
+}
+
+// attach resumes an event stream, returning the events logged after seq
+// and a sink for those to follow. There is no sink if the stream has
+// ended. The stream is taken over from any HTTP response still sending
+// it, as the server may not have noticed the client is gone.
+func (s *httpStream) attach(stream, seq uint64) (*httpSink, []httpEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if old, ok := s.sinks[stream]; ok {
+		s.detachLocked(old)
+	}
+
+	events := s.replay(stream, seq)
+	if stream != listenerStream && !s.streaming(stream) {
+		return nil, events
+	}
+
+	k := newHTTPSink(stream)
+	s.sinks[stream] = k
+	if stream == listenerStream {
+		s.listening = true
+	}
+	return k, events
+}
+
+// replay returns the logged events of a stream after seq. Must be called
+// with the lock held.
+func (s *httpStream) replay(stream, seq uint64) []httpEvent {
+	var events []httpEvent
+	for _, ev := range s.log {
+		if ev.stream == stream && ev.seq > seq {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+// streaming tells if a POST event stream still waits for responses. Must
+// be called with the lock held.
+func (s *httpStream) streaming(stream uint64) bool {
+	return slices.ContainsFunc(s.exchanges, func(ex *httpExchange) bool {
+		return ex.stream == stream
+	})
+}
+
+// detach forgets the sink of an event stream once its HTTP response has
+// ended.
+func (s *httpStream) detach(k *httpSink) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.detachLocked(k)
+}
+
+// detachLocked is detach with the lock held.
+func (s *httpStream) detachLocked(k *httpSink) {
+	if s.sinks[k.stream] == k {
+		delete(s.sinks, k.stream)
+	}
+	if !k.detached {
+		k.detached = true
+		close(k.done)
+	}
+}
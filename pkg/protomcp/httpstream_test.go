@@ -0,0 +1,182 @@
+package protomcp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// testNotification is a notification sent by the server.
+const testNotification = `{"jsonrpc":"2.0","method":"notifications/message"}`
+
+// testResult returns the response to a request.
+func testResult(id string) string {
+	return `{"jsonrpc":"2.0","id":` + id + `,"result":{}}`
+}
+
+// assertRoute checks the sink an outbound message is routed to, and the
+// ids of the events passed to it.
+func assertRoute(t *testing.T, s *httpStream, raw string, want *httpSink, ids ...string) {
+	t.Helper()
+
+	s.mu.Lock()
+	k, events := s.route(json.RawMessage(raw))
+	s.mu.Unlock()
+
+	if k != want {
+		t.Errorf("%s routed to %p, want %p", raw, k, want)
+	}
+	assertEventIDs(t, events, ids...)
+}
+
+// assertEventIDs checks the ids of some events.
+func assertEventIDs(t *testing.T, events []httpEvent, ids ...string) {
+	t.Helper()
+
+	got := make([]string, 0, len(events))
+	for _, ev := range events {
+		got = append(got, ev.id())
+	}
+	if len(got) != len(ids) {
+		t.Errorf("got events %q, want %q", got, ids)
+		return
+	}
+	for i := range got {
+		if got[i] != ids[i] {
+			t.Errorf("got events %q, want %q", got, ids)
+			return
+		}
+	}
+}
+
+func TestHTTPStream(t *testing.T) {
+	t.Run("json", testHTTPStreamJSON)
+	t.Run("events", testHTTPStreamEvents)
+	t.Run("listener", testHTTPStreamListener)
+	t.Run("replay log", testHTTPStreamReplayLog)
+}
+
+func testHTTPStreamJSON(t *testing.T) {
+	s := newHTTPStream(DefaultReplayBufferSize)
+
+	quiet, err := s.open([]string{"1", "2"}, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.open([]string{"2"}, nil, true); err != errDuplicateID {
+		t.Errorf("got %v, want %v", err, errDuplicateID)
+	}
+
+	assertRoute(t, s, testNotification, nil)
+	assertRoute(t, s, testResult("1"), nil)
+	assertRoute(t, s, testResult("1"), nil)
+	assertRoute(t, s, testResult("2"), nil)
+
+	select {
+	case <-quiet.ready:
+		if len(quiet.held) != 2 {
+			t.Errorf("got %d responses, want 2", len(quiet.held))
+		}
+	default:
+		t.Error("responses not ready")
+	}
+}
+
+func testHTTPStreamEvents(t *testing.T) {
+	s := newHTTPStream(DefaultReplayBufferSize)
+
+	ex, err := s.open([]string{"1", "2"}, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertRoute(t, s, testResult("1"), nil)
+	assertRoute(t, s, testNotification, ex.sink, "1-1", "1-2")
+	s.end(ex)
+
+	assertRoute(t, s, testResult("2"), nil, "1-3")
+	k, events := s.attach(1, 1)
+	if k != nil {
+		t.Fatal("ended stream attached")
+	}
+	assertEventIDs(t, events, "1-2", "1-3")
+	if !events[1].end {
+		t.Error("last event doesn't end the stream")
+	}
+}
+
+func testHTTPStreamListener(t *testing.T) {
+	s := newHTTPStream(DefaultReplayBufferSize)
+	assertRoute(t, s, testNotification, nil)
+
+	old, events := s.attach(listenerStream, 0)
+	if old == nil || len(events) != 0 {
+		t.Fatalf("attach: %v %v", old, events)
+	}
+	k, _ := s.attach(listenerStream, 0)
+	select {
+	case <-old.done:
+	default:
+		t.Error("stream not taken over")
+	}
+
+	assertRoute(t, s, testNotification, k, "0-1")
+	s.detach(k)
+	s.detach(old)
+	assertRoute(t, s, testNotification, nil, "0-2")
+
+	k, events = s.attach(listenerStream, 1)
+	if k == nil {
+		t.Fatal("attach: no sink")
+	}
+	assertEventIDs(t, events, "0-2")
+}
+
+func testHTTPStreamReplayLog(t *testing.T) {
+	s := newHTTPStream(2)
+	s.attach(listenerStream, 0)
+
+	for range 3 {
+		s.mu.Lock()
+		s.route(json.RawMessage(testNotification))
+		s.mu.Unlock()
+	}
+	assertEventIDs(t, s.replay(listenerStream, 0), "0-2", "0-3")
+
+	s = newHTTPStream(-1)
+	s.mu.Lock()
+	s.logEvent(listenerStream, json.RawMessage(testNotification), false)
+	s.mu.Unlock()
+	if len(s.log) != 0 {
+		t.Error("events logged without replay log")
+	}
+}
+
+// parseEventIDTestCase represents a test case for parseEventID
+type parseEventIDTestCase struct {
+	id     string
+	stream uint64
+	seq    uint64
+	ok     bool
+}
+
+// test runs the parseEventID test case
+func (tc parseEventIDTestCase) test(t *testing.T) {
+	stream, seq, ok := parseEventID(tc.id)
+	if stream != tc.stream || seq != tc.seq || ok != tc.ok {
+		t.Errorf("parseEventID(%q) = %d, %d, %v", tc.id, stream, seq, ok)
+	}
+}
+
+func TestParseEventID(t *testing.T) {
+	tests := []parseEventIDTestCase{
+		{"0-1", 0, 1, true},
+		{"12-345", 12, 345, true},
+		{"12", 0, 0, false},
+		{"a-1", 0, 1, false},
+		{"1-", 1, 0, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.id, tc.test)
+	}
+}
@@ -0,0 +1,346 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// HandlerFunc handles a JSON-RPC request given its raw params, returning
+// the value to be sent back as result. Errors of type *Error are reported
+// with their own code, any other as CodeInternalError.
+type HandlerFunc func(ctx context.Context, params json.RawMessage) (any, error)
+
+// MethodHandler is the signature of the handlers generated for proto
+// methods. dec decodes the request params into the given message.
+type MethodHandler func(ctx context.Context, dec func(proto.Message) error) (proto.Message, error)
+
+// NewMethodHandler adapts a unary service method, typically one of a
+// generated `<Service>Server` interface, into a MethodHandler.
+func NewMethodHandler[Req any, PReq interface {
+	*Req
+	proto.Message
+}, Resp proto.Message](fn func(context.Context, PReq) (Resp, error)) MethodHandler {
+	return func(ctx context.Context, dec func(proto.Message) error) (proto.Message, error) {
+		req := PReq(new(Req))
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		return fn(ctx, req)
+	}
+}
+
+// DefaultMaxWorkers is the number of requests of a connection handled
+// concurrently when Dispatcher.MaxWorkers isn't set.
+const DefaultMaxWorkers = 16
+
+// ConnServer is implemented by the servers able to handle JSON-RPC
+// connections, regardless of the transport used to establish them.
+type ConnServer interface {
+	// ServeConn starts serving the messages received over stream,
+	// returning the connection.
+	ServeConn(ctx context.Context, stream jsonrpc2.ObjectStream, opts ...jsonrpc2.ConnOpt) *jsonrpc2.Conn
+}
+
+var (
+	_ jsonrpc2.Handler = (*Dispatcher)(nil)
+	_ ConnServer       = (*Dispatcher)(nil)
+)
+
+// Dispatcher routes JSON-RPC 2.0 requests to registered handlers by method
+// name. Generated code registers proto methods under their
+// `pkg.Service/Method` names.
+//
+// Requests, including those of a batch, are handled concurrently, while
+// notifications are handled in the order they arrive.
+//
+// The exported fields must not be changed once the Dispatcher is serving.
+type Dispatcher struct {
+	methods map[string]methodEntry
+
+	// MarshalOptions controls how proto results are encoded.
+	MarshalOptions protojson.MarshalOptions
+	// UnmarshalOptions controls how proto params are decoded.
+	UnmarshalOptions protojson.UnmarshalOptions
+
+	// MaxWorkers limits how many requests of a single connection are
+	// handled concurrently. Zero means DefaultMaxWorkers.
+	MaxWorkers int
+
+	mu sync.RWMutex
+}
+
+// NewDispatcher creates an empty Dispatcher.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		methods: make(map[string]methodEntry),
+	}
+}
+
+// methodEntry is a registered method.
+type methodEntry struct {
+	fn     HandlerFunc
+	notify bool // callable as notification
+}
+
+// HandleFunc registers the handler for a method, replacing any previous
+// one. Notifications to it are ignored.
+func (d *Dispatcher) HandleFunc(method string, fn HandlerFunc) {
+	d.setMethod(method, fn, false)
+}
+
+// HandleNotificationFunc registers the handler for a method that can also
+// be called as a notification, replacing any previous one.
+func (d *Dispatcher) HandleNotificationFunc(method string, fn HandlerFunc) {
+	d.setMethod(method, fn, true)
+}
+
+// setMethod registers a method.
+func (d *Dispatcher) setMethod(method string, fn HandlerFunc, notify bool) {
+	if fn == nil {
+		panic(fmt.Errorf("protomcp: nil handler for %q", method))
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.methods[method] = methodEntry{fn: fn, notify: notify}
+}
+
+// Register registers a proto method handler. Params are decoded, and
+// results encoded, using protojson. Notifications to it are ignored.
+func (d *Dispatcher) Register(method string, h MethodHandler) {
+	d.HandleFunc(method, d.protoHandler(method, h))
+}
+
+// RegisterNotification registers a proto method handler that can also be
+// called as a notification, typically one returning google.protobuf.Empty.
+func (d *Dispatcher) RegisterNotification(method string, h MethodHandler) {
+	d.HandleNotificationFunc(method, d.protoHandler(method, h))
+}
+
+// protoHandler wraps a MethodHandler as a HandlerFunc.
+func (d *Dispatcher) protoHandler(method string, h MethodHandler) HandlerFunc {
+	if h == nil {
+		panic(fmt.Errorf("protomcp: nil handler for %q", method))
+	}
+
+	return func(ctx context.Context, params json.RawMessage) (any, error) {
+		resp, err := h(ctx, d.decoder(params))
+		if err != nil {
+			return nil, err
+		}
+		return d.marshal(resp)
+	}
+}
+
+// marshal encodes a proto message using MarshalOptions.
+func (d *Dispatcher) marshal(m proto.Message) (json.RawMessage, error) {
+	data, err := d.MarshalOptions.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(data), nil
+}
+
+// decoder returns a function decoding params into a proto message. Absent
+// or null params leave the message untouched.
+func (d *Dispatcher) decoder(params json.RawMessage) func(proto.Message) error {
+	return func(m proto.Message) error {
+		if k := jsonKind(params); k == 0 || k == 'n' {
+			return nil
+		}
+		if err := d.UnmarshalOptions.Unmarshal(params, m); err != nil {
+			return NewError(CodeInvalidParams, "invalid params: %v", err)
+		}
+		return nil
+	}
+}
+
+// Methods returns the sorted names of all registered methods.
+func (d *Dispatcher) Methods() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	out := make([]string, 0, len(d.methods))
+	for name := range d.methods {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// lookup returns a registered method.
+func (d *Dispatcher) lookup(method string) (methodEntry, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	m, ok := d.methods[method]
+	return m, ok
+}
+
+// ServeConn starts serving JSON-RPC requests received over stream,
+// returning the connection. Malformed messages are answered with the
+// appropriate JSON-RPC errors. The connection is closed when ctx is
+// cancelled.
+func (d *Dispatcher) ServeConn(ctx context.Context, stream jsonrpc2.ObjectStream,
+	opts ...jsonrpc2.ConnOpt) *jsonrpc2.Conn {
+	return d.serveWith(ctx, stream, d, opts)
+}
+
+// serveWith serves stream using h on top of the dispatcher's worker pool.
+func (d *Dispatcher) serveWith(ctx context.Context, stream jsonrpc2.ObjectStream,
+	h jsonrpc2.Handler, opts []jsonrpc2.ConnOpt) *jsonrpc2.Conn {
+	ms := newMessageStream(stream)
+	pool := &workerPool{
+		h:      h,
+		stream: ms,
+		sem:    make(chan struct{}, d.maxWorkers()),
+	}
+	return jsonrpc2.NewConn(ctx, ms, pool, opts...)
+}
+
+// maxWorkers returns the effective MaxWorkers.
+func (d *Dispatcher) maxWorkers() int {
+	if d.MaxWorkers > 0 {
+		return d.MaxWorkers
+	}
+	return DefaultMaxWorkers
+}
+
+// Handle implements jsonrpc2.Handler, handling a request synchronously.
+// Notifications are only delivered to methods registered as accepting them,
+// and never replied to. The handlers find a Notifier for conn in their
+// context.
+func (d *Dispatcher) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	ctx = WithNotifier(ctx, d.Notifier(conn))
+	if req.Notif {
+		d.notify(ctx, req)
+		return
+	}
+
+	result, err := d.call(ctx, req)
+
+	if err != nil {
+		_ = conn.ReplyWithError(ctx, req.ID, toJSONRPCError(err))
+		return
+	}
+
+	if err := conn.Reply(ctx, req.ID, result); err != nil && !errors.Is(err, jsonrpc2.ErrClosed) {
+		_ = conn.ReplyWithError(ctx, req.ID, toJSONRPCError(err))
+	}
+}
+
+// notify invokes the handler of a notification, if the method accepts
+// them. Results and errors are discarded.
+func (d *Dispatcher) notify(ctx context.Context, req *jsonrpc2.Request) {
+	if m, ok := d.lookup(req.Method); ok && m.notify {
+		_, _ = invoke(ctx, m.fn, req)
+	}
+}
+
+// call invokes the handler of a request.
+func (d *Dispatcher) call(ctx context.Context, req *jsonrpc2.Request) (any, error) {
+	m, ok := d.lookup(req.Method)
+	if !ok {
+		return nil, NewMethodNotFoundError(req.Method)
+	}
+	return invoke(ctx, m.fn, req)
+}
+
+// invoke calls a handler with the params of a request, recovering from
+// panics.
+func invoke(ctx context.Context, fn HandlerFunc, req *jsonrpc2.Request) (result any, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			result, err = nil, NewError(CodeInternalError, "panic: %v", e)
+		}
+	}()
+
+	var params json.RawMessage
+	if req.Params != nil {
+		params = *req.Params
+	}
+	return fn(ctx, params)
+}
+
+// toJSONRPCError converts an error into its JSON-RPC representation.
+func toJSONRPCError(err error) *jsonrpc2.Error {
+	var re *jsonrpc2.Error
+	if errors.As(err, &re) {
+		return re
+	}
+
+	var e *Error
+	if !errors.As(err, &e) {
+		return &jsonrpc2.Error{
+			Code:    int64(CodeInternalError),
+			Message: err.Error(),
+		}
+	}
+
+	out := &jsonrpc2.Error{
+		Code:    int64(e.Code),
+		Message: e.Message,
+	}
+	if e.Data != nil {
+		if data, err := json.Marshal(e.Data); err == nil {
+			out.Data = (*json.RawMessage)(&data)
+		}
+	}
+	return out
+}
+
+// workerPool is a jsonrpc2.Handler running requests on their own
+// goroutines, no more than cap(sem) at once. Notifications are handled
+// synchronously to preserve their order.
+type workerPool struct {
+	h      jsonrpc2.Handler
+	stream *messageStream // to find the original ids of batched requests
+	sem    chan struct{}
+}
+
+// Handle implements jsonrpc2.Handler. It never blocks on requests, so the
+// connection can keep reading responses to calls made by the handlers.
+// Requests can be cancelled by the client as soon as they are read, and
+// those cancelled while waiting for a worker are answered with the cause.
+func (p *workerPool) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	if req.Notif {
+		p.h.Handle(ctx, conn, req)
+		return
+	}
+
+	ctx, done := withCancel(ctx, p.stream.clientID(req.ID))
+	go func() {
+		defer done()
+
+		if !p.acquire(ctx) {
+			_ = conn.ReplyWithError(ctx, req.ID, toJSONRPCError(context.Cause(ctx)))
+			return
+		}
+		defer func() { <-p.sem }()
+		p.h.Handle(ctx, conn, req)
+	}()
+}
+
+// acquire waits for a worker to be available, failing if ctx is done
+// first.
+func (p *workerPool) acquire(ctx context.Context) bool {
+	select {
+	case p.sem <- struct{}{}:
+		if ctx.Err() != nil {
+			<-p.sem
+			return false
+		}
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
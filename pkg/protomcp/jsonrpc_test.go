@@ -0,0 +1,381 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// testTimeout bounds every blocking read in tests.
+const testTimeout = 5 * time.Second
+
+// testPeer is the raw client end of an in-memory connection to a server.
+type testPeer struct {
+	conn net.Conn
+	dec  *json.Decoder
+	out  chan string
+}
+
+// newTestPeer serves d over an in-memory pipe and returns the client end.
+func newTestPeer(t *testing.T, d ConnServer) *testPeer {
+	t.Helper()
+
+	p, _ := newTestConn(t, d)
+	return p
+}
+
+// newTestConn serves d over an in-memory pipe and returns both ends.
+func newTestConn(t *testing.T, d ConnServer) (*testPeer, *jsonrpc2.Conn) {
+	t.Helper()
+
+	server, client := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	conn := d.ServeConn(ctx, jsonrpc2.NewBufferedStream(server, jsonrpc2.PlainObjectCodec{}))
+
+	p := &testPeer{
+		conn: client,
+		dec:  json.NewDecoder(client),
+		out:  make(chan string, 16),
+	}
+	go p.writeLoop()
+
+	t.Cleanup(func() {
+		cancel()
+		close(p.out)
+		_ = conn.Close()
+		_ = client.Close()
+	})
+
+	return p, conn
+}
+
+// writeLoop writes queued messages. Writes on a pipe block until the
+// server reads them, and the server won't read while blocked on its own
+// replies, so they can't happen on the test goroutine.
+func (p *testPeer) writeLoop() {
+	for msg := range p.out {
+		if _, err := io.WriteString(p.conn, msg+"\n"); err != nil {
+			// recv will time out
+			return
+		}
+	}
+}
+
+// send queues a raw message.
+func (p *testPeer) send(t *testing.T, msg string) {
+	t.Helper()
+
+	select {
+	case p.out <- msg:
+	case <-time.After(testTimeout):
+		t.Fatal("send: timeout")
+	}
+}
+
+// recv reads the next raw message.
+func (p *testPeer) recv(t *testing.T) json.RawMessage {
+	t.Helper()
+
+	var raw json.RawMessage
+	_ = p.conn.SetReadDeadline(time.Now().Add(testTimeout))
+	if err := p.dec.Decode(&raw); err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	return raw
+}
+
+// recvResponse reads the next message as a response.
+func (p *testPeer) recvResponse(t *testing.T) *testResponse {
+	t.Helper()
+
+	var resp testResponse
+	if err := json.Unmarshal(p.recv(t), &resp); err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	return &resp
+}
+
+// call sends a request and returns its response.
+func (p *testPeer) call(t *testing.T, msg string) *testResponse {
+	t.Helper()
+
+	p.send(t, msg)
+	return p.recvResponse(t)
+}
+
+// testResponse is a decoded JSON-RPC response.
+type testResponse struct {
+	Error   *jsonrpc2.Error `json:"error"`
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// assertResult checks a response succeeded with the given result.
+func (r *testResponse) assertResult(t *testing.T, id, result string) {
+	t.Helper()
+
+	if r.Error != nil {
+		t.Fatalf("unexpected error: %v", r.Error)
+	}
+	if string(r.ID) != id {
+		t.Errorf("id = %s, want %s", r.ID, id)
+	}
+	if string(r.Result) != result {
+		t.Errorf("result = %s, want %s", r.Result, result)
+	}
+	if r.JSONRPC != "2.0" {
+		t.Errorf("jsonrpc = %q, want %q", r.JSONRPC, "2.0")
+	}
+}
+
+// assertError checks a response failed with the given code.
+func (r *testResponse) assertError(t *testing.T, id string, code ErrorCode) {
+	t.Helper()
+
+	if r.Error == nil {
+		t.Fatalf("expected error %d, got result %s", code, r.Result)
+	}
+	if r.Error.Code != int64(code) {
+		t.Errorf("error code = %d, want %d (%s)", r.Error.Code, code, r.Error.Message)
+	}
+	if string(r.ID) != id {
+		t.Errorf("id = %s, want %s", r.ID, id)
+	}
+}
+
+// newTestDispatcher returns a Dispatcher with a few test methods.
+func newTestDispatcher() *Dispatcher {
+	d := NewDispatcher()
+	d.Register("test.Echo/Echo", NewMethodHandler(
+		func(_ context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+			return req, nil
+		}))
+	d.Register("test.Echo/Upper", NewMethodHandler(
+		func(_ context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+			return wrapperspb.String(strings.ToUpper(req.GetValue())), nil
+		}))
+	d.Register("test.Echo/Fail", NewMethodHandler(
+		func(context.Context, *structpb.Struct) (*structpb.Struct, error) {
+			return nil, errors.New("boom")
+		}))
+	d.Register("test.Echo/Denied", NewMethodHandler(
+		func(context.Context, *structpb.Struct) (*structpb.Struct, error) {
+			err := NewError(-32001, "denied")
+			err.Data = map[string]string{"reason": "test"}
+			return nil, err
+		}))
+	d.Register("test.Echo/Panic", NewMethodHandler(
+		func(context.Context, *structpb.Struct) (*structpb.Struct, error) {
+			panic("oops")
+		}))
+	return d
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Run("proto method", testDispatcherProtoMethod)
+	t.Run("wrapper message", testDispatcherWrapper)
+	t.Run("absent params", testDispatcherAbsentParams)
+	t.Run("string id", testDispatcherStringID)
+	t.Run("method not found", testDispatcherMethodNotFound)
+	t.Run("invalid params", testDispatcherInvalidParams)
+	t.Run("internal error", testDispatcherInternalError)
+	t.Run("custom error", testDispatcherCustomError)
+	t.Run("panic", testDispatcherPanic)
+	t.Run("notification", testDispatcherNotification)
+	t.Run("handle func", testDispatcherHandleFunc)
+}
+
+func testDispatcherProtoMethod(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"test.Echo/Echo","params":{"a":"b"}}`)
+	resp.assertResult(t, "1", `{"a":"b"}`)
+}
+
+func testDispatcherWrapper(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":2,"method":"test.Echo/Upper","params":"hello"}`)
+	resp.assertError(t, "2", CodeInvalidRequest)
+
+	resp = p.call(t, `{"jsonrpc":"2.0","id":3,"method":"test.Echo/Upper","params":["hello"]}`)
+	resp.assertError(t, "3", CodeInvalidParams)
+}
+
+func testDispatcherAbsentParams(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":4,"method":"test.Echo/Echo"}`)
+	resp.assertResult(t, "4", `{}`)
+}
+
+func testDispatcherStringID(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":"abc","method":"test.Echo/Echo","params":{}}`)
+	resp.assertResult(t, `"abc"`, `{}`)
+}
+
+func testDispatcherMethodNotFound(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":5,"method":"test.Echo/Nope"}`)
+	resp.assertError(t, "5", CodeMethodNotFound)
+}
+
+func testDispatcherInvalidParams(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":6,"method":"test.Echo/Upper","params":{"bogus":1}}`)
+	resp.assertError(t, "6", CodeInvalidParams)
+}
+
+func testDispatcherInternalError(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":7,"method":"test.Echo/Fail"}`)
+	resp.assertError(t, "7", CodeInternalError)
+	if resp.Error.Message != "boom" {
+		t.Errorf("message = %q, want %q", resp.Error.Message, "boom")
+	}
+}
+
+func testDispatcherCustomError(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":8,"method":"test.Echo/Denied"}`)
+	resp.assertError(t, "8", -32001)
+	if resp.Error.Data == nil || string(*resp.Error.Data) != `{"reason":"test"}` {
+		t.Errorf("data = %v, want %s", resp.Error.Data, `{"reason":"test"}`)
+	}
+}
+
+func testDispatcherPanic(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":9,"method":"test.Echo/Panic"}`)
+	resp.assertError(t, "9", CodeInternalError)
+}
+
+func testDispatcherNotification(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	p.send(t, `{"jsonrpc":"2.0","method":"test.Echo/Echo","params":{}}`)
+	p.send(t, `{"jsonrpc":"2.0","method":"test.Echo/Nope"}`)
+
+	// the first reply must be the one of the request
+	resp := p.call(t, `{"jsonrpc":"2.0","id":10,"method":"test.Echo/Echo","params":{}}`)
+	resp.assertResult(t, "10", `{}`)
+}
+
+func testDispatcherHandleFunc(t *testing.T) {
+	d := NewDispatcher()
+	d.HandleFunc("sum", func(_ context.Context, params json.RawMessage) (any, error) {
+		var in []int
+		if err := json.Unmarshal(params, &in); err != nil {
+			return nil, NewError(CodeInvalidParams, "%v", err)
+		}
+		total := 0
+		for _, v := range in {
+			total += v
+		}
+		return total, nil
+	})
+
+	p := newTestPeer(t, d)
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"sum","params":[1,2,3]}`)
+	resp.assertResult(t, "1", "6")
+}
+
+func TestDispatcherInvalidMessages(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		id   string
+		code ErrorCode
+	}{
+		{"not an object", `1`, "null", CodeInvalidRequest},
+		{"method not a string", `{"jsonrpc":"2.0","method":1,"params":"bar"}`, "null", CodeInvalidRequest},
+		{"missing version", `{"id":1,"method":"test.Echo/Echo"}`, "1", CodeInvalidRequest},
+		{"wrong version", `{"jsonrpc":"1.0","id":1,"method":"test.Echo/Echo"}`, "1", CodeInvalidRequest},
+		{"fractional id", `{"jsonrpc":"2.0","id":1.5,"method":"test.Echo/Echo"}`, "null", CodeInvalidRequest},
+		{"negative id", `{"jsonrpc":"2.0","id":-1,"method":"test.Echo/Echo"}`, "null", CodeInvalidRequest},
+		{"object id", `{"jsonrpc":"2.0","id":{},"method":"test.Echo/Echo"}`, "null", CodeInvalidRequest},
+		{"no method", `{"jsonrpc":"2.0","id":1}`, "1", CodeInvalidRequest},
+		{"result and error", `{"jsonrpc":"2.0","id":1,"result":1,"error":{}}`, "1", CodeInvalidRequest},
+		{"request with result", `{"jsonrpc":"2.0","id":1,"method":"a","result":1}`, "1", CodeInvalidRequest},
+	}
+
+	p := newTestPeer(t, newTestDispatcher())
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := p.call(t, tc.msg)
+			resp.assertError(t, tc.id, tc.code)
+		})
+	}
+
+	// the connection survives invalid messages
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"test.Echo/Echo","params":{}}`)
+	resp.assertResult(t, "1", `{}`)
+}
+
+func TestDispatcherParseError(t *testing.T) {
+	p := newTestPeer(t, newTestDispatcher())
+
+	resp := p.call(t, `{"jsonrpc": "2.0", "method": "foobar, "params": "bar", "baz]`)
+	resp.assertError(t, "null", CodeParseError)
+}
+
+func TestDispatcherMethods(t *testing.T) {
+	got := newTestDispatcher().Methods()
+	want := []string{
+		"test.Echo/Denied",
+		"test.Echo/Echo",
+		"test.Echo/Fail",
+		"test.Echo/Panic",
+		"test.Echo/Upper",
+	}
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Methods() = %v, want %v", got, want)
+	}
+}
+
+func TestToJSONRPCError(t *testing.T) {
+	t.Run("jsonrpc2 error", testToJSONRPCErrorPassThrough)
+	t.Run("plain error", testToJSONRPCErrorPlain)
+	t.Run("unmarshallable data", testToJSONRPCErrorBadData)
+}
+
+func testToJSONRPCErrorPassThrough(t *testing.T) {
+	in := &jsonrpc2.Error{Code: 1, Message: "x"}
+	if got := toJSONRPCError(in); got != in {
+		t.Errorf("toJSONRPCError() = %v, want %v", got, in)
+	}
+}
+
+func testToJSONRPCErrorPlain(t *testing.T) {
+	got := toJSONRPCError(errors.New("x"))
+	if got.Code != int64(CodeInternalError) || got.Message != "x" {
+		t.Errorf("toJSONRPCError() = %v", got)
+	}
+}
+
+func testToJSONRPCErrorBadData(t *testing.T) {
+	in := NewError(CodeInvalidParams, "x")
+	in.Data = func() {}
+	got := toJSONRPCError(in)
+	if got.Code != int64(CodeInvalidParams) || got.Data != nil {
+		t.Errorf("toJSONRPCError() = %v", got)
+	}
+}
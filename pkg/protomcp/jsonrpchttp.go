@@ -0,0 +1,57 @@
+package protomcp
+
+import "net/http"
+
+var _ http.Handler = (*JSONRPCHandler)(nil)
+
+// JSONRPCHandler serves JSON-RPC 2.0 over HTTP, a request or batch per
+// POST request, replying with the responses as JSON.
+//
+// Each POST request is served on a connection of its own, so handlers
+// can't make requests to the client, and their notifications are
+// dropped. Use a StreamableHTTPHandler for sessions.
+//
+// The exported fields must not be changed once the handler is serving.
+type JSONRPCHandler struct {
+	server ConnServer
+
+	// MaxBodySize is the maximum size of the body of POST requests.
+	// Zero means DefaultMaxBodySize.
+	MaxBodySize int64
+}
+
+// NewJSONRPCHandler returns a JSONRPCHandler serving server, usually a
+// Dispatcher.
+func NewJSONRPCHandler(server ConnServer) *JSONRPCHandler {
+	return &JSONRPCHandler{server: server}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *JSONRPCHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, ok := readBody(w, r, h.MaxBodySize)
+	if !ok {
+		return
+	}
+
+	p, e := parsePost(body)
+	if e != nil {
+		writeJSON(w, http.StatusBadRequest, e.response())
+		return
+	}
+
+	stream := newHTTPStream(-1)
+	conn := h.server.ServeConn(r.Context(), stream)
+	defer func() { _ = conn.Close() }()
+
+	if len(p.keys) == 0 {
+		p.serveNotifications(w, r, stream)
+	} else {
+		p.serveRequests(w, r, stream)
+	}
+}
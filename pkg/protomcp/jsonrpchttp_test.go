@@ -0,0 +1,64 @@
+package protomcp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// jsonrpcHTTPTestCase represents a test case for JSONRPCHandler
+type jsonrpcHTTPTestCase struct {
+	name   string
+	method string
+	body   string
+	want   string
+	status int
+}
+
+// test runs the JSONRPCHandler test case
+func (tc jsonrpcHTTPTestCase) test(t *testing.T) {
+	ts := httptest.NewServer(NewJSONRPCHandler(newTestDispatcher()))
+	defer ts.Close()
+
+	req, err := http.NewRequest(tc.method, ts.URL, strings.NewReader(tc.body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(body)); resp.StatusCode != tc.status || got != tc.want {
+		t.Errorf("got %s %s, want %d %s", resp.Status, got, tc.status, tc.want)
+	}
+}
+
+func TestJSONRPCHandler(t *testing.T) {
+	const echo = `{"jsonrpc":"2.0","id":1,"method":"test.Echo/Echo","params":{"a":"b"}}`
+	const result = `{"id":1,"result":{"a":"b"},"jsonrpc":"2.0"}`
+
+	tests := []jsonrpcHTTPTestCase{
+		{"request", http.MethodPost, echo, result, http.StatusOK},
+		{"batch", http.MethodPost, `[` + echo + `,{"jsonrpc":"2.0","method":"test.Echo/Echo"}]`,
+			`[` + result + `]`, http.StatusOK},
+		{"error", http.MethodPost, `{"jsonrpc":"2.0","id":2,"method":"test.Echo/Missing"}`,
+			`{"id":2,"error":{"code":-32601,"message":"method not found: test.Echo/Missing"},"jsonrpc":"2.0"}`,
+			http.StatusOK},
+		{"notification", http.MethodPost, `{"jsonrpc":"2.0","method":"test.Echo/Echo"}`, "", http.StatusAccepted},
+		{"parse error", http.MethodPost, `{"jsonrpc":`,
+			`{"id":null,"error":{"code":-32700,"message":"parse error"},"jsonrpc":"2.0"}`, http.StatusBadRequest},
+		{"method", http.MethodGet, "", "method not allowed", http.StatusMethodNotAllowed},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
@@ -0,0 +1,165 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// MCP lifecycle methods.
+const (
+	MethodInitialize  = "initialize"
+	MethodInitialized = "notifications/initialized"
+	MethodPing        = "ping"
+)
+
+// Implementation describes an MCP client or server.
+type Implementation struct {
+	Name    string `json:"name"`
+	Title   string `json:"title,omitempty"`
+	Version string `json:"version"`
+}
+
+// ClientCapabilities are the optional features a client supports.
+type ClientCapabilities struct {
+	Experimental map[string]any         `json:"experimental,omitempty"`
+	Roots        *RootsCapability       `json:"roots,omitempty"`
+	Sampling     *SamplingCapability    `json:"sampling,omitempty"`
+	Elicitation  *ElicitationCapability `json:"elicitation,omitempty"`
+}
+
+// RootsCapability advertises the client exposes filesystem roots.
+type RootsCapability struct {
+	// ListChanged tells if the client notifies changes to the list.
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// SamplingCapability advertises the client can sample LLMs.
+type SamplingCapability struct{}
+
+// ElicitationCapability advertises the client can ask the user for
+// information.
+type ElicitationCapability struct{}
+
+// ServerCapabilities are the optional features a server supports.
+type ServerCapabilities struct {
+	Experimental map[string]any         `json:"experimental,omitempty"`
+	Tools        *ToolsCapability       `json:"tools,omitempty"`
+	Resources    *ResourcesCapability   `json:"resources,omitempty"`
+	Prompts      *PromptsCapability     `json:"prompts,omitempty"`
+	Logging      *LoggingCapability     `json:"logging,omitempty"`
+	Completions  *CompletionsCapability `json:"completions,omitempty"`
+}
+
+// ToolsCapability advertises the server offers tools.
+type ToolsCapability struct {
+	// ListChanged tells if the server notifies changes to the list.
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// ResourcesCapability advertises the server offers resources.
+type ResourcesCapability struct {
+	// Subscribe tells if clients can subscribe to resource updates.
+	Subscribe bool `json:"subscribe,omitempty"`
+	// ListChanged tells if the server notifies changes to the list.
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// PromptsCapability advertises the server offers prompt templates.
+type PromptsCapability struct {
+	// ListChanged tells if the server notifies changes to the list.
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// LoggingCapability advertises the server sends log messages.
+type LoggingCapability struct{}
+
+// CompletionsCapability advertises the server offers argument
+// completion.
+type CompletionsCapability struct{}
+
+// InitializeParams are the params of the initialize request.
+type InitializeParams struct {
+	Capabilities    ClientCapabilities `json:"capabilities"`
+	ClientInfo      Implementation     `json:"clientInfo"`
+	ProtocolVersion string             `json:"protocolVersion"`
+}
+
+// InitializeResult is the result of the initialize request.
+type InitializeResult struct {
+	Capabilities    ServerCapabilities `json:"capabilities"`
+	ServerInfo      Implementation     `json:"serverInfo"`
+	ProtocolVersion string             `json:"protocolVersion"`
+	Instructions    string             `json:"instructions,omitempty"`
+}
+
+// registerLifecycle registers the lifecycle methods on the dispatcher.
+func (s *MCPServer) registerLifecycle() {
+	s.d.HandleFunc(MethodInitialize, s.handleInitialize)
+	s.d.HandleNotificationFunc(MethodInitialized, s.handleInitialized)
+	s.d.HandleFunc(MethodPing, handlePing)
+	s.d.HandleNotificationFunc(MethodCancelled, handleCancelled)
+	s.d.HandleNotificationFunc(MethodRootsListChanged, handleRootsListChanged)
+}
+
+// handleInitialize negotiates the protocol version and exchanges
+// capabilities.
+func (s *MCPServer) handleInitialize(ctx context.Context, params json.RawMessage) (any, error) {
+	sess, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, NewError(CodeInternalError, "no session")
+	}
+
+	var p InitializeParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.ProtocolVersion == "" {
+		return nil, NewError(CodeInvalidParams, "invalid params: missing protocolVersion")
+	}
+
+	version := NegotiateProtocolVersion(p.ProtocolVersion, s.protocolVersions())
+	if err := sess.initialize(version, &p); err != nil {
+		return nil, err
+	}
+
+	return &InitializeResult{
+		ProtocolVersion: version,
+		Capabilities:    compatibleCapabilities(s.Capabilities(), version),
+		ServerInfo:      s.info,
+		Instructions:    s.Instructions,
+	}, nil
+}
+
+// compatibleCapabilities returns the capabilities as understood by a
+// protocol revision.
+func compatibleCapabilities(caps ServerCapabilities, version string) ServerCapabilities {
+	if version < ProtocolVersion20250326 {
+		caps.Completions = nil
+	}
+	return caps
+}
+
+// handleInitialized completes the initialisation handshake.
+func (*MCPServer) handleInitialized(ctx context.Context, _ json.RawMessage) (any, error) {
+	if sess, ok := SessionFromContext(ctx); ok {
+		sess.ready()
+	}
+	return nil, nil
+}
+
+// handlePing answers liveness checks.
+func handlePing(context.Context, json.RawMessage) (any, error) {
+	return struct{}{}, nil
+}
+
+// unmarshalParams decodes the params of an MCP request, reporting
+// CodeInvalidParams on failure.
+func unmarshalParams(params json.RawMessage, v any) error {
+	if k := jsonKind(params); k == 0 || k == 'n' {
+		return NewError(CodeInvalidParams, "invalid params: missing")
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return NewError(CodeInvalidParams, "invalid params: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+)
+
+var _ slog.Handler = (*LogHandler)(nil)
+
+// MCP logging method and notification.
+const (
+	MethodLoggingSetLevel = "logging/setLevel"
+	MethodMessage         = "notifications/message"
+)
+
+// LoggingLevel is the severity of a log message, as defined by RFC 5424.
+type LoggingLevel string
+
+// Logging levels, from the least to the most severe.
+const (
+	LoggingLevelDebug     LoggingLevel = "debug"
+	LoggingLevelInfo      LoggingLevel = "info"
+	LoggingLevelNotice    LoggingLevel = "notice"
+	LoggingLevelWarning   LoggingLevel = "warning"
+	LoggingLevelError     LoggingLevel = "error"
+	LoggingLevelCritical  LoggingLevel = "critical"
+	LoggingLevelAlert     LoggingLevel = "alert"
+	LoggingLevelEmergency LoggingLevel = "emergency"
+)
+
+// loggingLevels are the valid logging levels in order of severity.
+var loggingLevels = []LoggingLevel{
+	LoggingLevelDebug,
+	LoggingLevelInfo,
+	LoggingLevelNotice,
+	LoggingLevelWarning,
+	LoggingLevelError,
+	LoggingLevelCritical,
+	LoggingLevelAlert,
+	LoggingLevelEmergency,
+}
+
+// severity returns the position of the level in order of severity, or -1
+// if invalid.
+func (l LoggingLevel) severity() int {
+	return slices.Index(loggingLevels, l)
+}
+
+// SetLevelParams are the params of logging/setLevel.
+type SetLevelParams struct {
+	Level LoggingLevel `json:"level"`
+}
+
+// LoggingMessageParams are the params of notifications/message.
+type LoggingMessageParams struct {
+	// Data is the JSON encodable content of the message.
+	Data any `json:"data"`
+	// Level is the severity of the message.
+	Level LoggingLevel `json:"level"`
+	// Logger optionally names the source of the message.
+	Logger string `json:"logger,omitempty"`
+}
+
+// EnableLogging registers logging/setLevel and advertises the logging
+// capability. Sessions are only sent log messages once their client sets
+// a level.
+func (s *MCPServer) EnableLogging() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.caps.Logging == nil {
+		s.caps.Logging = &LoggingCapability{}
+		s.d.HandleFunc(MethodLoggingSetLevel, handleSetLevel)
+	}
+}
+
+// handleSetLevel implements logging/setLevel.
+func handleSetLevel(ctx context.Context, params json.RawMessage) (any, error) {
+	var p SetLevelParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.Level.severity() < 0 {
+		return nil, NewError(CodeInvalidParams, "invalid level: %s", p.Level)
+	}
+
+	sess, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, NewError(CodeInternalError, "no session")
+	}
+	sess.setLogLevel(p.Level)
+	return struct{}{}, nil
+}
+
+// Log sends a log message to the client if its level is at or above the
+// one the client set. data must be JSON encodable.
+func (s *Session) Log(ctx context.Context, level LoggingLevel, logger string, data any) error {
+	conn := s.Conn()
+	if conn == nil || !s.LogEnabled(level) {
+		return nil
+	}
+
+	return conn.Notify(ctx, MethodMessage, &LoggingMessageParams{
+		Level:  level,
+		Logger: logger,
+		Data:   data,
+	})
+}
+
+// LogEnabled tells if the client wants log messages of a level.
+func (s *Session) LogEnabled(level LoggingLevel) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.logLevel != "" && level.severity() >= s.logLevel.severity()
+}
+
+// setLogLevel records the minimum level of the log messages sent to the
+// client.
+func (s *Session) setLogLevel(level LoggingLevel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logLevel = level
+}
+
+// LogHandler is a log/slog Handler forwarding the records logged with the
+// context of an MCP request to its client, as notifications/message, and
+// passing all of them to another Handler.
+type LogHandler struct {
+	next   slog.Handler
+	logger string
+	group  string
+	attrs  []slog.Attr
+}
+
+// NewLogHandler returns a LogHandler for s, enabling its logging. Records
+// are forwarded to clients under the given logger name, and passed to
+// next unless nil.
+func (s *MCPServer) NewLogHandler(logger string, next slog.Handler) *LogHandler {
+	s.EnableLogging()
+	return &LogHandler{next: next, logger: logger}
+}
+
+// Enabled implements slog.Handler.
+func (h *LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if sess, ok := SessionFromContext(ctx); ok && sess.LogEnabled(LoggingLevelOf(level)) {
+		return true
+	}
+	return h.next != nil && h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler. Errors sending the record to the client
+// are ignored.
+func (h *LogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if sess, ok := SessionFromContext(ctx); ok {
+		_ = sess.Log(ctx, LoggingLevelOf(r.Level), h.logger, h.data(r))
+	}
+
+	if h.next != nil && h.next.Enabled(ctx, r.Level) {
+		return h.next.Handle(ctx, r)
+	}
+	return nil
+}
+
+// data returns the content of a record sent to clients, an object with
+// its message and attributes.
+func (h *LogHandler) data(r slog.Record) map[string]any {
+	out := make(map[string]any, len(h.attrs)+r.NumAttrs()+1)
+	out[slog.MessageKey] = r.Message
+	for _, a := range h.attrs {
+		addAttr(out, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(out, h.group, a)
+		return true
+	})
+	return out
+}
+
+// addAttr adds an attribute to the content of a record, prefixing its key
+// with its group, if any. Errors are added as their text.
+func addAttr(out map[string]any, group string, a slog.Attr) {
+	v := a.Value.Resolve()
+	switch x := v.Any().(type) {
+	case []slog.Attr:
+		for _, ga := range x {
+			addAttr(out, joinGroup(group, a.Key), ga)
+		}
+	case error:
+		out[joinGroup(group, a.Key)] = x.Error()
+	default:
+		if a.Key != "" {
+			out[joinGroup(group, a.Key)] = x
+		}
+	}
+}
+
+// joinGroup returns a key qualified by its group.
+func joinGroup(group, key string) string {
+	switch {
+	case group == "":
+		return key
+	case key == "":
+		return group
+	default:
+		return group + "." + key
+	}
+}
+
+// WithAttrs implements slog.Handler.
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	if h.next != nil {
+		c.next = h.next.WithAttrs(attrs)
+	}
+
+	c.attrs = slices.Clone(h.attrs)
+	for _, a := range attrs {
+		c.attrs = append(c.attrs, slog.Attr{Key: joinGroup(h.group, a.Key), Value: a.Value})
+	}
+	return &c
+}
+
+// WithGroup implements slog.Handler.
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	c := *h
+	if h.next != nil {
+		c.next = h.next.WithGroup(name)
+	}
+	c.group = joinGroup(h.group, name)
+	return &c
+}
+
+// LoggingLevelOf returns the LoggingLevel of a log/slog level.
+func LoggingLevelOf(level slog.Level) LoggingLevel {
+	switch {
+	case level >= slog.LevelError+4:
+		return LoggingLevelCritical
+	case level >= slog.LevelError:
+		return LoggingLevelError
+	case level >= slog.LevelWarn:
+		return LoggingLevelWarning
+	case level > slog.LevelInfo:
+		return LoggingLevelNotice
+	case level == slog.LevelInfo:
+		return LoggingLevelInfo
+	default:
+		return LoggingLevelDebug
+	}
+}
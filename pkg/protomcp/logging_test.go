@@ -0,0 +1,129 @@
+package protomcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// newTestLoggingServer returns an initialised MCP peer with a tool
+// logging through a LogHandler, and the output of the next handler.
+func newTestLoggingServer(t *testing.T) (*MCPServer, *testPeer, *bytes.Buffer) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	s := newTestMCPServer()
+	logger := slog.New(s.NewLogHandler("tools", slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	})))
+
+	s.AddTool(&Tool{
+		Name:        "work",
+		InputSchema: json.RawMessage(`{"type":"object"}`),
+	}, NewMethodHandler(func(ctx context.Context, req *emptypb.Empty) (*emptypb.Empty, error) {
+		logger.DebugContext(ctx, "starting")
+		logger.With("req", 7).WithGroup("op").InfoContext(ctx, "working", "n", 1, slog.Group("g", "a", "b"))
+		logger.ErrorContext(ctx, "failed", "err", errors.New("boom"))
+		return req, nil
+	}))
+
+	p := newTestPeer(t, s)
+	p.initialize(t)
+	return s, p, &buf
+}
+
+// workRequest is the tools/call request of the work tool.
+const workRequest = `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"work"}}`
+
+// workResult is the result of the work tool.
+const workResult = `{"structuredContent":{},"content":[{"type":"text","text":"{}"}]}`
+
+func TestMCPServerLogging(t *testing.T) {
+	t.Run("level set", testMCPServerLoggingLevelSet)
+	t.Run("level unset", testMCPServerLoggingLevelUnset)
+	t.Run("invalid level", testMCPServerLoggingInvalidLevel)
+}
+
+func testMCPServerLoggingLevelSet(t *testing.T) {
+	s, p, buf := newTestLoggingServer(t)
+
+	if caps := s.Capabilities(); caps.Logging == nil {
+		t.Error("logging capability not advertised")
+	}
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"logging/setLevel","params":{"level":"info"}}`)
+	resp.assertResult(t, "1", `{}`)
+
+	p.send(t, workRequest)
+	assertNotification(t, p.recv(t), MethodMessage, `{"data":{"msg":"working","op.g.a":"b","op.n":1,"req":7},`+
+		`"level":"info","logger":"tools"}`)
+	assertNotification(t, p.recv(t), MethodMessage, `{"data":{"err":"boom","msg":"failed"},`+
+		`"level":"error","logger":"tools"}`)
+	p.recvResponse(t).assertResult(t, "2", workResult)
+
+	if out := buf.String(); !strings.Contains(out, "msg=starting") || !strings.Contains(out, "msg=failed") {
+		t.Errorf("next handler output = %q", out)
+	}
+}
+
+func testMCPServerLoggingLevelUnset(t *testing.T) {
+	_, p, _ := newTestLoggingServer(t)
+
+	resp := p.call(t, workRequest)
+	resp.assertResult(t, "2", workResult)
+}
+
+func testMCPServerLoggingInvalidLevel(t *testing.T) {
+	_, p, _ := newTestLoggingServer(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"logging/setLevel","params":{"level":"loud"}}`)
+	resp.assertError(t, "1", CodeInvalidParams)
+}
+
+// loggingLevelOfTestCase represents a test case for LoggingLevelOf
+type loggingLevelOfTestCase struct {
+	name  string
+	want  LoggingLevel
+	level slog.Level
+}
+
+// test runs the LoggingLevelOf test case
+func (tc loggingLevelOfTestCase) test(t *testing.T) {
+	if got := LoggingLevelOf(tc.level); got != tc.want {
+		t.Errorf("LoggingLevelOf(%v) = %q, want %q", tc.level, got, tc.want)
+	}
+}
+
+func TestLoggingLevelOf(t *testing.T) {
+	tests := []loggingLevelOfTestCase{
+		{"debug", LoggingLevelDebug, slog.LevelDebug},
+		{"below info", LoggingLevelDebug, slog.LevelInfo - 1},
+		{"info", LoggingLevelInfo, slog.LevelInfo},
+		{"notice", LoggingLevelNotice, slog.LevelInfo + 2},
+		{"warning", LoggingLevelWarning, slog.LevelWarn},
+		{"error", LoggingLevelError, slog.LevelError},
+		{"critical", LoggingLevelCritical, slog.LevelError + 4},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestSessionLogEnabled(t *testing.T) {
+	sess := &Session{}
+	if sess.LogEnabled(LoggingLevelEmergency) {
+		t.Error("logging enabled without level")
+	}
+
+	sess.setLogLevel(LoggingLevelWarning)
+	if sess.LogEnabled(LoggingLevelNotice) || !sess.LogEnabled(LoggingLevelWarning) {
+		t.Error("level not applied")
+	}
+}
@@ -0,0 +1,215 @@
+package protomcp
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+var (
+	_ jsonrpc2.Handler = (*MCPServer)(nil)
+	_ ConnServer       = (*MCPServer)(nil)
+)
+
+// MCPServer serves the Model Context Protocol on top of a Dispatcher. It
+// implements the lifecycle of MCP sessions, one per connection, from the
+// initialize handshake to shutdown, and advertises the capabilities of
+// the features registered on it.
+//
+// The exported fields must not be changed once the server is serving.
+type MCPServer struct {
+	caps       ServerCapabilities
+	d          *Dispatcher
+	sessions   map[*Session]struct{}
+	tools      registry[*toolEntry]
+	prompts    registry[*promptEntry]
+	resources  registry[*resourceEntry]
+	templates  registry[*templateEntry]
+	watchers   registry[*watcherEntry]
+	subs       map[string]*subscription // by resource URI
+	completers map[string]Completer     // by reference and argument
+	info       Implementation
+
+	// Instructions optionally describe how to use the server, and are
+	// sent to clients on initialisation.
+	Instructions string
+
+	// ProtocolVersions are the MCP revisions accepted, most preferred
+	// first. Empty means SupportedProtocolVersions.
+	ProtocolVersions []string
+
+	// PageSize is the number of items returned per page by list
+	// methods. Zero means DefaultPageSize.
+	PageSize int
+
+	inflight sync.WaitGroup // requests being handled
+	conns    sync.WaitGroup // open connections
+	mu       sync.Mutex
+	closing  bool
+}
+
+// NewMCPServer creates an MCPServer identifying itself with the given name
+// and version.
+func NewMCPServer(name, version string) *MCPServer {
+	s := &MCPServer{
+		d:        NewDispatcher(),
+		sessions: make(map[*Session]struct{}),
+		info:     Implementation{Name: name, Version: version},
+	}
+	s.registerLifecycle()
+	return s
+}
+
+// Dispatcher returns the underlying JSON-RPC Dispatcher, to register
+// additional methods.
+func (s *MCPServer) Dispatcher() *Dispatcher {
+	return s.d
+}
+
+// Info returns the implementation details sent to clients.
+func (s *MCPServer) Info() Implementation {
+	return s.info
+}
+
+// Capabilities returns the capabilities advertised to clients.
+func (s *MCPServer) Capabilities() ServerCapabilities {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.caps
+}
+
+// UpdateCapabilities modifies the capabilities advertised to clients.
+// Features enable their own capabilities when registered.
+func (s *MCPServer) UpdateCapabilities(fn func(*ServerCapabilities)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn(&s.caps)
+}
+
+// protocolVersions returns the effective ProtocolVersions.
+func (s *MCPServer) protocolVersions() []string {
+	if len(s.ProtocolVersions) > 0 {
+		return s.ProtocolVersions
+	}
+	return SupportedProtocolVersions()
+}
+
+// ServeConn starts an MCP session over stream, returning its connection.
+// The Session is made available to handlers through their context.
+func (s *MCPServer) ServeConn(ctx context.Context, stream jsonrpc2.ObjectStream,
+	opts ...jsonrpc2.ConnOpt) *jsonrpc2.Conn {
+	sess := &Session{}
+	conn := s.d.serveWith(WithSession(ctx, sess), stream, s, opts)
+	sess.setConn(conn)
+	s.track(sess, conn)
+	return conn
+}
+
+// track keeps account of a session until its connection is closed.
+func (s *MCPServer) track(sess *Session, conn *jsonrpc2.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closing {
+		_ = conn.Close()
+		return
+	}
+
+	s.sessions[sess] = struct{}{}
+	s.conns.Add(1)
+	go func() {
+		defer s.conns.Done()
+		<-conn.DisconnectNotify()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.sessions, sess)
+		s.dropSubscriptions(sess)
+	}()
+}
+
+// Handle implements jsonrpc2.Handler, enforcing the session lifecycle
+// before passing requests to the Dispatcher. The client can ask for the
+// progress of requests to be reported.
+func (s *MCPServer) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	if err := s.begin(ctx, req); err != nil {
+		if !req.Notif {
+			_ = conn.ReplyWithError(ctx, req.ID, toJSONRPCError(err))
+		}
+		return
+	}
+	defer s.inflight.Done()
+
+	if !req.Notif {
+		ctx = s.withProgress(ctx, conn, req)
+	}
+	s.d.Handle(ctx, conn, req)
+}
+
+// begin checks a request can be handled and accounts for it.
+func (s *MCPServer) begin(ctx context.Context, req *jsonrpc2.Request) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closing {
+		return NewError(CodeInternalError, "server shutting down")
+	}
+
+	if sess, ok := SessionFromContext(ctx); ok {
+		if err := sess.check(req.Method); err != nil {
+			return err
+		}
+	}
+
+	s.inflight.Add(1)
+	return nil
+}
+
+// Shutdown stops accepting requests, waits for those in progress to
+// complete and closes all connections. If ctx expires first its error is
+// returned.
+func (s *MCPServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closing = true
+	s.mu.Unlock()
+
+	if err := waitGroup(ctx, &s.inflight); err != nil {
+		return err
+	}
+
+	for _, conn := range s.openConns() {
+		_ = conn.Close()
+	}
+	return waitGroup(ctx, &s.conns)
+}
+
+// openConns returns the connections of all open sessions.
+func (s *MCPServer) openConns() []*jsonrpc2.Conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]*jsonrpc2.Conn, 0, len(s.sessions))
+	for sess := range s.sessions {
+		out = append(out, sess.Conn())
+	}
+	return out
+}
+
+// waitGroup waits for wg or the cancellation of ctx.
+func waitGroup(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,248 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// initRequest is the initialize request used by tests.
+const initRequest = `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{` +
+	`"protocolVersion":"2025-06-18","capabilities":{"roots":{"listChanged":true}},` +
+	`"clientInfo":{"name":"test","version":"1.0"}}}`
+
+// initialize completes the initialisation handshake.
+func (p *testPeer) initialize(t *testing.T) *InitializeResult {
+	t.Helper()
+
+	resp := p.call(t, initRequest)
+	if resp.Error != nil {
+		t.Fatalf("initialize: %v", resp.Error)
+	}
+
+	var result InitializeResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+
+	p.send(t, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	return &result
+}
+
+// initializeWith completes the initialisation handshake advertising the
+// given client capabilities.
+func (p *testPeer) initializeWith(t *testing.T, caps string) {
+	t.Helper()
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{`+
+		`"protocolVersion":"2025-06-18","capabilities":`+caps+`,`+
+		`"clientInfo":{"name":"test","version":"1.0"}}}`)
+	if resp.Error != nil {
+		t.Fatalf("initialize: %v", resp.Error)
+	}
+	p.send(t, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+}
+
+// newTestMCPServer returns an MCPServer with a method reporting the
+// session state.
+func newTestMCPServer() *MCPServer {
+	s := NewMCPServer("test-server", "0.1.0")
+	s.Dispatcher().HandleFunc("session", func(ctx context.Context, _ json.RawMessage) (any, error) {
+		sess, ok := SessionFromContext(ctx)
+		if !ok {
+			return nil, errors.New("no session")
+		}
+		return map[string]any{
+			"version":     sess.ProtocolVersion(),
+			"client":      sess.ClientInfo().Name,
+			"initialized": sess.Initialized(),
+			"roots":       sess.ClientCapabilities().Roots != nil,
+		}, nil
+	})
+	return s
+}
+
+func TestMCPServerInitialize(t *testing.T) {
+	t.Run("result", testMCPServerInitializeResult)
+	t.Run("version negotiation", testMCPServerInitializeVersions)
+	t.Run("missing version", testMCPServerInitializeMissingVersion)
+	t.Run("twice", testMCPServerInitializeTwice)
+}
+
+func testMCPServerInitializeResult(t *testing.T) {
+	s := newTestMCPServer()
+	s.Instructions = "be nice"
+	s.UpdateCapabilities(func(caps *ServerCapabilities) {
+		caps.Tools = &ToolsCapability{ListChanged: true}
+		caps.Logging = &LoggingCapability{}
+	})
+
+	resp := newTestPeer(t, s).call(t, initRequest)
+	resp.assertResult(t, "0", `{"capabilities":{"tools":{"listChanged":true},"logging":{}},`+
+		`"serverInfo":{"name":"test-server","version":"0.1.0"},`+
+		`"protocolVersion":"2025-06-18","instructions":"be nice"}`)
+}
+
+func testMCPServerInitializeVersions(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		want      string
+		supported []string
+	}{
+		{"latest", "2025-06-18", "2025-06-18", nil},
+		{"older", "2024-11-05", "2024-11-05", nil},
+		{"unknown", "1999-01-01", LatestProtocolVersion, nil},
+		{"restricted", "2025-06-18", "2025-03-26", []string{"2025-03-26", "2024-11-05"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestMCPServer()
+			s.ProtocolVersions = tc.supported
+
+			p := newTestPeer(t, s)
+			resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{`+
+				`"protocolVersion":"`+tc.requested+`","capabilities":{},"clientInfo":{"name":"c","version":"1"}}}`)
+			assertJSONField(t, resp.Result, "protocolVersion", tc.want)
+		})
+	}
+}
+
+func testMCPServerInitializeMissingVersion(t *testing.T) {
+	p := newTestPeer(t, newTestMCPServer())
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"capabilities":{}}}`)
+	resp.assertError(t, "1", CodeInvalidParams)
+
+	resp = p.call(t, `{"jsonrpc":"2.0","id":2,"method":"initialize"}`)
+	resp.assertError(t, "2", CodeInvalidParams)
+}
+
+func testMCPServerInitializeTwice(t *testing.T) {
+	p := newTestPeer(t, newTestMCPServer())
+	p.initialize(t)
+
+	resp := p.call(t, initRequest)
+	resp.assertError(t, "0", CodeInvalidRequest)
+}
+
+func TestMCPServerLifecycle(t *testing.T) {
+	t.Run("before initialize", testMCPServerBeforeInitialize)
+	t.Run("session", testMCPServerSession)
+	t.Run("ping", testMCPServerPing)
+}
+
+func testMCPServerBeforeInitialize(t *testing.T) {
+	p := newTestPeer(t, newTestMCPServer())
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"session"}`)
+	resp.assertError(t, "1", CodeInvalidRequest)
+}
+
+func testMCPServerSession(t *testing.T) {
+	p := newTestPeer(t, newTestMCPServer())
+	p.initialize(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"session"}`)
+	resp.assertResult(t, "1", `{"client":"test","initialized":true,"roots":true,"version":"2025-06-18"}`)
+}
+
+func testMCPServerPing(t *testing.T) {
+	p := newTestPeer(t, newTestMCPServer())
+
+	// allowed before initialisation
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	resp.assertResult(t, "1", `{}`)
+}
+
+func TestMCPServerShutdown(t *testing.T) {
+	t.Run("graceful", testMCPServerShutdownGraceful)
+	t.Run("timeout", testMCPServerShutdownTimeout)
+}
+
+func testMCPServerShutdownGraceful(t *testing.T) {
+	gate := newTestGate()
+	s := newTestMCPServer()
+	s.Dispatcher().HandleFunc("wait", gate.handle)
+
+	p := newTestPeer(t, s)
+	p.initialize(t)
+	p.send(t, `{"jsonrpc":"2.0","id":1,"method":"wait"}`)
+	gate.waitEntered(t, 1)
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.Shutdown(context.Background())
+	}()
+
+	// new requests are rejected while draining
+	waitClosing(t, s)
+	resp := p.call(t, `{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	resp.assertError(t, "2", CodeInternalError)
+
+	gate.release()
+	p.recvResponse(t).assertResult(t, "1", "true")
+
+	select {
+	case err := <-errs:
+		if err != nil {
+			t.Errorf("Shutdown() = %v", err)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("Shutdown() didn't return")
+	}
+}
+
+func testMCPServerShutdownTimeout(t *testing.T) {
+	gate := newTestGate()
+	defer gate.release()
+
+	s := newTestMCPServer()
+	s.Dispatcher().HandleFunc("wait", gate.handle)
+
+	p := newTestPeer(t, s)
+	p.initialize(t)
+	p.send(t, `{"jsonrpc":"2.0","id":1,"method":"wait"}`)
+	gate.waitEntered(t, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := s.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// waitClosing waits for a Shutdown to start.
+func waitClosing(t *testing.T, s *MCPServer) {
+	t.Helper()
+
+	deadline := time.Now().Add(testTimeout)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		closing := s.closing
+		s.mu.Unlock()
+
+		if closing {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("server not shutting down")
+}
+
+// assertJSONField checks a string member of a raw JSON object.
+func assertJSONField(t *testing.T, raw json.RawMessage, name, want string) {
+	t.Helper()
+
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("invalid object %s: %v", raw, err)
+	}
+	if got, ok := m[name].(string); !ok || got != want {
+		t.Errorf("%s = %q, want %q", name, got, want)
+	}
+}
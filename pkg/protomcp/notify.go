@@ -0,0 +1,54 @@
+package protomcp
+
+import (
+	"context"
+
+	"darvaza.org/core"
+	"github.com/sourcegraph/jsonrpc2"
+	"google.golang.org/protobuf/proto"
+)
+
+var notifierKey = core.NewContextKey[Notifier]("protomcp.notifier")
+
+// Notifier sends server-to-client notifications over a connection.
+type Notifier interface {
+	// Notify sends a notification. Proto params are encoded using
+	// protojson, anything else using encoding/json.
+	Notify(ctx context.Context, method string, params any) error
+}
+
+// WithNotifier returns a copy of ctx carrying n.
+func WithNotifier(ctx context.Context, n Notifier) context.Context {
+	return notifierKey.WithValue(ctx, n)
+}
+
+// NotifierFromContext returns the Notifier of the connection a request
+// was received on.
+func NotifierFromContext(ctx context.Context) (Notifier, bool) {
+	n, ok := notifierKey.Get(ctx)
+	return n, ok && n != nil
+}
+
+// Notifier returns a Notifier for a connection served by d, for use
+// outside of request handlers.
+func (d *Dispatcher) Notifier(conn *jsonrpc2.Conn) Notifier {
+	return &connNotifier{d: d, conn: conn}
+}
+
+// connNotifier is a Notifier writing to a jsonrpc2.Conn.
+type connNotifier struct {
+	d    *Dispatcher
+	conn *jsonrpc2.Conn
+}
+
+// Notify implements Notifier.
+func (n *connNotifier) Notify(ctx context.Context, method string, params any) error {
+	if m, ok := params.(proto.Message); ok {
+		raw, err := n.d.marshal(m)
+		if err != nil {
+			return err
+		}
+		params = raw
+	}
+	return n.conn.Notify(ctx, method, params)
+}
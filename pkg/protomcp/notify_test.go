@@ -0,0 +1,145 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestDispatcherNotifications(t *testing.T) {
+	t.Run("accepted", testDispatcherNotificationAccepted)
+	t.Run("ignored", testDispatcherNotificationIgnored)
+	t.Run("proto method", testDispatcherNotificationProto)
+}
+
+func testDispatcherNotificationAccepted(t *testing.T) {
+	events := make(chan json.RawMessage, 1)
+	d := NewDispatcher()
+	d.HandleNotificationFunc("event", func(_ context.Context, params json.RawMessage) (any, error) {
+		events <- params
+		return nil, nil
+	})
+
+	p := newTestPeer(t, d)
+	p.send(t, `{"jsonrpc":"2.0","method":"event","params":{"n":1}}`)
+
+	select {
+	case params := <-events:
+		if string(params) != `{"n":1}` {
+			t.Errorf("params = %s, want %s", params, `{"n":1}`)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("notification not delivered")
+	}
+
+	// can still be called as a request
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"event"}`)
+	resp.assertResult(t, "1", "null")
+}
+
+func testDispatcherNotificationIgnored(t *testing.T) {
+	var calls atomic.Int32
+	d := newTestDispatcher()
+	d.HandleFunc("count", func(context.Context, json.RawMessage) (any, error) {
+		return calls.Add(1), nil
+	})
+
+	p := newTestPeer(t, d)
+	p.send(t, `{"jsonrpc":"2.0","method":"count"}`)
+
+	// notifications are handled before any later request
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"test.Echo/Echo","params":{}}`)
+	resp.assertResult(t, "1", `{}`)
+	if n := calls.Load(); n != 0 {
+		t.Errorf("request-only method called %d times by notification", n)
+	}
+}
+
+func testDispatcherNotificationProto(t *testing.T) {
+	values := make(chan string, 1)
+	d := NewDispatcher()
+	d.RegisterNotification("test.Echo/Touch", NewMethodHandler(
+		func(_ context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+			values <- req.GetValue()
+			return req, nil
+		}))
+
+	p := newTestPeer(t, d)
+	p.send(t, `{"jsonrpc":"2.0","method":"test.Echo/Touch"}`)
+
+	select {
+	case <-values:
+	case <-time.After(testTimeout):
+		t.Fatal("notification not delivered")
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	t.Run("from handler", testNotifierFromHandler)
+	t.Run("from connection", testNotifierFromConn)
+	t.Run("missing", testNotifierMissing)
+}
+
+func testNotifierFromHandler(t *testing.T) {
+	d := NewDispatcher()
+	d.HandleFunc("push", func(ctx context.Context, _ json.RawMessage) (any, error) {
+		n, ok := NotifierFromContext(ctx)
+		if !ok {
+			return nil, NewError(CodeInternalError, "no notifier")
+		}
+		if err := n.Notify(ctx, "progress", wrapperspb.String("half")); err != nil {
+			return nil, err
+		}
+		return "done", n.Notify(ctx, "progress", map[string]int{"done": 1})
+	})
+
+	p := newTestPeer(t, d)
+	p.send(t, `{"jsonrpc":"2.0","id":1,"method":"push"}`)
+
+	assertNotification(t, p.recv(t), "progress", `"half"`)
+	assertNotification(t, p.recv(t), "progress", `{"done":1}`)
+	p.recvResponse(t).assertResult(t, "1", `"done"`)
+}
+
+func testNotifierFromConn(t *testing.T) {
+	d := NewDispatcher()
+	p, conn := newTestConn(t, d)
+
+	// writes block until read
+	errs := make(chan error, 1)
+	go func() {
+		errs <- d.Notifier(conn).Notify(context.Background(), "invalidate", []string{"users"})
+	}()
+
+	assertNotification(t, p.recv(t), "invalidate", `["users"]`)
+	if err := <-errs; err != nil {
+		t.Errorf("Notify: %v", err)
+	}
+}
+
+func testNotifierMissing(t *testing.T) {
+	if _, ok := NotifierFromContext(context.Background()); ok {
+		t.Error("NotifierFromContext() found a notifier in an empty context")
+	}
+}
+
+// assertNotification checks a raw message is the given notification.
+func assertNotification(t *testing.T, raw json.RawMessage, method, params string) {
+	t.Helper()
+
+	var m struct {
+		ID     *json.RawMessage `json:"id"`
+		Method string           `json:"method"`
+		Params json.RawMessage  `json:"params"`
+	}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("invalid notification %s: %v", raw, err)
+	}
+	if m.ID != nil || m.Method != method || string(m.Params) != params {
+		t.Errorf("got %s, want notification %q with params %s", raw, method, params)
+	}
+}
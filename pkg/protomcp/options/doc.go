@@ -0,0 +1,15 @@
+// Package options provides the Go types of the protomcp/options.proto
+// extensions, which control how protoc-gen-protomcp exposes services
+// through JSON-RPC 2.0 and MCP.
+//
+// The options are set in proto files importing protomcp/options.proto:
+//
+//	rpc GetUser(GetUserRequest) returns (User) {
+//	  option (protomcp.mcp).tool = {
+//	    title: "Get user"
+//	    read_only: true
+//	  };
+//	}
+package options
+
+//go:generate protoc -I ../../../proto --go_out=../../.. --go_opt=module=protomcp.org/protomcp protomcp/options.proto
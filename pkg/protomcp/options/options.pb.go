@@ -0,0 +1,635 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: protomcp/options.proto
+
+// Options controlling how services are exposed through JSON-RPC 2.0 and MCP
+// by protoc-gen-protomcp.
+
+package options
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// MCPServiceOptions control the MCP exposure of a service.
+type MCPServiceOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// hidden prevents any method of the service from being exposed through
+	// MCP.
+	Hidden bool `protobuf:"varint,1,opt,name=hidden,proto3" json:"hidden,omitempty"`
+	// prompts are the prompt templates offered by the service.
+	Prompts       []*PromptOptions `protobuf:"bytes,2,rep,name=prompts,proto3" json:"prompts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MCPServiceOptions) Reset() {
+	*x = MCPServiceOptions{}
+	mi := &file_protomcp_options_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCPServiceOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCPServiceOptions) ProtoMessage() {}
+
+func (x *MCPServiceOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_protomcp_options_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCPServiceOptions.ProtoReflect.Descriptor instead.
+func (*MCPServiceOptions) Descriptor() ([]byte, []int) {
+	return file_protomcp_options_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MCPServiceOptions) GetHidden() bool {
+	if x != nil {
+		return x.Hidden
+	}
+	return false
+}
+
+func (x *MCPServiceOptions) GetPrompts() []*PromptOptions {
+	if x != nil {
+		return x.Prompts
+	}
+	return nil
+}
+
+// MCPMethodOptions control the MCP exposure of a method.
+type MCPMethodOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// tool customises the tool generated for the method.
+	Tool *ToolOptions `protobuf:"bytes,1,opt,name=tool,proto3" json:"tool,omitempty"`
+	// resource exposes the method as a resource template instead of a tool.
+	Resource      *ResourceOptions `protobuf:"bytes,2,opt,name=resource,proto3" json:"resource,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MCPMethodOptions) Reset() {
+	*x = MCPMethodOptions{}
+	mi := &file_protomcp_options_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MCPMethodOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MCPMethodOptions) ProtoMessage() {}
+
+func (x *MCPMethodOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_protomcp_options_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MCPMethodOptions.ProtoReflect.Descriptor instead.
+func (*MCPMethodOptions) Descriptor() ([]byte, []int) {
+	return file_protomcp_options_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MCPMethodOptions) GetTool() *ToolOptions {
+	if x != nil {
+		return x.Tool
+	}
+	return nil
+}
+
+func (x *MCPMethodOptions) GetResource() *ResourceOptions {
+	if x != nil {
+		return x.Resource
+	}
+	return nil
+}
+
+// ToolOptions customise the MCP tool generated for a method.
+type ToolOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name overrides the default `Service_Method` tool name.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// title is a human readable name for the tool.
+	Title string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	// description overrides the leading comments of the method.
+	Description string `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	// hidden prevents the method from being exposed as a tool.
+	Hidden bool `protobuf:"varint,4,opt,name=hidden,proto3" json:"hidden,omitempty"`
+	// read_only tells the tool doesn't modify its environment.
+	ReadOnly *bool `protobuf:"varint,5,opt,name=read_only,json=readOnly,proto3,oneof" json:"read_only,omitempty"`
+	// destructive tells the tool may perform destructive updates.
+	Destructive *bool `protobuf:"varint,6,opt,name=destructive,proto3,oneof" json:"destructive,omitempty"`
+	// idempotent tells repeated calls with the same arguments have no
+	// additional effect.
+	Idempotent *bool `protobuf:"varint,7,opt,name=idempotent,proto3,oneof" json:"idempotent,omitempty"`
+	// open_world tells the tool may interact with external entities.
+	OpenWorld     *bool `protobuf:"varint,8,opt,name=open_world,json=openWorld,proto3,oneof" json:"open_world,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolOptions) Reset() {
+	*x = ToolOptions{}
+	mi := &file_protomcp_options_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolOptions) ProtoMessage() {}
+
+func (x *ToolOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_protomcp_options_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolOptions.ProtoReflect.Descriptor instead.
+func (*ToolOptions) Descriptor() ([]byte, []int) {
+	return file_protomcp_options_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ToolOptions) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ToolOptions) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ToolOptions) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ToolOptions) GetHidden() bool {
+	if x != nil {
+		return x.Hidden
+	}
+	return false
+}
+
+func (x *ToolOptions) GetReadOnly() bool {
+	if x != nil && x.ReadOnly != nil {
+		return *x.ReadOnly
+	}
+	return false
+}
+
+func (x *ToolOptions) GetDestructive() bool {
+	if x != nil && x.Destructive != nil {
+		return *x.Destructive
+	}
+	return false
+}
+
+func (x *ToolOptions) GetIdempotent() bool {
+	if x != nil && x.Idempotent != nil {
+		return *x.Idempotent
+	}
+	return false
+}
+
+func (x *ToolOptions) GetOpenWorld() bool {
+	if x != nil && x.OpenWorld != nil {
+		return *x.OpenWorld
+	}
+	return false
+}
+
+// ResourceOptions expose a method as an MCP resource template.
+type ResourceOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// uri_template is the RFC 6570 template of the resource URIs, whose
+	// variables bind to fields of the request message, e.g.
+	// `users://{user_id}`.
+	UriTemplate string `protobuf:"bytes,1,opt,name=uri_template,json=uriTemplate,proto3" json:"uri_template,omitempty"`
+	// name identifies the resource template.
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// title is a human readable name for the resource template.
+	Title string `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	// description overrides the leading comments of the method.
+	Description string `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	// mime_type is the media type of the resources.
+	MimeType      string `protobuf:"bytes,5,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResourceOptions) Reset() {
+	*x = ResourceOptions{}
+	mi := &file_protomcp_options_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceOptions) ProtoMessage() {}
+
+func (x *ResourceOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_protomcp_options_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceOptions.ProtoReflect.Descriptor instead.
+func (*ResourceOptions) Descriptor() ([]byte, []int) {
+	return file_protomcp_options_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ResourceOptions) GetUriTemplate() string {
+	if x != nil {
+		return x.UriTemplate
+	}
+	return ""
+}
+
+func (x *ResourceOptions) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ResourceOptions) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ResourceOptions) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ResourceOptions) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+// PromptOptions define an MCP prompt template.
+type PromptOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name uniquely identifies the prompt.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// title is a human readable name for the prompt.
+	Title string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	// description tells what the prompt is for.
+	Description string `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	// arguments is the fully-qualified name of the message whose scalar
+	// fields are the arguments of the prompt. Fields without explicit
+	// presence are required.
+	Arguments string `protobuf:"bytes,4,opt,name=arguments,proto3" json:"arguments,omitempty"`
+	// template is the text of the prompt, referencing arguments as
+	// `{{field_name}}`.
+	Template      string `protobuf:"bytes,5,opt,name=template,proto3" json:"template,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PromptOptions) Reset() {
+	*x = PromptOptions{}
+	mi := &file_protomcp_options_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PromptOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromptOptions) ProtoMessage() {}
+
+func (x *PromptOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_protomcp_options_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromptOptions.ProtoReflect.Descriptor instead.
+func (*PromptOptions) Descriptor() ([]byte, []int) {
+	return file_protomcp_options_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PromptOptions) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PromptOptions) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *PromptOptions) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *PromptOptions) GetArguments() string {
+	if x != nil {
+		return x.Arguments
+	}
+	return ""
+}
+
+func (x *PromptOptions) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+// JSONRPCMethodOptions control the JSON-RPC 2.0 exposure of a method.
+type JSONRPCMethodOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// method overrides the default fully-qualified method name.
+	Method string `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	// notification tells if the method can be called as a notification,
+	// overriding the default of methods returning google.protobuf.Empty.
+	Notification  *bool `protobuf:"varint,2,opt,name=notification,proto3,oneof" json:"notification,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JSONRPCMethodOptions) Reset() {
+	*x = JSONRPCMethodOptions{}
+	mi := &file_protomcp_options_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JSONRPCMethodOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JSONRPCMethodOptions) ProtoMessage() {}
+
+func (x *JSONRPCMethodOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_protomcp_options_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JSONRPCMethodOptions.ProtoReflect.Descriptor instead.
+func (*JSONRPCMethodOptions) Descriptor() ([]byte, []int) {
+	return file_protomcp_options_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *JSONRPCMethodOptions) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *JSONRPCMethodOptions) GetNotification() bool {
+	if x != nil && x.Notification != nil {
+		return *x.Notification
+	}
+	return false
+}
+
+var file_protomcp_options_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.ServiceOptions)(nil),
+		ExtensionType: (*MCPServiceOptions)(nil),
+		Field:         51730,
+		Name:          "protomcp.mcp_service",
+		Tag:           "bytes,51730,opt,name=mcp_service",
+		Filename:      "protomcp/options.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*MCPMethodOptions)(nil),
+		Field:         51731,
+		Name:          "protomcp.mcp",
+		Tag:           "bytes,51731,opt,name=mcp",
+		Filename:      "protomcp/options.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*JSONRPCMethodOptions)(nil),
+		Field:         51732,
+		Name:          "protomcp.jsonrpc",
+		Tag:           "bytes,51732,opt,name=jsonrpc",
+		Filename:      "protomcp/options.proto",
+	},
+}
+
+// Extension fields to descriptorpb.ServiceOptions.
+var (
+	// mcp_service controls how the service is exposed through MCP.
+	//
+	// optional protomcp.MCPServiceOptions mcp_service = 51730;
+	E_McpService = &file_protomcp_options_proto_extTypes[0]
+)
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// mcp controls how the method is exposed through MCP.
+	//
+	// optional protomcp.MCPMethodOptions mcp = 51731;
+	E_Mcp = &file_protomcp_options_proto_extTypes[1]
+	// jsonrpc controls how the method is exposed through JSON-RPC 2.0.
+	//
+	// optional protomcp.JSONRPCMethodOptions jsonrpc = 51732;
+	E_Jsonrpc = &file_protomcp_options_proto_extTypes[2]
+)
+
+var File_protomcp_options_proto protoreflect.FileDescriptor
+
+const file_protomcp_options_proto_rawDesc = "" +
+	"\n" +
+	"\x16protomcp/options.proto\x12\bprotomcp\x1a google/protobuf/descriptor.proto\"^\n" +
+	"\x11MCPServiceOptions\x12\x16\n" +
+	"\x06hidden\x18\x01 \x01(\bR\x06hidden\x121\n" +
+	"\aprompts\x18\x02 \x03(\v2\x17.protomcp.PromptOptionsR\aprompts\"t\n" +
+	"\x10MCPMethodOptions\x12)\n" +
+	"\x04tool\x18\x01 \x01(\v2\x15.protomcp.ToolOptionsR\x04tool\x125\n" +
+	"\bresource\x18\x02 \x01(\v2\x19.protomcp.ResourceOptionsR\bresource\"\xbf\x02\n" +
+	"\vToolOptions\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06hidden\x18\x04 \x01(\bR\x06hidden\x12 \n" +
+	"\tread_only\x18\x05 \x01(\bH\x00R\breadOnly\x88\x01\x01\x12%\n" +
+	"\vdestructive\x18\x06 \x01(\bH\x01R\vdestructive\x88\x01\x01\x12#\n" +
+	"\n" +
+	"idempotent\x18\a \x01(\bH\x02R\n" +
+	"idempotent\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"open_world\x18\b \x01(\bH\x03R\topenWorld\x88\x01\x01B\f\n" +
+	"\n" +
+	"_read_onlyB\x0e\n" +
+	"\f_destructiveB\r\n" +
+	"\v_idempotentB\r\n" +
+	"\v_open_world\"\x9d\x01\n" +
+	"\x0fResourceOptions\x12!\n" +
+	"\furi_template\x18\x01 \x01(\tR\vuriTemplate\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x1b\n" +
+	"\tmime_type\x18\x05 \x01(\tR\bmimeType\"\x95\x01\n" +
+	"\rPromptOptions\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1c\n" +
+	"\targuments\x18\x04 \x01(\tR\targuments\x12\x1a\n" +
+	"\btemplate\x18\x05 \x01(\tR\btemplate\"h\n" +
+	"\x14JSONRPCMethodOptions\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12'\n" +
+	"\fnotification\x18\x02 \x01(\bH\x00R\fnotification\x88\x01\x01B\x0f\n" +
+	"\r_notification:_\n" +
+	"\vmcp_service\x12\x1f.google.protobuf.ServiceOptions\x18\x92\x94\x03 \x01(\v2\x1b.protomcp.MCPServiceOptionsR\n" +
+	"mcpService:N\n" +
+	"\x03mcp\x12\x1e.google.protobuf.MethodOptions\x18\x93\x94\x03 \x01(\v2\x1a.protomcp.MCPMethodOptionsR\x03mcp:Z\n" +
+	"\ajsonrpc\x12\x1e.google.protobuf.MethodOptions\x18\x94\x94\x03 \x01(\v2\x1e.protomcp.JSONRPCMethodOptionsR\ajsonrpcB4Z2protomcp.org/protomcp/pkg/protomcp/options;optionsb\x06proto3"
+
+var (
+	file_protomcp_options_proto_rawDescOnce sync.Once
+	file_protomcp_options_proto_rawDescData []byte
+)
+
+func file_protomcp_options_proto_rawDescGZIP() []byte {
+	file_protomcp_options_proto_rawDescOnce.Do(func() {
+		file_protomcp_options_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_protomcp_options_proto_rawDesc), len(file_protomcp_options_proto_rawDesc)))
+	})
+	return file_protomcp_options_proto_rawDescData
+}
+
+var file_protomcp_options_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_protomcp_options_proto_goTypes = []any{
+	(*MCPServiceOptions)(nil),           // 0: protomcp.MCPServiceOptions
+	(*MCPMethodOptions)(nil),            // 1: protomcp.MCPMethodOptions
+	(*ToolOptions)(nil),                 // 2: protomcp.ToolOptions
+	(*ResourceOptions)(nil),             // 3: protomcp.ResourceOptions
+	(*PromptOptions)(nil),               // 4: protomcp.PromptOptions
+	(*JSONRPCMethodOptions)(nil),        // 5: protomcp.JSONRPCMethodOptions
+	(*descriptorpb.ServiceOptions)(nil), // 6: google.protobuf.ServiceOptions
+	(*descriptorpb.MethodOptions)(nil),  // 7: google.protobuf.MethodOptions
+}
+var file_protomcp_options_proto_depIdxs = []int32{
+	4, // 0: protomcp.MCPServiceOptions.prompts:type_name -> protomcp.PromptOptions
+	2, // 1: protomcp.MCPMethodOptions.tool:type_name -> protomcp.ToolOptions
+	3, // 2: protomcp.MCPMethodOptions.resource:type_name -> protomcp.ResourceOptions
+	6, // 3: protomcp.mcp_service:extendee -> google.protobuf.ServiceOptions
+	7, // 4: protomcp.mcp:extendee -> google.protobuf.MethodOptions
+	7, // 5: protomcp.jsonrpc:extendee -> google.protobuf.MethodOptions
+	0, // 6: protomcp.mcp_service:type_name -> protomcp.MCPServiceOptions
+	1, // 7: protomcp.mcp:type_name -> protomcp.MCPMethodOptions
+	5, // 8: protomcp.jsonrpc:type_name -> protomcp.JSONRPCMethodOptions
+	9, // [9:9] is the sub-list for method output_type
+	9, // [9:9] is the sub-list for method input_type
+	6, // [6:9] is the sub-list for extension type_name
+	3, // [3:6] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_protomcp_options_proto_init() }
+func file_protomcp_options_proto_init() {
+	if File_protomcp_options_proto != nil {
+		return
+	}
+	file_protomcp_options_proto_msgTypes[2].OneofWrappers = []any{}
+	file_protomcp_options_proto_msgTypes[5].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_protomcp_options_proto_rawDesc), len(file_protomcp_options_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 3,
+			NumServices:   0,
+		},
+		GoTypes:           file_protomcp_options_proto_goTypes,
+		DependencyIndexes: file_protomcp_options_proto_depIdxs,
+		MessageInfos:      file_protomcp_options_proto_msgTypes,
+		ExtensionInfos:    file_protomcp_options_proto_extTypes,
+	}.Build()
+	File_protomcp_options_proto = out.File
+	file_protomcp_options_proto_goTypes = nil
+	file_protomcp_options_proto_depIdxs = nil
+}
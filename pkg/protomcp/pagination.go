@@ -0,0 +1,59 @@
+package protomcp
+
+import (
+	"encoding/base64"
+	"strconv"
+)
+
+// DefaultPageSize is the number of items returned per page by list
+// methods when MCPServer.PageSize isn't set.
+const DefaultPageSize = 50
+
+// PaginatedParams are the params of list requests.
+type PaginatedParams struct {
+	// Cursor is the opaque position returned by a previous page.
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// paginate returns the page of items starting at cursor, and the cursor of
+// the next page, if any.
+func paginate[T any](items []T, cursor string, size int) ([]T, string, error) {
+	start, err := decodeCursor(cursor)
+	if err != nil || start > len(items) {
+		return nil, "", NewError(CodeInvalidParams, "invalid params: invalid cursor")
+	}
+
+	end := start + size
+	if size <= 0 || end >= len(items) {
+		return items[start:], "", nil
+	}
+	return items[start:end], encodeCursor(end), nil
+}
+
+// encodeCursor returns the opaque cursor for an offset.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset of a cursor. An empty cursor is the
+// start of the list.
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.Atoi(string(b))
+	switch {
+	case err != nil:
+		return 0, err
+	case n < 0:
+		return 0, strconv.ErrRange
+	default:
+		return n, nil
+	}
+}
@@ -0,0 +1,51 @@
+package protomcp
+
+import (
+	"fmt"
+	"testing"
+)
+
+// paginateTestCase represents a test case for paginate
+type paginateTestCase struct {
+	name   string
+	cursor string
+	want   string
+	next   string
+	size   int
+	ok     bool
+}
+
+// test runs the paginate test case
+func (tc paginateTestCase) test(t *testing.T) {
+	t.Helper()
+
+	items := []int{0, 1, 2, 3, 4}
+	page, next, err := paginate(items, tc.cursor, tc.size)
+	if (err == nil) != tc.ok {
+		t.Fatalf("paginate() error = %v, want ok %v", err, tc.ok)
+	}
+	if got := fmt.Sprint(page); tc.ok && got != tc.want {
+		t.Errorf("paginate() page = %s, want %s", got, tc.want)
+	}
+	if next != tc.next {
+		t.Errorf("paginate() next = %q, want %q", next, tc.next)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []paginateTestCase{
+		{"all", "", "[0 1 2 3 4]", "", 0, true},
+		{"first page", "", "[0 1]", encodeCursor(2), 2, true},
+		{"middle page", encodeCursor(2), "[2 3]", encodeCursor(4), 2, true},
+		{"last page", encodeCursor(4), "[4]", "", 2, true},
+		{"exact fit", "", "[0 1 2 3 4]", "", 5, true},
+		{"at end", encodeCursor(5), "[]", "", 2, true},
+		{"past end", encodeCursor(6), "", "", 2, false},
+		{"negative", encodeCursor(-1), "", "", 2, false},
+		{"garbage", "!!", "", "", 2, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
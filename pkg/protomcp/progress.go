@@ -0,0 +1,112 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"darvaza.org/core"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// MethodProgress is the notification reporting the progress of a
+// request.
+const MethodProgress = "notifications/progress"
+
+var progressKey = core.NewContextKey[*ProgressReporter]("protomcp.progress")
+
+// ProgressParams are the params of notifications/progress.
+type ProgressParams struct {
+	// Message optionally describes the current progress.
+	Message string `json:"message,omitempty"`
+	// ProgressToken is the token given by the client in the request.
+	ProgressToken json.RawMessage `json:"progressToken"`
+	// Progress is the progress so far, increasing with every
+	// notification.
+	Progress float64 `json:"progress"`
+	// Total is the optional total progress expected.
+	Total float64 `json:"total,omitempty"`
+}
+
+// ProgressReporter reports the progress of a request whose client asked
+// for it by giving a progress token.
+type ProgressReporter struct {
+	n        Notifier
+	token    json.RawMessage
+	messages bool // supported by the protocol revision
+}
+
+// Report sends a progress notification. progress must increase with every
+// call, and total is zero if unknown. The message is dropped when the
+// negotiated protocol revision doesn't support it.
+func (p *ProgressReporter) Report(ctx context.Context, progress, total float64, message string) error {
+	params := &ProgressParams{
+		ProgressToken: p.token,
+		Progress:      progress,
+		Total:         total,
+	}
+	if p.messages {
+		params.Message = message
+	}
+	return p.n.Notify(ctx, MethodProgress, params)
+}
+
+// ProgressFromContext returns the ProgressReporter of a request, if the
+// client asked for progress notifications.
+func ProgressFromContext(ctx context.Context) (*ProgressReporter, bool) {
+	p, ok := progressKey.Get(ctx)
+	return p, ok && p != nil
+}
+
+// ReportProgress reports the progress of a request, if the client asked
+// for it. See ProgressReporter.Report.
+func ReportProgress(ctx context.Context, progress, total float64, message string) error {
+	if p, ok := ProgressFromContext(ctx); ok {
+		return p.Report(ctx, progress, total, message)
+	}
+	return nil
+}
+
+// requestMeta is the `_meta` of the params of a request.
+type requestMeta struct {
+	ProgressToken json.RawMessage `json:"progressToken"`
+}
+
+// metaParams are the params of a request carrying `_meta`.
+type metaParams struct {
+	Meta requestMeta `json:"_meta"`
+}
+
+// withProgress returns a copy of ctx carrying a ProgressReporter if the
+// request has a progress token.
+func (s *MCPServer) withProgress(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) context.Context {
+	token := progressToken(req)
+	if token == nil {
+		return ctx
+	}
+
+	return progressKey.WithValue(ctx, &ProgressReporter{
+		n:        s.d.Notifier(conn),
+		token:    token,
+		messages: sessionVersion(ctx) >= ProtocolVersion20250326,
+	})
+}
+
+// progressToken returns the `_meta.progressToken` of the params of a
+// request, if it is a string or a number.
+func progressToken(req *jsonrpc2.Request) json.RawMessage {
+	if req.Params == nil || jsonKind(*req.Params) != '{' {
+		return nil
+	}
+
+	var p metaParams
+	if json.Unmarshal(*req.Params, &p) != nil {
+		return nil
+	}
+
+	switch k := jsonKind(p.Meta.ProgressToken); {
+	case k == '"', k == '-', k >= '0' && k <= '9':
+		return p.Meta.ProgressToken
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// newTestProgressServer returns an initialised MCP peer with a tool
+// reporting its progress, negotiating the given protocol versions.
+func newTestProgressServer(t *testing.T, versions ...string) *testPeer {
+	t.Helper()
+
+	s := newTestMCPServer()
+	s.ProtocolVersions = versions
+	s.AddTool(&Tool{
+		Name:        "reindex",
+		InputSchema: json.RawMessage(`{"type":"object"}`),
+	}, NewMethodHandler(func(ctx context.Context, req *emptypb.Empty) (*emptypb.Empty, error) {
+		if err := ReportProgress(ctx, 1, 2, "half"); err != nil {
+			return nil, err
+		}
+		return req, nil
+	}))
+
+	p := newTestPeer(t, s)
+	p.initialize(t)
+	return p
+}
+
+// reindexRequest is the tools/call request of the reindex tool, asking
+// for progress.
+const reindexRequest = `{"jsonrpc":"2.0","id":1,"method":"tools/call",` +
+	`"params":{"name":"reindex","arguments":{},"_meta":{"progressToken":"t1"}}}`
+
+func TestMCPServerProgress(t *testing.T) {
+	t.Run("reported", testMCPServerProgressReported)
+	t.Run("not requested", testMCPServerProgressNotRequested)
+	t.Run("older revision", testMCPServerProgressOlderRevision)
+}
+
+func testMCPServerProgressReported(t *testing.T) {
+	p := newTestProgressServer(t)
+
+	p.send(t, reindexRequest)
+	assertNotification(t, p.recv(t), MethodProgress,
+		`{"message":"half","progressToken":"t1","progress":1,"total":2}`)
+	p.recvResponse(t).assertResult(t, "1", `{"structuredContent":{},"content":[{"type":"text","text":"{}"}]}`)
+}
+
+func testMCPServerProgressNotRequested(t *testing.T) {
+	p := newTestProgressServer(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"reindex"}}`)
+	resp.assertResult(t, "1", `{"structuredContent":{},"content":[{"type":"text","text":"{}"}]}`)
+}
+
+func testMCPServerProgressOlderRevision(t *testing.T) {
+	p := newTestProgressServer(t, ProtocolVersion20241105)
+
+	p.send(t, reindexRequest)
+	assertNotification(t, p.recv(t), MethodProgress, `{"progressToken":"t1","progress":1,"total":2}`)
+	p.recvResponse(t).assertResult(t, "1", `{"content":[{"type":"text","text":"{}"}]}`)
+}
+
+// progressTokenTestCase represents a test case for progressToken
+type progressTokenTestCase struct {
+	name   string
+	params string
+	token  string
+}
+
+// test runs the progressToken test case
+func (tc progressTokenTestCase) test(t *testing.T) {
+	req := &jsonrpc2.Request{Method: "test"}
+	if tc.params != "" {
+		params := json.RawMessage(tc.params)
+		req.Params = &params
+	}
+
+	if got := string(progressToken(req)); got != tc.token {
+		t.Errorf("progressToken(%s) = %q, want %q", tc.params, got, tc.token)
+	}
+}
+
+func TestProgressToken(t *testing.T) {
+	tests := []progressTokenTestCase{
+		{"string", `{"_meta":{"progressToken":"abc"}}`, `"abc"`},
+		{"number", `{"_meta":{"progressToken":42}}`, `42`},
+		{"negative", `{"_meta":{"progressToken":-1}}`, `-1`},
+		{"object", `{"_meta":{"progressToken":{}}}`, ""},
+		{"null", `{"_meta":{"progressToken":null}}`, ""},
+		{"no meta", `{"name":"x"}`, ""},
+		{"array params", `[1]`, ""},
+		{"no params", "", ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
@@ -0,0 +1,198 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MCP prompts methods.
+const (
+	MethodPromptsList = "prompts/list"
+	MethodPromptsGet  = "prompts/get"
+)
+
+// Roles of the messages of a prompt.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Prompt describes an MCP prompt. Generated code describes the prompts
+// declared in the `(protomcp.mcp_service)` options of a service, with
+// arguments derived from the fields of a message.
+type Prompt struct {
+	// Name uniquely identifies the prompt.
+	Name string `json:"name"`
+	// Title is an optional human readable name.
+	Title string `json:"title,omitempty"`
+	// Description tells what the prompt is for.
+	Description string `json:"description,omitempty"`
+	// Arguments are the arguments the prompt takes.
+	Arguments []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptArgument describes an argument of a prompt.
+type PromptArgument struct {
+	// Name identifies the argument.
+	Name string `json:"name"`
+	// Title is an optional human readable name.
+	Title string `json:"title,omitempty"`
+	// Description tells what the argument is.
+	Description string `json:"description,omitempty"`
+	// Required tells if the argument must be provided.
+	Required bool `json:"required,omitempty"`
+}
+
+// ListPromptsResult is the result of prompts/list.
+type ListPromptsResult struct {
+	NextCursor string    `json:"nextCursor,omitempty"`
+	Prompts    []*Prompt `json:"prompts"`
+}
+
+// GetPromptParams are the params of prompts/get.
+type GetPromptParams struct {
+	Arguments map[string]string `json:"arguments,omitempty"`
+	Name      string            `json:"name"`
+}
+
+// GetPromptResult is the result of prompts/get.
+type GetPromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// PromptMessage is a message of a prompt.
+type PromptMessage struct {
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
+}
+
+// PromptHandler returns the messages of a prompt given its arguments,
+// which are checked to include the required ones.
+type PromptHandler func(ctx context.Context, args map[string]string) (*GetPromptResult, error)
+
+// promptEntry is a registered prompt.
+type promptEntry struct {
+	prompt *Prompt
+	h      PromptHandler
+}
+
+// AddPrompt registers a prompt, replacing any previous one of the same
+// name.
+func (s *MCPServer) AddPrompt(prompt *Prompt, h PromptHandler) {
+	if prompt == nil || prompt.Name == "" || h == nil {
+		panic(errors.New("protomcp: invalid prompt"))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.prompts.len() == 0 {
+		s.d.HandleFunc(MethodPromptsList, s.handleListPrompts)
+		s.d.HandleFunc(MethodPromptsGet, s.handleGetPrompt)
+	}
+	if s.caps.Prompts == nil {
+		s.caps.Prompts = &PromptsCapability{}
+	}
+
+	s.prompts.add(prompt.Name, &promptEntry{prompt: prompt, h: h})
+}
+
+// AddPromptTemplate registers a prompt whose single user message is a
+// PromptTemplate, replacing any previous one of the same name. Every
+// argument referenced by the template must be declared by the prompt.
+func (s *MCPServer) AddPromptTemplate(prompt *Prompt, template string) {
+	t, err := ParsePromptTemplate(template)
+	if err != nil {
+		panic(fmt.Errorf("protomcp: %w", err))
+	}
+
+	for _, name := range t.Variables() {
+		if prompt == nil || !hasPromptArgument(prompt, name) {
+			panic(fmt.Errorf("protomcp: prompt template references undeclared argument %q", name))
+		}
+	}
+
+	s.AddPrompt(prompt, promptTemplateHandler(t))
+}
+
+// hasPromptArgument tells if a prompt declares an argument.
+func hasPromptArgument(prompt *Prompt, name string) bool {
+	for _, arg := range prompt.Arguments {
+		if arg.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Prompts returns the registered prompts.
+func (s *MCPServer) Prompts() []*Prompt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return mapValues(&s.prompts, func(e *promptEntry) *Prompt { return e.prompt })
+}
+
+// prompt returns a registered prompt by name.
+func (s *MCPServer) prompt(name string) (*promptEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.prompts.get(name)
+}
+
+// handleListPrompts implements prompts/list.
+func (s *MCPServer) handleListPrompts(_ context.Context, params json.RawMessage) (any, error) {
+	var p PaginatedParams
+	if err := unmarshalOptionalParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	prompts, next, err := paginate(s.Prompts(), p.Cursor, s.pageSize())
+	if err != nil {
+		return nil, err
+	}
+	return &ListPromptsResult{Prompts: prompts, NextCursor: next}, nil
+}
+
+// handleGetPrompt implements prompts/get.
+func (s *MCPServer) handleGetPrompt(ctx context.Context, params json.RawMessage) (any, error) {
+	var p GetPromptParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	e, ok := s.prompt(p.Name)
+	if !ok {
+		return nil, NewError(CodeInvalidParams, "unknown prompt: %s", p.Name)
+	}
+
+	if err := checkPromptArguments(e.prompt, p.Arguments); err != nil {
+		return nil, err
+	}
+
+	result, err := e.h(ctx, p.Arguments)
+	switch {
+	case err != nil:
+		return nil, err
+	case result == nil:
+		return nil, NewError(CodeInternalError, "prompt %s returned no result", p.Name)
+	case result.Description == "":
+		result.Description = e.prompt.Description
+	}
+	return result, nil
+}
+
+// checkPromptArguments checks the required arguments of a prompt are
+// provided.
+func checkPromptArguments(prompt *Prompt, args map[string]string) error {
+	for _, arg := range prompt.Arguments {
+		if _, ok := args[arg.Name]; arg.Required && !ok {
+			return NewError(CodeInvalidParams, "missing argument: %s", arg.Name)
+		}
+	}
+	return nil
+}
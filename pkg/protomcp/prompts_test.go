@@ -0,0 +1,115 @@
+package protomcp
+
+import (
+	"context"
+	"testing"
+)
+
+// newTestPromptServer returns an initialised MCP peer with a template
+// prompt and a custom one.
+func newTestPromptServer(t *testing.T) (*MCPServer, *testPeer) {
+	t.Helper()
+
+	s := newTestMCPServer()
+	s.AddPromptTemplate(&Prompt{
+		Name:        "review",
+		Description: "Review a user.",
+		Arguments: []PromptArgument{
+			{Name: "user_id", Required: true},
+			{Name: "focus"},
+		},
+	}, "Review user {{user_id}}, focusing on {{focus}}.")
+	s.AddPrompt(&Prompt{Name: "greet"}, func(context.Context, map[string]string) (*GetPromptResult, error) {
+		return &GetPromptResult{
+			Description: "Greetings.",
+			Messages: []PromptMessage{
+				{Role: RoleUser, Content: TextContent("Hello")},
+				{Role: RoleAssistant, Content: TextContent("Hi!")},
+			},
+		}, nil
+	})
+
+	p := newTestPeer(t, s)
+	p.initialize(t)
+	return s, p
+}
+
+func TestMCPServerAddPrompt(t *testing.T) {
+	t.Run("registered", testMCPServerAddPromptRegistered)
+	t.Run("undeclared argument", testMCPServerAddPromptUndeclared)
+}
+
+func testMCPServerAddPromptRegistered(t *testing.T) {
+	s, _ := newTestPromptServer(t)
+
+	if caps := s.Capabilities(); caps.Prompts == nil {
+		t.Error("prompts capability not advertised")
+	}
+	if n := len(s.Prompts()); n != 2 {
+		t.Errorf("Prompts() = %d, want 2", n)
+	}
+}
+
+func testMCPServerAddPromptUndeclared(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("undeclared argument accepted")
+		}
+	}()
+
+	s := newTestMCPServer()
+	s.AddPromptTemplate(&Prompt{Name: "bad"}, "Hello {{name}}")
+}
+
+func TestMCPServerListPrompts(t *testing.T) {
+	_, p := newTestPromptServer(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`)
+	resp.assertResult(t, "1", `{"prompts":[`+
+		`{"name":"review","description":"Review a user.","arguments":[`+
+		`{"name":"user_id","required":true},{"name":"focus"}]},`+
+		`{"name":"greet"}]}`)
+}
+
+func TestMCPServerGetPrompt(t *testing.T) {
+	t.Run("template", testMCPServerGetPromptTemplate)
+	t.Run("custom", testMCPServerGetPromptCustom)
+	t.Run("missing argument", testMCPServerGetPromptMissing)
+	t.Run("unknown", testMCPServerGetPromptUnknown)
+}
+
+func testMCPServerGetPromptTemplate(t *testing.T) {
+	_, p := newTestPromptServer(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"prompts/get",`+
+		`"params":{"name":"review","arguments":{"user_id":"42","focus":"security"}}}`)
+	resp.assertResult(t, "1", `{"description":"Review a user.","messages":[`+
+		`{"role":"user","content":{"type":"text","text":"Review user 42, focusing on security."}}]}`)
+}
+
+func testMCPServerGetPromptCustom(t *testing.T) {
+	_, p := newTestPromptServer(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"greet"}}`)
+	resp.assertResult(t, "1", `{"description":"Greetings.","messages":[`+
+		`{"role":"user","content":{"type":"text","text":"Hello"}},`+
+		`{"role":"assistant","content":{"type":"text","text":"Hi!"}}]}`)
+}
+
+func testMCPServerGetPromptMissing(t *testing.T) {
+	_, p := newTestPromptServer(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"prompts/get",`+
+		`"params":{"name":"review","arguments":{"focus":"security"}}}`)
+	resp.assertError(t, "1", CodeInvalidParams)
+}
+
+func testMCPServerGetPromptUnknown(t *testing.T) {
+	_, p := newTestPromptServer(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"nope"}}`)
+	resp.assertError(t, "1", CodeInvalidParams)
+
+	resp = p.call(t, `{"jsonrpc":"2.0","id":2,"method":"prompts/get"}`)
+	resp.assertError(t, "2", CodeInvalidParams)
+}
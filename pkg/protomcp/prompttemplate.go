@@ -0,0 +1,26 @@
+package protomcp
+
+import (
+	"context"
+
+	"protomcp.org/protomcp/pkg/protomcp/template"
+)
+
+// PromptTemplate is a parsed prompt template, the text of a prompt
+// referencing its arguments as `{{name}}`.
+type PromptTemplate = template.Prompt
+
+// ParsePromptTemplate parses a prompt template.
+func ParsePromptTemplate(s string) (*PromptTemplate, error) {
+	return template.ParsePrompt(s)
+}
+
+// promptTemplateHandler returns the PromptHandler of a template, returning
+// it expanded as a single user message.
+func promptTemplateHandler(t *PromptTemplate) PromptHandler {
+	return func(_ context.Context, args map[string]string) (*GetPromptResult, error) {
+		return &GetPromptResult{
+			Messages: []PromptMessage{{Role: RoleUser, Content: TextContent(t.Expand(args))}},
+		}, nil
+	}
+}
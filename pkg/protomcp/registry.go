@@ -0,0 +1,46 @@
+package protomcp
+
+// registry holds named entries in registration order, as listed to
+// clients. Replacing an entry keeps its position.
+type registry[T any] struct {
+	index map[string]int // position in list
+	list  []T
+}
+
+// add registers an entry, replacing any previous one of the same name.
+func (r *registry[T]) add(name string, v T) {
+	if i, ok := r.index[name]; ok {
+		r.list[i] = v
+		return
+	}
+
+	if r.index == nil {
+		r.index = make(map[string]int)
+	}
+	r.index[name] = len(r.list)
+	r.list = append(r.list, v)
+}
+
+// get returns an entry by name.
+func (r *registry[T]) get(name string) (T, bool) {
+	i, ok := r.index[name]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return r.list[i], true
+}
+
+// len returns the number of entries.
+func (r *registry[T]) len() int {
+	return len(r.list)
+}
+
+// mapValues returns the entries of a registry converted by fn.
+func mapValues[T, V any](r *registry[T], fn func(T) V) []V {
+	out := make([]V, len(r.list))
+	for i, v := range r.list {
+		out[i] = fn(v)
+	}
+	return out
+}
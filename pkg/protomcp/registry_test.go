@@ -0,0 +1,27 @@
+package protomcp
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	var r registry[int]
+	if _, ok := r.get("a"); ok {
+		t.Error("get() on empty registry succeeded")
+	}
+
+	r.add("a", 1)
+	r.add("b", 2)
+	r.add("a", 3)
+
+	if v, ok := r.get("a"); !ok || v != 3 {
+		t.Errorf("get(a) = %v, %v, want 3, true", v, ok)
+	}
+	if r.len() != 2 {
+		t.Errorf("len() = %d, want 2", r.len())
+	}
+	if got := fmt.Sprint(mapValues(&r, func(v int) int { return v * 10 })); got != "[30 20]" {
+		t.Errorf("mapValues() = %s, want [30 20]", got)
+	}
+}
@@ -0,0 +1,257 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// MCP resources methods.
+const (
+	MethodResourcesList          = "resources/list"
+	MethodResourcesTemplatesList = "resources/templates/list"
+	MethodResourcesRead          = "resources/read"
+)
+
+// DefaultResourceMimeType is the media type of resources whose MimeType
+// isn't set, as response messages are encoded with protojson.
+const DefaultResourceMimeType = "application/json"
+
+// Resource describes an MCP resource with a fixed URI.
+type Resource struct {
+	// URI identifies the resource.
+	URI string `json:"uri"`
+	// Name identifies the resource for display.
+	Name string `json:"name"`
+	// Title is an optional human readable name.
+	Title string `json:"title,omitempty"`
+	// Description tells what the resource contains.
+	Description string `json:"description,omitempty"`
+	// MimeType is the media type of the resource.
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// ResourceTemplate describes a family of MCP resources whose URIs match
+// a template. Generated code describes the methods annotated with a URI
+// template as resource templates, binding the variables of the template
+// to fields of the request message.
+type ResourceTemplate struct {
+	// URITemplate is the RFC 6570 template of the resource URIs.
+	URITemplate string `json:"uriTemplate"`
+	// Name identifies the resource template for display.
+	Name string `json:"name"`
+	// Title is an optional human readable name.
+	Title string `json:"title,omitempty"`
+	// Description tells what the resources contain.
+	Description string `json:"description,omitempty"`
+	// MimeType is the media type of the resources.
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// ListResourcesResult is the result of resources/list.
+type ListResourcesResult struct {
+	NextCursor string      `json:"nextCursor,omitempty"`
+	Resources  []*Resource `json:"resources"`
+}
+
+// ListResourceTemplatesResult is the result of resources/templates/list.
+type ListResourceTemplatesResult struct {
+	NextCursor        string              `json:"nextCursor,omitempty"`
+	ResourceTemplates []*ResourceTemplate `json:"resourceTemplates"`
+}
+
+// ReadResourceParams are the params of resources/read.
+type ReadResourceParams struct {
+	URI string `json:"uri"`
+}
+
+// ReadResourceResult is the result of resources/read.
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// ResourceContents are the contents of a resource, either text or base64
+// encoded binary data.
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// resourceEntry is a registered resource.
+type resourceEntry struct {
+	res *Resource
+	h   MethodHandler
+}
+
+// templateEntry is a registered resource template.
+type templateEntry struct {
+	tmpl *ResourceTemplate
+	uri  *URITemplate
+	h    MethodHandler
+}
+
+// resourceMatch is the resolution of a resource URI.
+type resourceMatch struct {
+	h        MethodHandler
+	dec      func(proto.Message) error
+	mimeType string
+}
+
+// AddResource registers a resource with a fixed URI, replacing any
+// previous one with the same URI. The response message of h is returned
+// as its contents.
+func (s *MCPServer) AddResource(res *Resource, h MethodHandler) {
+	if res == nil || res.URI == "" || h == nil {
+		panic(errors.New("protomcp: invalid resource"))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.enableResources()
+	s.resources.add(res.URI, &resourceEntry{res: res, h: h})
+}
+
+// AddResourceTemplate registers a resource template, replacing any
+// previous one with the same URI template. The variables of the template
+// are decoded into the fields of the request message of h with the same
+// name or JSON name, and the response message returned as contents.
+func (s *MCPServer) AddResourceTemplate(tmpl *ResourceTemplate, h MethodHandler) {
+	if tmpl == nil || h == nil {
+		panic(errors.New("protomcp: invalid resource template"))
+	}
+
+	uri, err := ParseURITemplate(tmpl.URITemplate)
+	if err != nil {
+		panic(fmt.Errorf("protomcp: %w", err))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.enableResources()
+	s.templates.add(tmpl.URITemplate, &templateEntry{tmpl: tmpl, uri: uri, h: h})
+}
+
+// enableResources registers the resources methods and advertises the
+// capability, including subscriptions and list changes, when the first
+// resource is added.
+func (s *MCPServer) enableResources() {
+	if s.resources.len()+s.templates.len() == 0 {
+		s.d.HandleFunc(MethodResourcesList, s.handleListResources)
+		s.d.HandleFunc(MethodResourcesTemplatesList, s.handleListResourceTemplates)
+		s.d.HandleFunc(MethodResourcesRead, s.handleReadResource)
+		s.d.HandleFunc(MethodResourcesSubscribe, s.handleSubscribe)
+		s.d.HandleFunc(MethodResourcesUnsubscribe, s.handleUnsubscribe)
+	}
+	if s.caps.Resources == nil {
+		s.caps.Resources = &ResourcesCapability{Subscribe: true, ListChanged: true}
+	}
+}
+
+// resourceNotFound returns the error reported for unknown resources.
+func resourceNotFound(uri string) *Error {
+	return &Error{
+		Code:    CodeResourceNotFound,
+		Message: "resource not found",
+		Data:    map[string]string{"uri": uri},
+	}
+}
+
+// Resources returns the registered resources.
+func (s *MCPServer) Resources() []*Resource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return mapValues(&s.resources, func(e *resourceEntry) *Resource { return e.res })
+}
+
+// ResourceTemplates returns the registered resource templates.
+func (s *MCPServer) ResourceTemplates() []*ResourceTemplate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return mapValues(&s.templates, func(e *templateEntry) *ResourceTemplate { return e.tmpl })
+}
+
+// resolveResource finds the handler of a resource URI. Fixed resources
+// take precedence over templates, which are tried in registration order.
+func (s *MCPServer) resolveResource(uri string) (*resourceMatch, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.resources.get(uri); ok {
+		return &resourceMatch{h: e.h, dec: s.d.decoder(nil), mimeType: e.res.MimeType}, true
+	}
+
+	for _, e := range s.templates.list {
+		if values, ok := e.uri.Match(uri); ok {
+			return &resourceMatch{h: e.h, dec: fieldsDecoder(values), mimeType: e.tmpl.MimeType}, true
+		}
+	}
+	return nil, false
+}
+
+// handleListResources implements resources/list.
+func (s *MCPServer) handleListResources(_ context.Context, params json.RawMessage) (any, error) {
+	var p PaginatedParams
+	if err := unmarshalOptionalParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	resources, next, err := paginate(s.Resources(), p.Cursor, s.pageSize())
+	if err != nil {
+		return nil, err
+	}
+	return &ListResourcesResult{Resources: resources, NextCursor: next}, nil
+}
+
+// handleListResourceTemplates implements resources/templates/list.
+func (s *MCPServer) handleListResourceTemplates(_ context.Context, params json.RawMessage) (any, error) {
+	var p PaginatedParams
+	if err := unmarshalOptionalParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	templates, next, err := paginate(s.ResourceTemplates(), p.Cursor, s.pageSize())
+	if err != nil {
+		return nil, err
+	}
+	return &ListResourceTemplatesResult{ResourceTemplates: templates, NextCursor: next}, nil
+}
+
+// handleReadResource implements resources/read.
+func (s *MCPServer) handleReadResource(ctx context.Context, params json.RawMessage) (any, error) {
+	var p ReadResourceParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	match, ok := s.resolveResource(p.URI)
+	if !ok {
+		return nil, resourceNotFound(p.URI)
+	}
+
+	resp, err := match.h(ctx, match.dec)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := s.d.marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	mimeType := match.mimeType
+	if mimeType == "" {
+		mimeType = DefaultResourceMimeType
+	}
+	return &ReadResourceResult{
+		Contents: []ResourceContents{{URI: p.URI, MimeType: mimeType, Text: string(raw)}},
+	}, nil
+}
@@ -0,0 +1,135 @@
+package protomcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/jsonrpc2"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// newTestResourceServer returns an initialised MCP peer with a fixed
+// resource and two resource templates.
+func newTestResourceServer(t *testing.T) (*MCPServer, *testPeer) {
+	t.Helper()
+
+	s, p, _ := newTestResourceConn(t)
+	return s, p
+}
+
+// newTestResourceConn is newTestResourceServer also returning the server
+// end of the connection.
+func newTestResourceConn(t *testing.T) (*MCPServer, *testPeer, *jsonrpc2.Conn) {
+	t.Helper()
+
+	s := newTestMCPServer()
+	s.AddResource(&Resource{
+		URI:      "config://app",
+		Name:     "config",
+		MimeType: "text/plain",
+	}, NewMethodHandler(func(context.Context, *emptypb.Empty) (*wrapperspb.StringValue, error) {
+		return wrapperspb.String("debug"), nil
+	}))
+	s.AddResourceTemplate(&ResourceTemplate{
+		URITemplate: "users://{value}",
+		Name:        "user",
+		Description: "A user.",
+	}, NewMethodHandler(func(_ context.Context, req *wrapperspb.UInt32Value) (*wrapperspb.UInt32Value, error) {
+		if req.Value == 0 {
+			return nil, NewError(CodeResourceNotFound, "no such user")
+		}
+		return req, nil
+	}))
+	s.AddResourceTemplate(&ResourceTemplate{
+		URITemplate: "bad://{nope}",
+		Name:        "bad",
+	}, NewMethodHandler(func(_ context.Context, req *emptypb.Empty) (*emptypb.Empty, error) {
+		return req, nil
+	}))
+
+	p, conn := newTestConn(t, s)
+	p.initialize(t)
+	return s, p, conn
+}
+
+func TestMCPServerAddResource(t *testing.T) {
+	s, _ := newTestResourceServer(t)
+
+	if caps := s.Capabilities(); caps.Resources == nil {
+		t.Error("resources capability not advertised")
+	}
+	if n := len(s.Resources()); n != 1 {
+		t.Errorf("Resources() = %d, want 1", n)
+	}
+	if n := len(s.ResourceTemplates()); n != 2 {
+		t.Errorf("ResourceTemplates() = %d, want 2", n)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("invalid template accepted")
+		}
+	}()
+	s.AddResourceTemplate(&ResourceTemplate{URITemplate: "users://{id"}, NewMethodHandler(
+		func(_ context.Context, req *emptypb.Empty) (*emptypb.Empty, error) {
+			return req, nil
+		}))
+}
+
+func TestMCPServerListResources(t *testing.T) {
+	_, p := newTestResourceServer(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"resources/list"}`)
+	resp.assertResult(t, "1", `{"resources":[{"uri":"config://app","name":"config","mimeType":"text/plain"}]}`)
+
+	resp = p.call(t, `{"jsonrpc":"2.0","id":2,"method":"resources/templates/list"}`)
+	resp.assertResult(t, "2", `{"resourceTemplates":[`+
+		`{"uriTemplate":"users://{value}","name":"user","description":"A user."},`+
+		`{"uriTemplate":"bad://{nope}","name":"bad"}]}`)
+}
+
+func TestMCPServerReadResource(t *testing.T) {
+	t.Run("fixed", testMCPServerReadResourceFixed)
+	t.Run("template", testMCPServerReadResourceTemplate)
+	t.Run("not found", testMCPServerReadResourceNotFound)
+	t.Run("invalid variable", testMCPServerReadResourceInvalid)
+}
+
+func testMCPServerReadResourceFixed(t *testing.T) {
+	_, p := newTestResourceServer(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"config://app"}}`)
+	resp.assertResult(t, "1", `{"contents":[{"uri":"config://app","mimeType":"text/plain","text":"\"debug\""}]}`)
+}
+
+func testMCPServerReadResourceTemplate(t *testing.T) {
+	_, p := newTestResourceServer(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"users://42"}}`)
+	resp.assertResult(t, "1", `{"contents":[{"uri":"users://42","mimeType":"application/json","text":"42"}]}`)
+}
+
+func testMCPServerReadResourceNotFound(t *testing.T) {
+	_, p := newTestResourceServer(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"nope://x"}}`)
+	resp.assertError(t, "1", CodeResourceNotFound)
+
+	resp = p.call(t, `{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"users://0"}}`)
+	resp.assertError(t, "2", CodeResourceNotFound)
+}
+
+func testMCPServerReadResourceInvalid(t *testing.T) {
+	_, p := newTestResourceServer(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"users://x"}}`)
+	resp.assertError(t, "1", CodeInvalidParams)
+
+	resp = p.call(t, `{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"bad://x"}}`)
+	resp.assertError(t, "2", CodeInvalidParams)
+
+	resp = p.call(t, `{"jsonrpc":"2.0","id":3,"method":"resources/read"}`)
+	resp.assertError(t, "3", CodeInvalidParams)
+}
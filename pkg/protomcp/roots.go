@@ -0,0 +1,98 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+)
+
+// MCP roots request and notification.
+const (
+	MethodRootsList        = "roots/list"
+	MethodRootsListChanged = "notifications/roots/list_changed"
+)
+
+// Root is a filesystem root exposed by the client, scoping the files the
+// server may operate on.
+type Root struct {
+	// URI is the file:// URI of the root.
+	URI string `json:"uri"`
+	// Name optionally describes the root.
+	Name string `json:"name,omitempty"`
+}
+
+// ListRootsResult is the result of roots/list.
+type ListRootsResult struct {
+	Roots []Root `json:"roots"`
+}
+
+// Roots returns the roots exposed by the client, requesting them with
+// roots/list the first time and after the client notifies they changed.
+// It fails with ErrNotSupportedByClient if the client doesn't expose
+// roots.
+func (s *Session) Roots(ctx context.Context) ([]Root, error) {
+	if s.ClientCapabilities().Roots == nil {
+		return nil, ErrNotSupportedByClient
+	}
+
+	roots, gen, ok := s.cachedRoots()
+	if ok {
+		return roots, nil
+	}
+
+	var out ListRootsResult
+	if err := s.call(ctx, MethodRootsList, nil, &out); err != nil {
+		return nil, err
+	}
+	s.setRoots(out.Roots, gen)
+	return slices.Clone(out.Roots), nil
+}
+
+// cachedRoots returns a copy of the roots of the client, if known, and
+// the generation of the cache.
+func (s *Session) cachedRoots() ([]Root, uint64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.roots), s.rootsGen, s.roots != nil
+}
+
+// setRoots caches the roots of the client unless they changed since
+// requested.
+func (s *Session) setRoots(roots []Root, gen uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.rootsGen == gen {
+		s.roots = append([]Root{}, roots...)
+	}
+}
+
+// resetRoots discards the cached roots of the client.
+func (s *Session) resetRoots() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.roots = nil
+	s.rootsGen++
+}
+
+// RootsFromContext returns the roots exposed by the client of the session
+// of a request. See Session.Roots.
+func RootsFromContext(ctx context.Context) ([]Root, error) {
+	sess, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, ErrNotSupportedByClient
+	}
+	return sess.Roots(ctx)
+}
+
+// handleRootsListChanged implements notifications/roots/list_changed. The
+// roots are requested again when next needed, as notifications are
+// handled before reading the next message.
+func handleRootsListChanged(ctx context.Context, _ json.RawMessage) (any, error) {
+	if sess, ok := SessionFromContext(ctx); ok {
+		sess.resetRoots()
+	}
+	return nil, nil
+}
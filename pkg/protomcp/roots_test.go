@@ -0,0 +1,81 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// newTestRootsPeer returns an MCP peer initialised with the given client
+// capabilities, and a method returning the roots of the client.
+func newTestRootsPeer(t *testing.T, caps string) *testPeer {
+	t.Helper()
+
+	s := newTestMCPServer()
+	s.Dispatcher().HandleFunc("roots", func(ctx context.Context, _ json.RawMessage) (any, error) {
+		return RootsFromContext(ctx)
+	})
+
+	p := newTestPeer(t, s)
+	p.initializeWith(t, caps)
+	return p
+}
+
+// getRoots calls the roots method, answering the roots/list request
+// expected with the given result.
+func (p *testPeer) getRoots(t *testing.T, id, callID, result string) *testResponse {
+	t.Helper()
+
+	p.send(t, `{"jsonrpc":"2.0","id":`+id+`,"method":"roots"}`)
+	assertRequest(t, p.recv(t), callID, MethodRootsList, "")
+	p.send(t, `{"jsonrpc":"2.0","id":`+callID+`,"result":`+result+`}`)
+	return p.recvResponse(t)
+}
+
+// testRoots is the result of roots/list used by the tests.
+const testRoots = `{"roots":[{"uri":"file:///home/user/project","name":"project"}]}`
+
+func TestSessionRoots(t *testing.T) {
+	t.Run("cached", testSessionRootsCached)
+	t.Run("list changed", testSessionRootsListChanged)
+	t.Run("empty", testSessionRootsEmpty)
+	t.Run("not supported", testSessionRootsNotSupported)
+}
+
+func testSessionRootsCached(t *testing.T) {
+	p := newTestRootsPeer(t, `{"roots":{}}`)
+
+	resp := p.getRoots(t, "1", "1", testRoots)
+	resp.assertResult(t, "1", `[{"uri":"file:///home/user/project","name":"project"}]`)
+
+	resp = p.call(t, `{"jsonrpc":"2.0","id":2,"method":"roots"}`)
+	resp.assertResult(t, "2", `[{"uri":"file:///home/user/project","name":"project"}]`)
+}
+
+func testSessionRootsListChanged(t *testing.T) {
+	p := newTestRootsPeer(t, `{"roots":{"listChanged":true}}`)
+
+	resp := p.getRoots(t, "1", "1", testRoots)
+	resp.assertResult(t, "1", `[{"uri":"file:///home/user/project","name":"project"}]`)
+
+	p.send(t, `{"jsonrpc":"2.0","method":"notifications/roots/list_changed"}`)
+	resp = p.getRoots(t, "2", "2", `{"roots":[{"uri":"file:///tmp"}]}`)
+	resp.assertResult(t, "2", `[{"uri":"file:///tmp"}]`)
+}
+
+func testSessionRootsEmpty(t *testing.T) {
+	p := newTestRootsPeer(t, `{"roots":{}}`)
+
+	resp := p.getRoots(t, "1", "1", `{"roots":[]}`)
+	resp.assertResult(t, "1", `[]`)
+
+	resp = p.call(t, `{"jsonrpc":"2.0","id":2,"method":"roots"}`)
+	resp.assertResult(t, "2", `[]`)
+}
+
+func testSessionRootsNotSupported(t *testing.T) {
+	p := newTestRootsPeer(t, `{}`)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"roots"}`)
+	resp.assertError(t, "1", CodeInternalError)
+}
@@ -0,0 +1,94 @@
+package protomcp
+
+import (
+	"context"
+	"errors"
+)
+
+// MethodSamplingCreateMessage is the request asking the client to sample
+// an LLM.
+const MethodSamplingCreateMessage = "sampling/createMessage"
+
+// ErrNotSupportedByClient is returned when a request is sent to a client
+// that hasn't advertised the required capability.
+var ErrNotSupportedByClient = errors.New("not supported by the client")
+
+// Values of CreateMessageParams.IncludeContext.
+const (
+	IncludeContextNone       = "none"
+	IncludeContextThisServer = "thisServer"
+	IncludeContextAllServers = "allServers"
+)
+
+// Stop reasons of CreateMessageResult.
+const (
+	StopReasonEndTurn      = "endTurn"
+	StopReasonStopSequence = "stopSequence"
+	StopReasonMaxTokens    = "maxTokens"
+)
+
+// SamplingMessage is a message sent to, or received from, an LLM.
+type SamplingMessage struct {
+	Role    string  `json:"role"`
+	Content Content `json:"content"`
+}
+
+// ModelHint suggests a model to the client.
+type ModelHint struct {
+	// Name is the full or partial name of a model.
+	Name string `json:"name,omitempty"`
+}
+
+// ModelPreferences guide the choice of model by the client. Priorities
+// range from 0 to 1.
+type ModelPreferences struct {
+	Hints                []ModelHint `json:"hints,omitempty"`
+	CostPriority         float64     `json:"costPriority,omitempty"`
+	SpeedPriority        float64     `json:"speedPriority,omitempty"`
+	IntelligencePriority float64     `json:"intelligencePriority,omitempty"`
+}
+
+// CreateMessageParams are the params of sampling/createMessage.
+type CreateMessageParams struct {
+	ModelPreferences *ModelPreferences `json:"modelPreferences,omitempty"`
+	Temperature      *float64          `json:"temperature,omitempty"`
+	Metadata         map[string]any    `json:"metadata,omitempty"`
+	SystemPrompt     string            `json:"systemPrompt,omitempty"`
+	IncludeContext   string            `json:"includeContext,omitempty"`
+	Messages         []SamplingMessage `json:"messages"`
+	StopSequences    []string          `json:"stopSequences,omitempty"`
+	MaxTokens        int               `json:"maxTokens"`
+}
+
+// CreateMessageResult is the result of sampling/createMessage.
+type CreateMessageResult struct {
+	Role       string  `json:"role"`
+	Model      string  `json:"model"`
+	StopReason string  `json:"stopReason,omitempty"`
+	Content    Content `json:"content"`
+}
+
+// CreateMessage asks the client to sample an LLM, waiting for the
+// message generated. It fails with ErrNotSupportedByClient if the client
+// doesn't support sampling.
+func (s *Session) CreateMessage(ctx context.Context, params *CreateMessageParams) (*CreateMessageResult, error) {
+	if s.ClientCapabilities().Sampling == nil {
+		return nil, ErrNotSupportedByClient
+	}
+
+	var out CreateMessageResult
+	if err := s.call(ctx, MethodSamplingCreateMessage, params, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateMessage asks the client of the session of a request to sample an
+// LLM. See Session.CreateMessage.
+func CreateMessage(ctx context.Context, params *CreateMessageParams) (*CreateMessageResult, error) {
+	sess, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, ErrNotSupportedByClient
+	}
+	return sess.CreateMessage(ctx, params)
+}
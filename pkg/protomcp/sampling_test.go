@@ -0,0 +1,109 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// newTestClientPeer returns an MCP peer initialised with the given client
+// capabilities, and methods making requests to the client.
+func newTestClientPeer(t *testing.T, caps string) *testPeer {
+	t.Helper()
+
+	s := newTestMCPServer()
+	s.Dispatcher().HandleFunc("summarise", func(ctx context.Context, _ json.RawMessage) (any, error) {
+		return CreateMessage(ctx, &CreateMessageParams{
+			Messages:  []SamplingMessage{{Role: RoleUser, Content: TextContent("Summarise this.")}},
+			MaxTokens: 100,
+		})
+	})
+	s.Dispatcher().HandleFunc("confirm", func(ctx context.Context, _ json.RawMessage) (any, error) {
+		return Elicit(ctx, &ElicitParams{
+			Message:         "Are you sure?",
+			RequestedSchema: json.RawMessage(`{"type":"object"}`),
+		})
+	})
+
+	p := newTestPeer(t, s)
+	p.initializeWith(t, caps)
+	return p
+}
+
+// assertRequest checks a raw message is the given request.
+func assertRequest(t *testing.T, raw json.RawMessage, id, method, params string) {
+	t.Helper()
+
+	var m struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("invalid request %s: %v", raw, err)
+	}
+	if string(m.ID) != id || m.Method != method || string(m.Params) != params {
+		t.Errorf("got %s, want request %s %q with params %s", raw, id, method, params)
+	}
+}
+
+// summariseRequest is the sampling/createMessage request sent by the
+// summarise method.
+const summariseRequest = `{"messages":[{"role":"user","content":{"type":"text","text":"Summarise this."}}],` +
+	`"maxTokens":100}`
+
+func TestSessionCreateMessage(t *testing.T) {
+	t.Run("sampled", testSessionCreateMessageSampled)
+	t.Run("client error", testSessionCreateMessageClientError)
+	t.Run("not supported", testSessionCreateMessageNotSupported)
+	t.Run("cancelled", testSessionCreateMessageCancelled)
+	t.Run("no session", testSessionCreateMessageNoSession)
+}
+
+func testSessionCreateMessageSampled(t *testing.T) {
+	p := newTestClientPeer(t, `{"sampling":{}}`)
+
+	p.send(t, `{"jsonrpc":"2.0","id":1,"method":"summarise"}`)
+	assertRequest(t, p.recv(t), "1", MethodSamplingCreateMessage, summariseRequest)
+
+	p.send(t, `{"jsonrpc":"2.0","id":1,"result":{"role":"assistant","model":"m1",`+
+		`"stopReason":"endTurn","content":{"type":"text","text":"Done."}}}`)
+	p.recvResponse(t).assertResult(t, "1", `{"role":"assistant","model":"m1",`+
+		`"stopReason":"endTurn","content":{"type":"text","text":"Done."}}`)
+}
+
+func testSessionCreateMessageClientError(t *testing.T) {
+	p := newTestClientPeer(t, `{"sampling":{}}`)
+
+	p.send(t, `{"jsonrpc":"2.0","id":1,"method":"summarise"}`)
+	assertRequest(t, p.recv(t), "1", MethodSamplingCreateMessage, summariseRequest)
+
+	p.send(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-1,"message":"rejected by the user"}}`)
+	p.recvResponse(t).assertError(t, "1", -1)
+}
+
+func testSessionCreateMessageNotSupported(t *testing.T) {
+	p := newTestClientPeer(t, `{}`)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"summarise"}`)
+	resp.assertError(t, "1", CodeInternalError)
+}
+
+func testSessionCreateMessageCancelled(t *testing.T) {
+	p := newTestClientPeer(t, `{"sampling":{}}`)
+
+	p.send(t, `{"jsonrpc":"2.0","id":"a","method":"summarise"}`)
+	assertRequest(t, p.recv(t), "1", MethodSamplingCreateMessage, summariseRequest)
+
+	p.send(t, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"a"}}`)
+	assertNotification(t, p.recv(t), MethodCancelled, `{"reason":"request cancelled by client","requestId":1}`)
+	p.recvResponse(t).assertError(t, `"a"`, CodeInternalError)
+}
+
+func testSessionCreateMessageNoSession(t *testing.T) {
+	_, err := CreateMessage(context.Background(), &CreateMessageParams{})
+	if !errors.Is(err, ErrNotSupportedByClient) {
+		t.Errorf("got %v, want %v", err, ErrNotSupportedByClient)
+	}
+}
@@ -0,0 +1,194 @@
+package protomcp
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+var _ http.Handler = (*Server)(nil)
+
+// Server serves the JSON-RPC endpoint, the MCP endpoint and the REST
+// routes of a service on a single http.Handler, over HTTP/2 with TLS or,
+// optionally, cleartext HTTP/2 (h2c).
+//
+// Handlers are mounted before serving. The exported fields must not be
+// changed once the server is serving.
+type Server struct {
+	mux *http.ServeMux
+	srv *http.Server
+
+	// TLSConfig enables TLS, with HTTP/2 negotiated by ALPN. Its
+	// certificates are used when serving.
+	TLSConfig *tls.Config
+
+	// Addr is the TCP address to listen on, ":https" or ":http" if
+	// empty.
+	Addr string
+
+	closers []io.Closer // handlers closed on shutdown
+
+	// ReadTimeout, ReadHeaderTimeout, WriteTimeout and IdleTimeout are
+	// those of http.Server. WriteTimeout also bounds event streams.
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	// MaxHeaderBytes is the maximum size of request headers. Zero means
+	// http.DefaultMaxHeaderBytes.
+	MaxHeaderBytes int
+
+	// H2C enables cleartext HTTP/2 when serving without TLS, with prior
+	// knowledge or by upgrading HTTP/1.1 connections.
+	H2C bool
+
+	mu sync.Mutex
+}
+
+// NewServer returns a Server without handlers.
+func NewServer() *Server {
+	return &Server{mux: http.NewServeMux()}
+}
+
+// HandleJSONRPC mounts the JSON-RPC endpoint of server, usually a
+// Dispatcher, on pattern.
+func (s *Server) HandleJSONRPC(pattern string, server ConnServer) {
+	s.mux.Handle(pattern, NewJSONRPCHandler(server))
+}
+
+// HandleMCP mounts the Streamable HTTP endpoint of server, usually an
+// MCPServer, on pattern. Its sessions are terminated on shutdown.
+func (s *Server) HandleMCP(pattern string, server ConnServer) {
+	s.handleCloser(pattern, NewStreamableHTTPHandler(server))
+}
+
+// HandleWebSocket mounts the WebSocket endpoint of server, a Dispatcher
+// or an MCPServer, on pattern. Its connections are closed on shutdown.
+func (s *Server) HandleWebSocket(pattern string, server ConnServer) {
+	s.handleCloser(pattern, NewWebSocketHandler(server))
+}
+
+// closeHandler is an http.Handler holding sessions or connections to close
+// on shutdown.
+type closeHandler interface {
+	http.Handler
+	io.Closer
+}
+
+// handleCloser mounts a handler to be closed on shutdown.
+func (s *Server) handleCloser(pattern string, h closeHandler) {
+	s.mux.Handle(pattern, h)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closers = append(s.closers, h)
+}
+
+// Handle mounts a handler, such as REST routes, on pattern.
+func (s *Server) Handle(pattern string, h http.Handler) {
+	s.mux.Handle(pattern, h)
+}
+
+// ServeHTTP implements http.Handler, routing requests to the mounted
+// handlers.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe listens on Addr and serves until shut down, when it
+// returns http.ErrServerClosed.
+func (s *Server) ListenAndServe() error {
+	addr := s.Addr
+	if addr == "" {
+		addr = ":http"
+		if s.TLSConfig != nil {
+			addr = ":https"
+		}
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve serves the connections accepted by l until shut down, when it
+// returns http.ErrServerClosed. It can be called for several listeners.
+func (s *Server) Serve(l net.Listener) error {
+	srv := s.httpServer()
+	if s.TLSConfig != nil {
+		return srv.ServeTLS(l, "", "")
+	}
+	return srv.Serve(l)
+}
+
+// httpServer returns the http.Server, created on first use.
+func (s *Server) httpServer() *http.Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.srv == nil {
+		s.srv = s.newHTTPServer()
+		s.srv.RegisterOnShutdown(s.closeHandlers)
+	}
+	return s.srv
+}
+
+// newHTTPServer returns an http.Server with the settings of s.
+func (s *Server) newHTTPServer() *http.Server {
+	var h http.Handler = s.mux
+	if s.H2C && s.TLSConfig == nil {
+		h = h2c.NewHandler(h, &http2.Server{})
+	}
+
+	var cfg *tls.Config
+	if s.TLSConfig != nil {
+		cfg = s.TLSConfig.Clone()
+	}
+
+	return &http.Server{
+		Addr:              s.Addr,
+		Handler:           h,
+		TLSConfig:         cfg,
+		ReadTimeout:       s.ReadTimeout,
+		ReadHeaderTimeout: s.ReadHeaderTimeout,
+		WriteTimeout:      s.WriteTimeout,
+		IdleTimeout:       s.IdleTimeout,
+		MaxHeaderBytes:    s.MaxHeaderBytes,
+	}
+}
+
+// Shutdown stops accepting connections, terminates the MCP sessions and
+// closes the WebSocket connections, then waits for the requests in
+// progress to complete until ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer().Shutdown(ctx)
+}
+
+// Close closes all connections immediately, and terminates the MCP
+// sessions.
+func (s *Server) Close() error {
+	s.closeHandlers()
+	return s.httpServer().Close()
+}
+
+// closeHandlers closes the handlers holding sessions or connections.
+func (s *Server) closeHandlers() {
+	s.mu.Lock()
+	closers := s.closers
+	s.mu.Unlock()
+
+	for _, c := range closers {
+		_ = c.Close()
+	}
+}
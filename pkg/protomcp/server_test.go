@@ -0,0 +1,230 @@
+package protomcp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/net/http2"
+)
+
+// testServer is a Server on the loopback interface and its client.
+type testServer struct {
+	s      *Server
+	client *http.Client
+	served chan error
+	url    string
+}
+
+// newTestServer returns a Server mounting a Dispatcher on /rpc and /ws,
+// an MCPServer on /mcp and a REST route on /v1/.
+func newTestServer() *Server {
+	s := NewServer()
+	s.HandleJSONRPC("/rpc", newTestDispatcher())
+	s.HandleMCP("/mcp", newTestHTTPServer())
+	s.HandleWebSocket("/ws", newTestDispatcher())
+	s.Handle("/v1/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.URL.Path)
+	}))
+	return s
+}
+
+// startTestServer serves s on the loopback interface, using the client
+// transport given.
+func startTestServer(t *testing.T, s *Server, tr http.RoundTripper) *testServer {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scheme := "http://"
+	if s.TLSConfig != nil {
+		scheme = "https://"
+	}
+	ts := &testServer{
+		s:      s,
+		client: &http.Client{Transport: tr, Timeout: testTimeout},
+		served: make(chan error, 1),
+		url:    scheme + l.Addr().String(),
+	}
+	go func() {
+		ts.served <- s.Serve(l)
+	}()
+
+	t.Cleanup(func() { _ = s.Close() })
+	return ts
+}
+
+// newTestTLSServer serves a test Server over HTTP/2 with TLS.
+func newTestTLSServer(t *testing.T) *testServer {
+	t.Helper()
+
+	cfg, pool := newTestTLSConfig(t)
+	s := newTestServer()
+	s.TLSConfig = cfg
+	return startTestServer(t, s, &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS13},
+		ForceAttemptHTTP2: true,
+	})
+}
+
+// newTestH2CServer serves a test Server over cleartext HTTP/2.
+func newTestH2CServer(t *testing.T) *testServer {
+	t.Helper()
+
+	s := newTestServer()
+	s.H2C = true
+	return startTestServer(t, s, &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	})
+}
+
+// do sends a request to a path of the server.
+func (ts *testServer) do(t *testing.T, method, path, body string) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, ts.url+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Content-Type", contentTypeJSON)
+
+	resp, err := ts.client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, strings.TrimSpace(string(b))
+}
+
+// assertRoutes checks the handlers mounted by newTestServer are served
+// over HTTP/2.
+func (ts *testServer) assertRoutes(t *testing.T) {
+	t.Helper()
+
+	for _, tc := range []struct{ path, body, want string }{
+		{"/rpc", `{"jsonrpc":"2.0","id":1,"method":"test.Echo/Echo","params":{"a":"b"}}`,
+			`{"id":1,"result":{"a":"b"},"jsonrpc":"2.0"}`},
+		{"/mcp", `{"jsonrpc":"2.0","id":1,"method":"ping"}`, "missing " + HeaderSessionID},
+		{"/v1/users", "", "/v1/users"},
+	} {
+		resp, body := ts.do(t, http.MethodPost, tc.path, tc.body)
+		if resp.ProtoMajor != 2 || body != tc.want {
+			t.Errorf("%s: got %s %s, want HTTP/2 %s", tc.path, resp.Proto, body, tc.want)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	t.Run("tls", testServerTLS)
+	t.Run("h2c", testServerH2C)
+	t.Run("mcp", testServerMCP)
+	t.Run("websocket", testServerWebSocket)
+	t.Run("max header bytes", testServerMaxHeaderBytes)
+	t.Run("shutdown", testServerShutdown)
+}
+
+func testServerTLS(t *testing.T) {
+	newTestTLSServer(t).assertRoutes(t)
+}
+
+func testServerH2C(t *testing.T) {
+	newTestH2CServer(t).assertRoutes(t)
+}
+
+func testServerMCP(t *testing.T) {
+	ts := newTestH2CServer(t)
+
+	resp, body := ts.do(t, http.MethodPost, "/mcp", initRequest)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(HeaderSessionID) == "" {
+		t.Errorf("initialize: got %s %s", resp.Status, body)
+	}
+}
+
+func testServerWebSocket(t *testing.T) {
+	ts := startTestServer(t, newTestServer(), http.DefaultTransport)
+
+	ws, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.url, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	defer func() { _ = ws.Close() }()
+	c := &testWebSocket{ws: ws}
+
+	c.send(t, `{"jsonrpc":"2.0","id":1,"method":"test.Echo/Echo"}`)
+	assertResponseID(t, c.recv(t), "1")
+
+	if err := ts.s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	c.assertClosed(t, websocket.CloseNormalClosure)
+}
+
+func testServerMaxHeaderBytes(t *testing.T) {
+	s := newTestServer()
+	s.MaxHeaderBytes = 1024
+	ts := startTestServer(t, s, http.DefaultTransport)
+
+	req, err := http.NewRequest(http.MethodGet, ts.url+"/v1/users", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Large", strings.Repeat("x", 8<<10))
+
+	resp, err := ts.client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("got %s, want %d", resp.Status, http.StatusRequestHeaderFieldsTooLarge)
+	}
+}
+
+func testServerShutdown(t *testing.T) {
+	ts := newTestTLSServer(t)
+
+	resp, _ := ts.do(t, http.MethodPost, "/mcp", initRequest)
+	req, err := http.NewRequest(http.MethodGet, ts.url+"/mcp", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", contentTypeEventStream)
+	req.Header.Set(HeaderSessionID, resp.Header.Get(HeaderSessionID))
+	stream, err := ts.client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = stream.Body.Close() }()
+	events := newTestEvents(t, stream)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	if err := ts.s.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	events.assertEnd(t)
+	if err := <-ts.served; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("serve: got %v, want %v", err, http.ErrServerClosed)
+	}
+}
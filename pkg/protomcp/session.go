@@ -0,0 +1,202 @@
+package protomcp
+
+import (
+	"context"
+	"sync"
+
+	"darvaza.org/core"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+var sessionKey = core.NewContextKey[*Session]("protomcp.session")
+
+// sessionState is the lifecycle stage of a Session.
+type sessionState int
+
+const (
+	// sessionNew sessions only accept initialize and ping.
+	sessionNew sessionState = iota
+	// sessionInitializing sessions have answered initialize and wait for
+	// the initialized notification.
+	sessionInitializing
+	// sessionReady sessions are in normal operation.
+	sessionReady
+)
+
+// Session is the state of an MCP connection, shared by all its requests.
+type Session struct {
+	conn       *jsonrpc2.Conn
+	requests   map[jsonrpc2.ID]context.CancelCauseFunc // in progress
+	clientCaps ClientCapabilities
+	clientInfo Implementation
+	version    string
+	logLevel   LoggingLevel // minimum sent, none if empty
+	roots      []Root       // cached, nil if unknown
+	calls      uint64       // requests sent to the client
+	rootsGen   uint64       // generation of the cached roots
+	state      sessionState
+	mu         sync.RWMutex
+}
+
+// WithSession returns a copy of ctx carrying s.
+func WithSession(ctx context.Context, s *Session) context.Context {
+	return sessionKey.WithValue(ctx, s)
+}
+
+// SessionFromContext returns the MCP session a request was received on.
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	s, ok := sessionKey.Get(ctx)
+	return s, ok && s != nil
+}
+
+// ProtocolVersion returns the negotiated MCP revision, or "" if the
+// session hasn't been initialised.
+func (s *Session) ProtocolVersion() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.version
+}
+
+// ClientInfo returns the implementation details reported by the client.
+func (s *Session) ClientInfo() Implementation {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.clientInfo
+}
+
+// ClientCapabilities returns the capabilities advertised by the client.
+func (s *Session) ClientCapabilities() ClientCapabilities {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.clientCaps
+}
+
+// Initialized tells if the client has completed the initialisation
+// handshake.
+func (s *Session) Initialized() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.state == sessionReady
+}
+
+// Conn returns the underlying JSON-RPC connection.
+func (s *Session) Conn() *jsonrpc2.Conn {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.conn
+}
+
+// setConn binds the session to its connection.
+func (s *Session) setConn(conn *jsonrpc2.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.conn = conn
+}
+
+// initialize records the outcome of the initialize request.
+func (s *Session) initialize(version string, params *InitializeParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != sessionNew {
+		return NewError(CodeInvalidRequest, "session already initialised")
+	}
+
+	s.version = version
+	s.clientInfo = params.ClientInfo
+	s.clientCaps = params.Capabilities
+	s.state = sessionInitializing
+	return nil
+}
+
+// ready handles the initialized notification.
+func (s *Session) ready() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == sessionInitializing {
+		s.state = sessionReady
+	}
+}
+
+// check tells if a method may be called in the current stage of the
+// lifecycle.
+func (s *Session) check(method string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.state != sessionNew {
+		return nil
+	}
+
+	switch method {
+	case MethodInitialize, MethodPing:
+		return nil
+	default:
+		return NewError(CodeInvalidRequest, "session not initialised, %s not allowed", method)
+	}
+}
+
+// startRequest records a request in progress, to be cancelled by the
+// client.
+func (s *Session) startRequest(id jsonrpc2.ID, cancel context.CancelCauseFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.requests == nil {
+		s.requests = make(map[jsonrpc2.ID]context.CancelCauseFunc)
+	}
+	s.requests[id] = cancel
+}
+
+// endRequest forgets a request once handled.
+func (s *Session) endRequest(id jsonrpc2.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.requests, id)
+}
+
+// cancelRequest cancels a request in progress, if any.
+func (s *Session) cancelRequest(id jsonrpc2.ID, cause error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cancel, ok := s.requests[id]; ok {
+		cancel(cause)
+	}
+}
+
+// call sends a request to the client and waits for its response. If ctx
+// is cancelled first the client is told to abandon the request.
+func (s *Session) call(ctx context.Context, method string, params, result any) error {
+	conn := s.Conn()
+	if conn == nil {
+		return jsonrpc2.ErrClosed
+	}
+
+	id := s.newCallID()
+	err := conn.Call(ctx, method, params, result, jsonrpc2.PickID(id))
+	if err != nil && ctx.Err() != nil {
+		_ = conn.Notify(context.WithoutCancel(ctx), MethodCancelled, &CancelledParams{
+			Reason:    context.Cause(ctx).Error(),
+			RequestID: id,
+		})
+	}
+	return err
+}
+
+// newCallID returns the ID of a new request to the client.
+func (s *Session) newCallID() jsonrpc2.ID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	return jsonrpc2.ID{Num: s.calls}
+}
@@ -0,0 +1,70 @@
+package protomcp
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSessionCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		state  sessionState
+		ok     bool
+	}{
+		{"initialize when new", MethodInitialize, sessionNew, true},
+		{"ping when new", MethodPing, sessionNew, true},
+		{"other when new", "tools/list", sessionNew, false},
+		{"initialized when initializing", MethodInitialized, sessionInitializing, true},
+		{"other when initializing", "tools/list", sessionInitializing, true},
+		{"other when ready", "tools/list", sessionReady, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Session{state: tc.state}
+			if err := s.check(tc.method); (err == nil) != tc.ok {
+				t.Errorf("check(%q) = %v, want ok %v", tc.method, err, tc.ok)
+			}
+		})
+	}
+}
+
+func TestSessionInitialize(t *testing.T) {
+	s := &Session{}
+	params := &InitializeParams{
+		ClientInfo: Implementation{Name: "client", Version: "1"},
+	}
+
+	if err := s.initialize("2025-06-18", params); err != nil {
+		t.Fatalf("initialize() = %v", err)
+	}
+	if s.Initialized() {
+		t.Error("Initialized() before the initialized notification")
+	}
+	if err := s.initialize("2025-06-18", params); err == nil {
+		t.Error("initialize() accepted twice")
+	}
+
+	s.ready()
+	if !s.Initialized() {
+		t.Error("Initialized() = false after the initialized notification")
+	}
+	if got := s.ClientInfo().Name; got != "client" {
+		t.Errorf("ClientInfo().Name = %q, want %q", got, "client")
+	}
+	if got := s.ProtocolVersion(); got != "2025-06-18" {
+		t.Errorf("ProtocolVersion() = %q, want %q", got, "2025-06-18")
+	}
+}
+
+func TestSessionFromContext(t *testing.T) {
+	if _, ok := SessionFromContext(context.Background()); ok {
+		t.Error("SessionFromContext() found a session in an empty context")
+	}
+
+	s := &Session{}
+	if got, ok := SessionFromContext(WithSession(context.Background(), s)); !ok || got != s {
+		t.Errorf("SessionFromContext() = %p, %v, want %p", got, ok, s)
+	}
+}
@@ -0,0 +1,76 @@
+package protomcp
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+)
+
+// sseWriter writes Server-Sent Events to an HTTP response.
+type sseWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// newSSEWriter starts an event stream as the response to an HTTP request.
+func newSSEWriter(w http.ResponseWriter) *sseWriter {
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	sw := &sseWriter{w: w, rc: http.NewResponseController(w)}
+	_ = sw.rc.Flush()
+	return sw
+}
+
+// writeEvent sends an event of the given type, flushing it. The id is
+// optional.
+func (sw *sseWriter) writeEvent(id, event string, data []byte) error {
+	buf := make([]byte, 0, len(id)+len(event)+len(data)+24)
+	if id != "" {
+		buf = append(buf, "id: "+id+"\n"...)
+	}
+	buf = append(buf, "event: "+event+"\n"...)
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf = append(buf, "data: "...)
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+	buf = append(buf, '\n')
+
+	if _, err := sw.w.Write(buf); err != nil {
+		return err
+	}
+	return sw.rc.Flush()
+}
+
+// send writes the events of a stream as messages, telling if the stream
+// has ended or can't be written to.
+func (sw *sseWriter) send(events ...httpEvent) bool {
+	for _, ev := range events {
+		if sw.writeEvent(ev.id(), "message", ev.data) != nil || ev.end {
+			return true
+		}
+	}
+	return false
+}
+
+// relay writes the events of a sink until the stream ends or is taken
+// over, or the request or the session ends.
+func (sw *sseWriter) relay(ctx context.Context, k *httpSink, closed <-chan struct{}) {
+	for {
+		select {
+		case ev := <-k.events:
+			if sw.send(ev) {
+				return
+			}
+		case <-k.done:
+			return
+		case <-ctx.Done():
+			return
+		case <-closed:
+			return
+		}
+	}
+}
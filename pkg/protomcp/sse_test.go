@@ -0,0 +1,29 @@
+package protomcp
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSSEWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	sw := newSSEWriter(rec)
+
+	if err := sw.writeEvent("1-1", "message", []byte(`{"a":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sw.writeEvent("", "endpoint", []byte("one\ntwo")); err != nil {
+		t.Fatal(err)
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != contentTypeEventStream {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	want := "id: 1-1\nevent: message\ndata: {\"a\":1}\n\nevent: endpoint\ndata: one\ndata: two\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if !rec.Flushed {
+		t.Error("not flushed")
+	}
+}
@@ -0,0 +1,307 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+var (
+	_ http.Handler          = (*SSEHandler)(nil)
+	_ jsonrpc2.ObjectStream = (*sseStream)(nil)
+)
+
+// SSESessionParam is the query parameter identifying the session of the
+// POST requests of the HTTP+SSE transport.
+const SSESessionParam = "sessionId"
+
+// SSEHandler serves MCP over the HTTP+SSE transport of the 2024-11-05
+// revision, superseded by Streamable HTTP but still used by older
+// clients.
+//
+// Clients open a session with a GET request, whose response is a stream
+// of Server-Sent Events. Its first event, of type endpoint, gives the URL
+// clients POST their messages to, identifying the session by a query
+// parameter. All the messages of the server follow on the event stream,
+// and the session ends with it.
+//
+// The same handler serves both the GET and the POST requests, so it can
+// be mounted on the paths of both endpoints.
+//
+// The exported fields must not be changed once the handler is serving.
+type SSEHandler struct {
+	server   ConnServer
+	sessions map[string]*sseSession
+
+	// CheckOrigin tells if a request is accepted from the origin of a
+	// browser, preventing DNS rebinding attacks. Nil accepts requests
+	// without Origin, and those from the same host or from localhost.
+	CheckOrigin func(r *http.Request) bool
+
+	endpoint string
+
+	// MaxBodySize is the maximum size of the body of POST requests.
+	// Zero means DefaultMaxBodySize.
+	MaxBodySize int64
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// sseSession is an MCP session served by an SSEHandler.
+type sseSession struct {
+	stream *sseStream
+	conn   *jsonrpc2.Conn
+	id     string
+}
+
+// NewSSEHandler returns an SSEHandler serving sessions of server, usually
+// an MCPServer. endpoint is the URL, usually a path, clients are told to
+// POST their messages to.
+func NewSSEHandler(server ConnServer, endpoint string) *SSEHandler {
+	return &SSEHandler{
+		server:   server,
+		sessions: make(map[string]*sseSession),
+		endpoint: endpoint,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !checkOrigin(r, h.CheckOrigin) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.serveGet(w, r)
+	case http.MethodPost:
+		h.servePost(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Close terminates all sessions, and rejects new ones.
+func (h *SSEHandler) Close() error {
+	h.mu.Lock()
+	h.closed = true
+	sessions := slices.Collect(maps.Values(h.sessions))
+	clear(h.sessions)
+	h.mu.Unlock()
+
+	for _, sess := range sessions {
+		_ = sess.conn.Close()
+	}
+	return nil
+}
+
+// newSession starts a new session.
+func (h *SSEHandler) newSession() (*sseSession, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	stream := newSSEStream()
+	sess := &sseSession{
+		id:     id,
+		stream: stream,
+		conn:   h.server.ServeConn(context.Background(), stream),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		_ = sess.conn.Close()
+		return nil, jsonrpc2.ErrClosed
+	}
+
+	h.sessions[id] = sess
+	return sess, nil
+}
+
+// endSession terminates a session.
+func (h *SSEHandler) endSession(sess *sseSession) {
+	h.mu.Lock()
+	delete(h.sessions, sess.id)
+	h.mu.Unlock()
+
+	_ = sess.conn.Close()
+}
+
+// endpointURL returns the URL of the POST endpoint of a session.
+func (h *SSEHandler) endpointURL(id string) (string, error) {
+	u, err := url.Parse(h.endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	q.Set(SSESessionParam, id)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// serveGet starts a session, sending its messages as events until the
+// request or the session ends.
+func (h *SSEHandler) serveGet(w http.ResponseWriter, r *http.Request) {
+	sess, err := h.newSession()
+	if err != nil {
+		http.Error(w, "session unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	defer h.endSession(sess)
+
+	endpoint, err := h.endpointURL(sess.id)
+	if err != nil {
+		http.Error(w, "invalid endpoint", http.StatusInternalServerError)
+		return
+	}
+
+	sw := newSSEWriter(w)
+	if sw.writeEvent("", "endpoint", []byte(endpoint)) == nil {
+		sess.stream.relay(r.Context(), sw)
+	}
+}
+
+// servePost passes the message of a POST request to its session. The
+// responses are sent on the event stream of the session.
+func (h *SSEHandler) servePost(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+
+	body, ok := readBody(w, r, h.MaxBodySize)
+	if !ok {
+		return
+	}
+	if !json.Valid(body) {
+		http.Error(w, "invalid message", http.StatusBadRequest)
+		return
+	}
+
+	err := sess.stream.push(r.Context(), body)
+	switch {
+	case errors.Is(err, jsonrpc2.ErrClosed):
+		http.Error(w, "session closed", http.StatusNotFound)
+	case err != nil:
+		// the request was cancelled before the session took the message
+		http.Error(w, "message not delivered", http.StatusServiceUnavailable)
+	default:
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// session returns the session of a POST request, replying with an error
+// if missing or unknown.
+func (h *SSEHandler) session(w http.ResponseWriter, r *http.Request) (*sseSession, bool) {
+	id := r.URL.Query().Get(SSESessionParam)
+	if id == "" {
+		http.Error(w, "missing "+SSESessionParam, http.StatusBadRequest)
+		return nil, false
+	}
+
+	h.mu.Lock()
+	sess, ok := h.sessions[id]
+	h.mu.Unlock()
+
+	if !ok {
+		http.Error(w, "session not found", http.StatusNotFound)
+	}
+	return sess, ok
+}
+
+// sseStream is the jsonrpc2.ObjectStream of an MCP session over HTTP+SSE.
+// The messages of the client are pushed by POST requests, and those of
+// the server wait for the event stream of the session.
+type sseStream struct {
+	in        chan json.RawMessage
+	out       chan json.RawMessage
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// newSSEStream returns a new sseStream.
+func newSSEStream() *sseStream {
+	return &sseStream{
+		in:     make(chan json.RawMessage),
+		out:    make(chan json.RawMessage, httpSinkSize),
+		closed: make(chan struct{}),
+	}
+}
+
+// ReadObject implements jsonrpc2.ObjectStream.
+func (s *sseStream) ReadObject(v any) error {
+	select {
+	case raw := <-s.in:
+		return decodeMessage(raw, v)
+	case <-s.closed:
+		return io.EOF
+	}
+}
+
+// WriteObject implements jsonrpc2.ObjectStream, waiting for the event
+// stream to take the message.
+func (s *sseStream) WriteObject(obj any) error {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case s.out <- raw:
+		return nil
+	case <-s.closed:
+		return jsonrpc2.ErrClosed
+	}
+}
+
+// Close implements jsonrpc2.ObjectStream.
+func (s *sseStream) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+	return nil
+}
+
+// push passes a message of the client to the session.
+func (s *sseStream) push(ctx context.Context, raw json.RawMessage) error {
+	select {
+	case s.in <- raw:
+		return nil
+	case <-s.closed:
+		return jsonrpc2.ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// relay writes the messages of the server as events until the request or
+// the session ends, or a write fails.
+func (s *sseStream) relay(ctx context.Context, sw *sseWriter) {
+	for {
+		select {
+		case raw := <-s.out:
+			if sw.writeEvent("", "message", raw) != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		case <-s.closed:
+			return
+		}
+	}
+}
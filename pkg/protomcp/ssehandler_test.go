@@ -0,0 +1,263 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// testSSEClient is a client of the HTTP+SSE transport.
+type testSSEClient struct {
+	ts       *httptest.Server
+	h        *SSEHandler
+	events   *testEvents
+	endpoint string
+}
+
+// newTestSSEClient opens a session of an SSEHandler serving s.
+func newTestSSEClient(t *testing.T, s ConnServer) *testSSEClient {
+	t.Helper()
+
+	h := NewSSEHandler(s, "/messages")
+	ts := httptest.NewServer(h)
+	t.Cleanup(func() {
+		_ = h.Close()
+		ts.Close()
+	})
+
+	c := &testSSEClient{ts: ts, h: h}
+	c.events = newTestEvents(t, c.do(t, http.MethodGet, "", ""))
+	c.endpoint = c.events.next(t)
+	return c
+}
+
+// newTestSSESession opens an initialised session of an SSEHandler.
+func newTestSSESession(t *testing.T, s *MCPServer) *testSSEClient {
+	t.Helper()
+
+	c := newTestSSEClient(t, s)
+	c.post(t, `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{`+
+		`"protocolVersion":"2024-11-05","capabilities":{},`+
+		`"clientInfo":{"name":"test","version":"1.0"}}}`)
+	assertResponseID(t, json.RawMessage(c.events.next(t)), "0")
+	c.post(t, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	return c
+}
+
+// do sends an HTTP request to a path of the handler.
+func (c *testSSEClient) do(t *testing.T, method, path, body string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, c.ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", contentTypeJSON)
+
+	resp, err := c.ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+// post sends a message to the session.
+func (c *testSSEClient) post(t *testing.T, body string) {
+	t.Helper()
+
+	if resp := c.do(t, http.MethodPost, c.endpoint, body); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("got %s, want %d", resp.Status, http.StatusAccepted)
+	}
+}
+
+// assertResponseID checks a message is a response with the given id.
+func assertResponseID(t *testing.T, raw json.RawMessage, id string) {
+	t.Helper()
+
+	if key, ok := responseKey(raw); !ok || key != id {
+		t.Fatalf("got %s, want response %s", raw, id)
+	}
+}
+
+func TestSSEHandler(t *testing.T) {
+	t.Run("endpoint", testSSEHandlerEndpoint)
+	t.Run("messages", testSSEHandlerMessages)
+	t.Run("client request", testSSEHandlerClientRequest)
+	t.Run("close", testSSEHandlerClose)
+	t.Run("invalid", testSSEHandlerInvalid)
+	t.Run("invalid message", testSSEHandlerInvalidMessage)
+	t.Run("origin", testSSEHandlerOrigin)
+	t.Run("cancelled post", testSSEHandlerCancelledPost)
+}
+
+func testSSEHandlerEndpoint(t *testing.T) {
+	c := newTestSSEClient(t, newTestMCPServer())
+
+	id, ok := strings.CutPrefix(c.endpoint, "/messages?"+SSESessionParam+"=")
+	if !ok || len(id) != 32 {
+		t.Fatalf("endpoint: got %q", c.endpoint)
+	}
+
+	c.h.mu.Lock()
+	_, ok = c.h.sessions[id]
+	c.h.mu.Unlock()
+	if !ok {
+		t.Errorf("session %q not found", id)
+	}
+}
+
+func testSSEHandlerMessages(t *testing.T) {
+	c := newTestSSESession(t, newTestHTTPServer())
+
+	c.post(t, `{"jsonrpc":"2.0","id":1,"method":"tools/call",`+
+		`"params":{"name":"reindex","_meta":{"progressToken":"t1"}}}`)
+	assertNotification(t, json.RawMessage(c.events.next(t)), MethodProgress,
+		`{"progressToken":"t1","progress":1,"total":2}`)
+	assertResponseID(t, json.RawMessage(c.events.next(t)), "1")
+
+	c.post(t, `[{"jsonrpc":"2.0","id":2,"method":"ping"},{"jsonrpc":"2.0","id":3,"method":"ping"}]`)
+	var batch []json.RawMessage
+	if err := json.Unmarshal([]byte(c.events.next(t)), &batch); err != nil || len(batch) != 2 {
+		t.Errorf("batch: got %s %v, want two responses", batch, err)
+	}
+}
+
+func testSSEHandlerClientRequest(t *testing.T) {
+	c := newTestSSEClient(t, newTestHTTPServer())
+	c.post(t, `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{`+
+		`"protocolVersion":"2024-11-05","capabilities":{"sampling":{}},`+
+		`"clientInfo":{"name":"test","version":"1.0"}}}`)
+	assertResponseID(t, json.RawMessage(c.events.next(t)), "0")
+	c.post(t, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	c.post(t, `{"jsonrpc":"2.0","id":1,"method":"summarise"}`)
+	raw := json.RawMessage(c.events.next(t))
+	assertRequest(t, raw, "1", MethodSamplingCreateMessage,
+		`{"messages":[{"role":"user","content":{"type":"text","text":"Summarise this."}}],"maxTokens":100}`)
+
+	c.post(t, `{"jsonrpc":"2.0","id":1,"result":{"role":"assistant",`+
+		`"content":{"type":"text","text":"Done."},"model":"test"}}`)
+	if got, want := c.events.next(t), `{"id":1,"result":{"role":"assistant",`+
+		`"model":"test","content":{"type":"text","text":"Done."}},"jsonrpc":"2.0"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func testSSEHandlerClose(t *testing.T) {
+	c := newTestSSESession(t, newTestMCPServer())
+
+	if err := c.h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	c.events.assertEnd(t)
+
+	resp := c.do(t, http.MethodPost, c.endpoint, `{"jsonrpc":"2.0","method":"ping"}`)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("post: got %s, want %d", resp.Status, http.StatusNotFound)
+	}
+	if resp := c.do(t, http.MethodGet, "", ""); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("get: got %s, want %d", resp.Status, http.StatusServiceUnavailable)
+	}
+}
+
+// sseInvalidTestCase represents a request rejected by an SSEHandler
+type sseInvalidTestCase struct {
+	name   string
+	method string
+	path   string // {endpoint} is replaced by the endpoint of the session
+	body   string
+	status int
+}
+
+// test runs the SSEHandler rejection test case
+func (tc sseInvalidTestCase) test(t *testing.T) {
+	c := newTestSSESession(t, newTestMCPServer())
+
+	path := strings.ReplaceAll(tc.path, "{endpoint}", c.endpoint)
+	if resp := c.do(t, tc.method, path, tc.body); resp.StatusCode != tc.status {
+		t.Errorf("got %s, want %d", resp.Status, tc.status)
+	}
+}
+
+func testSSEHandlerInvalid(t *testing.T) {
+	tests := []sseInvalidTestCase{
+		{"no session", http.MethodPost, "/messages", `{}`, http.StatusBadRequest},
+		{"unknown session", http.MethodPost, "/messages?sessionId=x", `{}`, http.StatusNotFound},
+		{"invalid JSON", http.MethodPost, "{endpoint}", `{`, http.StatusBadRequest},
+		{"method", http.MethodDelete, "{endpoint}", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func testSSEHandlerInvalidMessage(t *testing.T) {
+	c := newTestSSESession(t, newTestMCPServer())
+
+	c.post(t, `{"jsonrpc":"2.0","id":1}`)
+	if got, want := c.events.next(t), `{"id":1,"error":{"code":-32600,`+
+		`"message":"invalid request: missing method"},"jsonrpc":"2.0"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+// doOrigin sends a request from another origin, returning its status.
+func (c *testSSEClient) doOrigin(t *testing.T, method, path string) int {
+	t.Helper()
+
+	req, err := http.NewRequest(method, c.ts.URL+path, strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Origin", "https://example.com")
+
+	resp, err := c.ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode
+}
+
+func testSSEHandlerOrigin(t *testing.T) {
+	c := newTestSSEClient(t, newTestMCPServer())
+
+	if got := c.doOrigin(t, http.MethodGet, ""); got != http.StatusForbidden {
+		t.Errorf("get: got %d, want %d", got, http.StatusForbidden)
+	}
+	if got := c.doOrigin(t, http.MethodPost, c.endpoint); got != http.StatusForbidden {
+		t.Errorf("post: got %d, want %d", got, http.StatusForbidden)
+	}
+}
+
+// stalledServer serves connections without ever reading the messages of
+// their stream.
+type stalledServer struct{}
+
+func (stalledServer) ServeConn(ctx context.Context, _ jsonrpc2.ObjectStream,
+	opts ...jsonrpc2.ConnOpt) *jsonrpc2.Conn {
+	conn, _ := net.Pipe()
+	return jsonrpc2.NewConn(ctx, jsonrpc2.NewPlainObjectStream(conn), nil, opts...)
+}
+
+func testSSEHandlerCancelledPost(t *testing.T) {
+	c := newTestSSEClient(t, stalledServer{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, c.endpoint, strings.NewReader(`{}`)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	c.h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,117 @@
+package protomcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+var _ jsonrpc2.ObjectStream = (*lineStream)(nil)
+
+// lineStream is a jsonrpc2.ObjectStream exchanging newline-delimited JSON
+// messages, as the MCP stdio transport does.
+type lineStream struct {
+	r  *bufio.Reader
+	w  io.Writer
+	cs []io.Closer
+	mu sync.Mutex // serialises writes
+}
+
+// NewLineStream returns a jsonrpc2.ObjectStream reading JSON messages
+// from r and writing them to w, one per line. Blank lines are ignored,
+// and lines which aren't valid JSON are answered with a parse error by
+// the servers instead of closing the stream. Closing the stream closes r
+// and w if they implement io.Closer.
+func NewLineStream(r io.Reader, w io.Writer) jsonrpc2.ObjectStream {
+	s := &lineStream{
+		r: bufio.NewReader(r),
+		w: w,
+	}
+	for _, v := range []any{r, w} {
+		if c, ok := v.(io.Closer); ok {
+			s.cs = append(s.cs, c)
+		}
+	}
+	return s
+}
+
+// ReadObject implements jsonrpc2.ObjectStream.
+func (s *lineStream) ReadObject(v any) error {
+	for {
+		line, err := s.r.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return decodeMessage(line, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// decodeMessage decodes a message read by a stream. Raw messages are
+// passed through unchecked, for the messageStream to reply to invalid
+// ones.
+func decodeMessage(data []byte, v any) error {
+	if raw, ok := v.(*json.RawMessage); ok {
+		*raw = data
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+// WriteObject implements jsonrpc2.ObjectStream. The compact encoding of
+// JSON never contains newlines.
+func (s *lineStream) WriteObject(obj any) error {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.w.Write(append(b, '\n'))
+	return err
+}
+
+// Close implements jsonrpc2.ObjectStream.
+func (s *lineStream) Close() error {
+	var err error
+	for _, c := range s.cs {
+		if e := c.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// ServeStdio serves server over the standard input and output of the
+// process, the transport MCP clients use to run local servers. It returns
+// when the client closes the standard input, or the context of the
+// server once cancelled. Nothing else may be written to the standard
+// output, logs belong in the standard error.
+func ServeStdio(ctx context.Context, server ConnServer) error {
+	// only closing stdin is needed to stop reading
+	return ServeIO(ctx, server, os.Stdin, struct{ io.Writer }{os.Stdout})
+}
+
+// ServeIO serves server over a single connection reading newline-delimited
+// JSON-RPC messages from r and writing them to w. See ServeStdio.
+func ServeIO(ctx context.Context, server ConnServer, r io.Reader, w io.Writer) error {
+	conn := server.ServeConn(ctx, NewLineStream(r, w))
+
+	select {
+	case <-conn.DisconnectNotify():
+		return nil
+	case <-ctx.Done():
+		_ = conn.Close()
+		<-conn.DisconnectNotify()
+		return context.Cause(ctx)
+	}
+}
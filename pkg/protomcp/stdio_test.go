@@ -0,0 +1,143 @@
+package protomcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// testStdio is the client end of a server served by ServeIO over pipes.
+type testStdio struct {
+	in   *io.PipeWriter
+	out  *bufio.Reader
+	msgs chan string
+	errs chan error
+}
+
+// newTestStdio serves s with ServeIO over a pair of pipes.
+func newTestStdio(ctx context.Context, t *testing.T, s ConnServer) *testStdio {
+	t.Helper()
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &testStdio{
+		in:   inW,
+		out:  bufio.NewReader(outR),
+		msgs: make(chan string, 16),
+		errs: make(chan error, 1),
+	}
+	go c.writeLoop()
+	go func() {
+		c.errs <- ServeIO(ctx, s, inR, outW)
+	}()
+
+	t.Cleanup(func() {
+		close(c.msgs)
+		_ = inW.Close()
+		_ = outR.Close()
+	})
+	return c
+}
+
+// writeLoop writes queued data, as writes on a pipe block until the
+// server reads them.
+func (c *testStdio) writeLoop() {
+	for data := range c.msgs {
+		if _, err := io.WriteString(c.in, data); err != nil {
+			// recv will fail
+			return
+		}
+	}
+}
+
+// send queues raw data to be written to the server.
+func (c *testStdio) send(data string) {
+	c.msgs <- data
+}
+
+// recv reads the next line written by the server.
+func (c *testStdio) recv(t *testing.T) string {
+	t.Helper()
+
+	line, err := c.out.ReadString('\n')
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	return line
+}
+
+// call sends a request and checks the line answering it.
+func (c *testStdio) call(t *testing.T, req, want string) {
+	t.Helper()
+
+	c.send(req + "\n")
+	if got := c.recv(t); got != want+"\n" {
+		t.Errorf("got %q, want %q", got, want+"\n")
+	}
+}
+
+// wait waits for ServeIO to return.
+func (c *testStdio) wait(t *testing.T) error {
+	t.Helper()
+
+	select {
+	case err := <-c.errs:
+		return err
+	case <-time.After(testTimeout):
+		t.Fatal("ServeIO didn't return")
+		return nil
+	}
+}
+
+func TestServeIO(t *testing.T) {
+	t.Run("session", testServeIOSession)
+	t.Run("parse error", testServeIOParseError)
+	t.Run("closed input", testServeIOClosedInput)
+	t.Run("cancelled", testServeIOCancelled)
+}
+
+func testServeIOSession(t *testing.T) {
+	c := newTestStdio(context.Background(), t, newTestMCPServer())
+
+	c.send(initRequest + "\n")
+	var resp testResponse
+	if err := json.Unmarshal([]byte(c.recv(t)), &resp); err != nil || resp.Error != nil {
+		t.Fatalf("initialize: %v %v", err, resp.Error)
+	}
+
+	c.send("\n" + `{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\r\n")
+	c.call(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`, `{"id":1,"result":{},"jsonrpc":"2.0"}`)
+	c.call(t, `{"jsonrpc":"2.0","id":2,"method":"session"}`,
+		`{"id":2,"result":{"client":"test","initialized":true,"roots":true,"version":"2025-06-18"},"jsonrpc":"2.0"}`)
+}
+
+func testServeIOParseError(t *testing.T) {
+	c := newTestStdio(context.Background(), t, newTestMCPServer())
+
+	c.call(t, `{"jsonrpc":"2.0",`,
+		`{"id":null,"error":{"code":-32700,"message":"parse error"},"jsonrpc":"2.0"}`)
+	c.call(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`, `{"id":1,"result":{},"jsonrpc":"2.0"}`)
+}
+
+func testServeIOClosedInput(t *testing.T) {
+	c := newTestStdio(context.Background(), t, NewDispatcher())
+
+	_ = c.in.Close()
+	if err := c.wait(t); err != nil {
+		t.Errorf("ServeIO: %v", err)
+	}
+}
+
+func testServeIOCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := newTestStdio(ctx, t, NewDispatcher())
+
+	cancel()
+	if err := c.wait(t); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+}
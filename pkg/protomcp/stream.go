@@ -0,0 +1,255 @@
+package protomcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// messageStream wraps a jsonrpc2.ObjectStream, validating inbound messages
+// before they reach jsonrpc2.Conn and answering malformed ones with the
+// errors mandated by the JSON-RPC 2.0 specification instead of dropping the
+// connection.
+//
+// Batch requests are split into their individual messages, and the
+// responses to them held back until they can be written together.
+type messageStream struct {
+	stream  jsonrpc2.ObjectStream
+	pending map[string]batchedRequest // batched requests by tagged id
+	queue   []json.RawMessage         // batched messages not yet read
+	seq     uint64                    // last batched request tagged
+	mu      sync.Mutex                // serialises writes
+}
+
+// newMessageStream wraps stream for use by a dispatcher.
+func newMessageStream(stream jsonrpc2.ObjectStream) *messageStream {
+	return &messageStream{
+		stream:  stream,
+		pending: make(map[string]batchedRequest),
+	}
+}
+
+// ReadObject implements jsonrpc2.ObjectStream, skipping over invalid
+// messages after replying to them.
+func (s *messageStream) ReadObject(v any) error {
+	for {
+		raw, err := s.next()
+		if err != nil {
+			return err
+		}
+		if raw != nil {
+			return json.Unmarshal(raw, v)
+		}
+	}
+}
+
+// next returns the next valid message, queued or read. A nil message
+// without error means the one read was either rejected or a batch.
+func (s *messageStream) next() (json.RawMessage, error) {
+	if len(s.queue) > 0 {
+		raw := s.queue[0]
+		s.queue = s.queue[1:]
+		return raw, nil
+	}
+
+	raw, err := s.readRaw()
+	switch {
+	case err != nil:
+		return nil, err
+	case jsonKind(raw) == '[':
+		s.readBatch(raw)
+		return nil, nil
+	}
+
+	if err := s.check(raw); err != nil {
+		_ = s.writeError(err.id, err.err)
+		return nil, nil
+	}
+	return raw, nil
+}
+
+// readRaw reads the next message from the underlying stream, replying
+// with a parse error if it isn't valid JSON.
+func (s *messageStream) readRaw() (json.RawMessage, error) {
+	var raw json.RawMessage
+	err := s.stream.ReadObject(&raw)
+
+	var se *json.SyntaxError
+	if errors.As(err, &se) {
+		// the underlying stream can't recover from this one
+		_ = s.writeError(nil, NewError(CodeParseError, "parse error"))
+	}
+	return raw, err
+}
+
+// WriteObject implements jsonrpc2.ObjectStream. Responses to batched
+// requests are held until the whole batch has been answered.
+func (s *messageStream) WriteObject(obj any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) > 0 {
+		if b, raw := s.batched(obj); b != nil {
+			return s.writeBatched(b, raw)
+		}
+	}
+	return s.stream.WriteObject(obj)
+}
+
+// Close implements jsonrpc2.ObjectStream.
+func (s *messageStream) Close() error {
+	return s.stream.Close()
+}
+
+// check validates a raw inbound message.
+func (*messageStream) check(raw json.RawMessage) *messageError {
+	if !json.Valid(raw) {
+		return &messageError{err: NewError(CodeParseError, "parse error")}
+	}
+	return checkMessage(raw)
+}
+
+// writeError sends an error response. A nil id is sent as null.
+func (s *messageStream) writeError(id *jsonrpc2.ID, err *Error) error {
+	e := &messageError{id: id, err: err}
+	return s.WriteObject(e.response())
+}
+
+// errorResponse is a JSON-RPC error response whose id may be null, which
+// jsonrpc2.Response can't represent.
+type errorResponse struct {
+	ID      *jsonrpc2.ID    `json:"id"`
+	Error   *jsonrpc2.Error `json:"error"`
+	JSONRPC string          `json:"jsonrpc"`
+}
+
+// messageError describes why an inbound message was rejected, and the id
+// to use when replying.
+type messageError struct {
+	id  *jsonrpc2.ID
+	err *Error
+}
+
+// response returns the error response to the rejected message.
+func (e *messageError) response() *errorResponse {
+	return &errorResponse{
+		JSONRPC: "2.0",
+		ID:      e.id,
+		Error:   toJSONRPCError(e.err),
+	}
+}
+
+// rawMessage holds the members of a JSON-RPC message relevant to
+// validation.
+type rawMessage struct {
+	JSONRPC json.RawMessage `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  json.RawMessage `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	Result  json.RawMessage `json:"result"`
+	Error   json.RawMessage `json:"error"`
+}
+
+// checkMessage validates a single JSON-RPC 2.0 request or response object.
+func checkMessage(raw json.RawMessage) *messageError {
+	var m rawMessage
+	if jsonKind(raw) != '{' || json.Unmarshal(raw, &m) != nil {
+		return invalidRequest(nil, "message must be an object")
+	}
+
+	id, ok := parseID(m.ID)
+	switch {
+	case !ok:
+		return invalidRequest(nil, "invalid id")
+	case string(m.JSONRPC) != `"2.0"`:
+		return invalidRequest(id, "invalid jsonrpc version")
+	case m.Method != nil:
+		return checkRequest(id, &m)
+	default:
+		return checkResponse(id, &m)
+	}
+}
+
+// checkRequest validates the members of a request.
+func checkRequest(id *jsonrpc2.ID, m *rawMessage) *messageError {
+	switch {
+	case jsonKind(m.Method) != '"':
+		return invalidRequest(id, "method must be a string")
+	case id != nil && id.IsString && strings.HasPrefix(id.Str, batchIDPrefix):
+		return invalidRequest(id, "reserved id")
+	case m.Params != nil && !isStructured(m.Params):
+		return invalidRequest(id, "params must be an object or an array")
+	case m.Result != nil || m.Error != nil:
+		return invalidRequest(id, "request must not have result or error")
+	default:
+		return nil
+	}
+}
+
+// checkResponse validates the members of a response.
+func checkResponse(id *jsonrpc2.ID, m *rawMessage) *messageError {
+	switch {
+	case m.Result != nil && m.Error != nil:
+		return invalidRequest(id, "response must not have both result and error")
+	case m.Result == nil && m.Error == nil:
+		return invalidRequest(id, "missing method")
+	default:
+		return nil
+	}
+}
+
+// invalidRequest returns a CodeInvalidRequest messageError.
+func invalidRequest(id *jsonrpc2.ID, reason string) *messageError {
+	return &messageError{
+		id:  id,
+		err: NewError(CodeInvalidRequest, "invalid request: %s", reason),
+	}
+}
+
+// parseID decodes a raw id member using the same conventions as
+// jsonrpc2.Request. Absent and null ids yield nil. Only strings and
+// non-negative integral numbers are valid, as jsonrpc2.ID can't hold
+// negative ones.
+func parseID(raw json.RawMessage) (*jsonrpc2.ID, bool) {
+	switch jsonKind(raw) {
+	case 0, 'n':
+		return nil, true
+	case '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, false
+		}
+		return &jsonrpc2.ID{Str: s, IsString: true}, true
+	default:
+		var n json.Number
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return nil, false
+		}
+		v, err := strconv.ParseUint(n.String(), 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		return &jsonrpc2.ID{Num: v}, true
+	}
+}
+
+// isStructured tells if a raw value is an object or an array.
+func isStructured(raw json.RawMessage) bool {
+	k := jsonKind(raw)
+	return k == '{' || k == '['
+}
+
+// jsonKind returns the first significant byte of a raw JSON value, or 0 if
+// it is empty.
+func jsonKind(raw json.RawMessage) byte {
+	raw = bytes.TrimLeft(raw, " \t\r\n")
+	if len(raw) == 0 {
+		return 0
+	}
+	return raw[0]
+}
@@ -0,0 +1,88 @@
+package protomcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// parseIDTestCase represents a test case for parseID
+type parseIDTestCase struct {
+	name string
+	raw  string
+	want string
+	ok   bool
+}
+
+// test runs the parseID test case
+func (tc parseIDTestCase) test(t *testing.T) {
+	t.Helper()
+
+	id, ok := parseID(json.RawMessage(tc.raw))
+	if ok != tc.ok {
+		t.Fatalf("parseID() ok = %v, want %v", ok, tc.ok)
+	}
+	if got := idString(t, id); got != tc.want {
+		t.Errorf("parseID() = %s, want %s", got, tc.want)
+	}
+}
+
+func TestParseID(t *testing.T) {
+	tests := []parseIDTestCase{
+		{"absent", "", "", true},
+		{"null", "null", "", true},
+		{"string", `"abc"`, `"abc"`, true},
+		{"number", "42", "42", true},
+		{"largest number", "18446744073709551615", "18446744073709551615", true},
+		{"negative", "-1", "", false},
+		{"fractional", "1.5", "", false},
+		{"object", "{}", "", false},
+		{"boolean", "true", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestCheckMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   string
+		valid bool
+	}{
+		{"request", `{"jsonrpc":"2.0","id":1,"method":"a"}`, true},
+		{"notification", `{"jsonrpc":"2.0","method":"a","params":[]}`, true},
+		{"result", `{"jsonrpc":"2.0","id":1,"result":null}`, true},
+		{"error", `{"jsonrpc":"2.0","id":1,"error":{"code":1,"message":"x"}}`, true},
+		{"array", `[]`, false},
+		{"no version", `{"id":1,"method":"a"}`, false},
+		{"numeric method", `{"jsonrpc":"2.0","id":1,"method":1}`, false},
+		{"scalar params", `{"jsonrpc":"2.0","id":1,"method":"a","params":1}`, false},
+		{"empty response", `{"jsonrpc":"2.0","id":1}`, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkMessage(json.RawMessage(tc.raw))
+			if valid := err == nil; valid != tc.valid {
+				t.Errorf("checkMessage() = %v, want valid %v", err, tc.valid)
+			}
+		})
+	}
+}
+
+// idString renders an id as JSON, or "" if nil.
+func idString(t *testing.T, id *jsonrpc2.ID) string {
+	t.Helper()
+
+	if id == nil {
+		return ""
+	}
+	b, err := id.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	return string(b)
+}
@@ -0,0 +1,445 @@
+package protomcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+var _ http.Handler = (*StreamableHTTPHandler)(nil)
+
+// Streamable HTTP headers.
+const (
+	HeaderSessionID       = "Mcp-Session-Id"
+	HeaderProtocolVersion = "Mcp-Protocol-Version"
+)
+
+// DefaultMaxBodySize is the maximum size of the body of POST requests when
+// the MaxBodySize of a handler isn't set.
+const DefaultMaxBodySize = 4 << 20
+
+// DefaultReplayBufferSize is the number of events kept per session when
+// StreamableHTTPHandler.ReplayBufferSize isn't set.
+const DefaultReplayBufferSize = 256
+
+// DefaultSessionIdleTimeout is how long sessions are kept without requests
+// when StreamableHTTPHandler.SessionIdleTimeout isn't set.
+const DefaultSessionIdleTimeout = 30 * time.Minute
+
+// errTooManySessions is returned when starting a session beyond
+// StreamableHTTPHandler.MaxSessions.
+var errTooManySessions = errors.New("too many sessions")
+
+// Media types of the Streamable HTTP transport.
+const (
+	contentTypeJSON        = "application/json"
+	contentTypeEventStream = "text/event-stream"
+)
+
+// StreamableHTTPHandler serves MCP over the Streamable HTTP transport,
+// one session per client, on any HTTP server including HTTP/2 ones.
+//
+// Clients POST their messages, and receive the responses to their
+// requests either as JSON or, if the server sends them requests or
+// notifications meanwhile, as a stream of Server-Sent Events. They can
+// also GET an event stream for the messages of the server unrelated to
+// their requests. Sessions are identified by the Mcp-Session-Id header
+// assigned on initialisation, and terminated with DELETE. Requests giving
+// an Mcp-Protocol-Version header must give the revision negotiated by
+// their session.
+//
+// Events carry ids, and the latest are kept so that clients can resume an
+// interrupted event stream by sending a GET request with the id of the
+// last event received as Last-Event-ID. Once the response to a POST
+// request has turned into an event stream, it can be resumed the same way
+// if interrupted.
+//
+// Sessions are terminated once they have had no request in progress for
+// SessionIdleTimeout, as clients may go away without DELETE.
+//
+// The exported fields must not be changed once the handler is serving.
+type StreamableHTTPHandler struct {
+	server   ConnServer
+	sessions map[string]*httpSession
+
+	// CheckOrigin tells if a request is accepted from the origin of a
+	// browser, preventing DNS rebinding attacks. Nil accepts requests
+	// without Origin, and those from the same host or from localhost.
+	// Servers reachable by name should check against their own list.
+	CheckOrigin func(r *http.Request) bool
+
+	// MaxBodySize is the maximum size of the body of POST requests.
+	// Zero means DefaultMaxBodySize.
+	MaxBodySize int64
+
+	// ReplayBufferSize is the number of events kept per session to
+	// resume event streams. Zero means DefaultReplayBufferSize, and
+	// negative disables resumption.
+	ReplayBufferSize int
+
+	// SessionIdleTimeout is how long a session is kept without requests
+	// in progress. Zero means DefaultSessionIdleTimeout, and negative
+	// keeps sessions until terminated by their client.
+	SessionIdleTimeout time.Duration
+
+	// MaxSessions is the maximum number of sessions, beyond which new
+	// ones are refused. Zero means no limit.
+	MaxSessions int
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// httpSession is an MCP session served by a StreamableHTTPHandler.
+type httpSession struct {
+	stream *httpStream
+	conn   *jsonrpc2.Conn
+	idle   *time.Timer // terminates the session once idle
+	id     string
+	active int // requests in progress
+}
+
+// NewStreamableHTTPHandler returns a StreamableHTTPHandler serving
+// sessions of server, usually an MCPServer.
+func NewStreamableHTTPHandler(server ConnServer) *StreamableHTTPHandler {
+	return &StreamableHTTPHandler{
+		server:   server,
+		sessions: make(map[string]*httpSession),
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *StreamableHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !checkOrigin(r, h.CheckOrigin) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	if v := r.Header.Get(HeaderProtocolVersion); v != "" && !slices.Contains(SupportedProtocolVersions(), v) {
+		http.Error(w, "unsupported protocol version: "+v, http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		h.servePost(w, r)
+	case http.MethodGet:
+		h.serveGet(w, r)
+	case http.MethodDelete:
+		h.serveDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Close terminates all sessions, and rejects new ones.
+func (h *StreamableHTTPHandler) Close() error {
+	h.mu.Lock()
+	h.closed = true
+	sessions := slices.Collect(maps.Values(h.sessions))
+	h.mu.Unlock()
+
+	for _, sess := range sessions {
+		_ = sess.conn.Close()
+	}
+	return nil
+}
+
+// newSession starts a new session, with the request starting it in
+// progress.
+func (h *StreamableHTTPHandler) newSession() (*httpSession, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	stream := newHTTPStream(h.replayBufferSize())
+	sess := &httpSession{
+		id:     id,
+		stream: stream,
+		conn:   h.server.ServeConn(context.Background(), stream),
+		active: 1,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch {
+	case h.closed:
+		_ = sess.conn.Close()
+		return nil, jsonrpc2.ErrClosed
+	case h.MaxSessions > 0 && len(h.sessions) >= h.MaxSessions:
+		_ = sess.conn.Close()
+		return nil, errTooManySessions
+	}
+
+	h.sessions[id] = sess
+	go h.track(sess)
+	return sess, nil
+}
+
+// replayBufferSize returns the effective ReplayBufferSize.
+func (h *StreamableHTTPHandler) replayBufferSize() int {
+	if h.ReplayBufferSize == 0 {
+		return DefaultReplayBufferSize
+	}
+	return h.ReplayBufferSize
+}
+
+// sessionIdleTimeout returns the effective SessionIdleTimeout.
+func (h *StreamableHTTPHandler) sessionIdleTimeout() time.Duration {
+	if h.SessionIdleTimeout == 0 {
+		return DefaultSessionIdleTimeout
+	}
+	return h.SessionIdleTimeout
+}
+
+// track forgets a session once its connection is closed.
+func (h *StreamableHTTPHandler) track(sess *httpSession) {
+	<-sess.conn.DisconnectNotify()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.sessions, sess.id)
+	if sess.idle != nil {
+		sess.idle.Stop()
+	}
+}
+
+// session returns the session of a request, replying with an error if
+// missing or unknown. The request is in progress until released.
+func (h *StreamableHTTPHandler) session(w http.ResponseWriter, r *http.Request) (*httpSession, bool) {
+	id := r.Header.Get(HeaderSessionID)
+	if id == "" {
+		http.Error(w, "missing "+HeaderSessionID, http.StatusBadRequest)
+		return nil, false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sess, ok := h.sessions[id]
+	if !ok {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return nil, false
+	}
+	if v := r.Header.Get(HeaderProtocolVersion); !sess.acceptsVersion(v) {
+		http.Error(w, "protocol version mismatch: "+v, http.StatusBadRequest)
+		return nil, false
+	}
+
+	sess.active++
+	if sess.idle != nil {
+		sess.idle.Stop()
+	}
+	return sess, true
+}
+
+// acceptsVersion tells if requests giving a protocol revision in their
+// Mcp-Protocol-Version header are accepted, either the one negotiated by
+// the session or none.
+func (sess *httpSession) acceptsVersion(v string) bool {
+	if v == "" {
+		return true
+	}
+	negotiated := sess.stream.protocolVersion()
+	return negotiated == "" || v == negotiated
+}
+
+// release ends a request of a session, terminating the session once idle
+// for SessionIdleTimeout.
+func (h *StreamableHTTPHandler) release(sess *httpSession) {
+	timeout := h.sessionIdleTimeout()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sess.active--
+	switch {
+	case sess.active > 0 || timeout < 0 || h.sessions[sess.id] != sess:
+		return
+	case sess.idle == nil:
+		sess.idle = time.AfterFunc(timeout, func() { h.expire(sess) })
+	default:
+		sess.idle.Reset(timeout)
+	}
+}
+
+// expire terminates a session if still idle.
+func (h *StreamableHTTPHandler) expire(sess *httpSession) {
+	h.mu.Lock()
+	idle := sess.active == 0 && h.sessions[sess.id] == sess
+	if idle {
+		delete(h.sessions, sess.id)
+	}
+	h.mu.Unlock()
+
+	if idle {
+		_ = sess.conn.Close()
+	}
+}
+
+// newSessionID returns a random session id.
+func newSessionID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// serveGet opens the event stream of the messages of the server unrelated
+// to requests of the client, or resumes the one given by Last-Event-ID.
+// Each stream is only sent to the latest request for it.
+func (h *StreamableHTTPHandler) serveGet(w http.ResponseWriter, r *http.Request) {
+	if !accepts(r, contentTypeEventStream) {
+		http.Error(w, "event stream not accepted", http.StatusNotAcceptable)
+		return
+	}
+
+	sess, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+	defer h.release(sess)
+
+	stream, seq, ok := lastEventID(r)
+	if !ok {
+		http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+		return
+	}
+
+	k, events := sess.stream.attach(stream, seq)
+	sess.resume(w, r, k, events)
+}
+
+// resume sends the events of a stream missed by the client, and those to
+// follow unless the stream has ended.
+func (sess *httpSession) resume(w http.ResponseWriter, r *http.Request, k *httpSink, events []httpEvent) {
+	sw := newSSEWriter(w)
+	if k == nil {
+		sw.send(events...)
+		return
+	}
+
+	defer sess.stream.detach(k)
+	if !sw.send(events...) {
+		sw.relay(r.Context(), k, sess.stream.closed)
+	}
+}
+
+// lastEventID returns the stream and sequence number given by the
+// Last-Event-ID header of a request, or the GET stream without replay if
+// absent.
+func lastEventID(r *http.Request) (stream, seq uint64, ok bool) {
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		return listenerStream, math.MaxUint64, true
+	}
+	return parseEventID(v)
+}
+
+// serveDelete terminates a session.
+func (h *StreamableHTTPHandler) serveDelete(w http.ResponseWriter, r *http.Request) {
+	sess, ok := h.session(w, r)
+	if !ok {
+		return
+	}
+	defer h.release(sess)
+
+	h.mu.Lock()
+	delete(h.sessions, sess.id)
+	h.mu.Unlock()
+
+	_ = sess.conn.Close()
+	w.WriteHeader(http.StatusOK)
+}
+
+// checkOrigin tells if a request is accepted from the origin of a
+// browser, using check if not nil. By default, requests without Origin
+// are accepted, as are those from the same host or from localhost.
+func checkOrigin(r *http.Request, check func(*http.Request) bool) bool {
+	if check != nil {
+		return check(r)
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	switch {
+	case err != nil:
+		return false
+	case strings.EqualFold(u.Host, r.Host):
+		return true
+	default:
+		return isLocalhost(u.Hostname())
+	}
+}
+
+// isLocalhost tells if a host name refers to the local machine.
+func isLocalhost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// accepts tells if the Accept header of a request includes a media type.
+func accepts(r *http.Request, mediaType string) bool {
+	for _, v := range r.Header.Values("Accept") {
+		for _, t := range strings.Split(v, ",") {
+			t, _, _ = strings.Cut(t, ";")
+			if t = strings.TrimSpace(t); t == mediaType || t == "*/*" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readBody reads the body of a POST request up to size bytes, or
+// DefaultMaxBodySize if not positive, replying with an error if too large
+// or unreadable.
+func readBody(w http.ResponseWriter, r *http.Request, size int64) ([]byte, bool) {
+	if size <= 0 {
+		size = DefaultMaxBodySize
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, size))
+	var me *http.MaxBytesError
+	switch {
+	case errors.As(err, &me):
+		http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+		return nil, false
+	case err != nil:
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return nil, false
+	}
+	return body, true
+}
+
+// writeJSON replies to an HTTP request with a JSON value.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,519 @@
+package protomcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// testHTTPClient is a client of a StreamableHTTPHandler served over
+// HTTP/2.
+type testHTTPClient struct {
+	ts      *httptest.Server
+	h       *StreamableHTTPHandler
+	session string
+}
+
+// newTestHTTPClient serves s with a StreamableHTTPHandler on an HTTP/2
+// test server.
+func newTestHTTPClient(t *testing.T, s ConnServer) *testHTTPClient {
+	t.Helper()
+
+	h := NewStreamableHTTPHandler(s)
+	ts := httptest.NewUnstartedServer(h)
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+
+	t.Cleanup(func() {
+		_ = h.Close()
+		ts.Close()
+	})
+	return &testHTTPClient{ts: ts, h: h}
+}
+
+// newTestHTTPSession returns a client of a new session of an MCP server,
+// initialised with the given client capabilities.
+func newTestHTTPSession(t *testing.T, s *MCPServer, caps string) *testHTTPClient {
+	t.Helper()
+
+	c := newTestHTTPClient(t, s)
+	c.initialize(t, caps)
+	return c
+}
+
+// initialize starts a new session, initialised with the given client
+// capabilities.
+func (c *testHTTPClient) initialize(t *testing.T, caps string) {
+	t.Helper()
+
+	resp, body := c.post(t, `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{`+
+		`"protocolVersion":"2025-06-18","capabilities":`+caps+`,`+
+		`"clientInfo":{"name":"test","version":"1.0"}}}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("initialize: %s %s", resp.Status, body)
+	}
+
+	c.session = resp.Header.Get(HeaderSessionID)
+	if c.session == "" {
+		t.Fatal("initialize: no session id")
+	}
+
+	resp, _ = c.post(t, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("initialized: %s", resp.Status)
+	}
+}
+
+// do sends an HTTP request to the handler.
+func (c *testHTTPClient) do(t *testing.T, method, body string) *http.Response {
+	t.Helper()
+
+	return c.send(t, c.newRequest(t, method, body))
+}
+
+// newRequest returns an HTTP request of the session.
+func (c *testHTTPClient) newRequest(t *testing.T, method, body string) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequest(method, c.ts.URL, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Content-Type", "application/json")
+	if c.session != "" {
+		req.Header.Set(HeaderSessionID, c.session)
+	}
+	return req
+}
+
+// send sends an HTTP request to the handler.
+func (c *testHTTPClient) send(t *testing.T, req *http.Request) *http.Response {
+	t.Helper()
+
+	resp, err := c.ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	if resp.ProtoMajor != 2 {
+		t.Errorf("got %s, want HTTP/2", resp.Proto)
+	}
+	return resp
+}
+
+// post sends a POST request, returning its response and its body.
+func (c *testHTTPClient) post(t *testing.T, body string) (*http.Response, string) {
+	t.Helper()
+
+	resp := c.do(t, http.MethodPost, body)
+	if resp.Header.Get("Content-Type") == contentTypeEventStream {
+		return resp, ""
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, strings.TrimSpace(string(b))
+}
+
+// assertJSON checks the response to a POST request is the given JSON.
+func (c *testHTTPClient) assertJSON(t *testing.T, req, want string) {
+	t.Helper()
+
+	resp, body := c.post(t, req)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != contentTypeJSON {
+		t.Fatalf("got %s %s, want JSON", resp.Status, resp.Header.Get("Content-Type"))
+	}
+	if body != want {
+		t.Errorf("got %s, want %s", body, want)
+	}
+}
+
+// testEvents reads Server-Sent Events.
+type testEvents struct {
+	r *bufio.Reader
+}
+
+// newTestEvents reads the events of the response to a request.
+func newTestEvents(t *testing.T, resp *http.Response) *testEvents {
+	t.Helper()
+
+	if ct := resp.Header.Get("Content-Type"); resp.StatusCode != http.StatusOK || ct != contentTypeEventStream {
+		t.Fatalf("got %s %s, want event stream", resp.Status, ct)
+	}
+	return &testEvents{r: bufio.NewReader(resp.Body)}
+}
+
+// next returns the data of the next event.
+func (e *testEvents) next(t *testing.T) string {
+	t.Helper()
+
+	_, data := e.nextEvent(t)
+	return data
+}
+
+// nextEvent returns the id and the data of the next event.
+func (e *testEvents) nextEvent(t *testing.T) (id, data string) {
+	t.Helper()
+
+	var lines []string
+	for {
+		line, err := e.r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("event: %v", err)
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && len(lines) > 0:
+			return id, strings.Join(lines, "\n")
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			lines = append(lines, strings.TrimPrefix(line, "data: "))
+		}
+	}
+}
+
+// assertEnd checks the event stream ends without further events.
+func (e *testEvents) assertEnd(t *testing.T) {
+	t.Helper()
+
+	rest, err := io.ReadAll(e.r)
+	if err != nil || len(rest) > 0 {
+		t.Errorf("got %q %v, want end of stream", rest, err)
+	}
+}
+
+// newTestHTTPServer returns an MCPServer with tools reporting progress
+// and sampling the client.
+func newTestHTTPServer() *MCPServer {
+	s := newTestMCPServer()
+	s.AddTool(&Tool{
+		Name:        "reindex",
+		InputSchema: json.RawMessage(`{"type":"object"}`),
+	}, NewMethodHandler(func(ctx context.Context, req *emptypb.Empty) (*emptypb.Empty, error) {
+		return req, ReportProgress(ctx, 1, 2, "half")
+	}))
+	s.Dispatcher().HandleFunc("summarise", func(ctx context.Context, _ json.RawMessage) (any, error) {
+		return CreateMessage(ctx, &CreateMessageParams{
+			Messages:  []SamplingMessage{{Role: RoleUser, Content: TextContent("Summarise this.")}},
+			MaxTokens: 100,
+		})
+	})
+	return s
+}
+
+func TestStreamableHTTPHandler(t *testing.T) {
+	t.Run("json", testStreamableHTTPJSON)
+	t.Run("batch", testStreamableHTTPBatch)
+	t.Run("events", testStreamableHTTPEvents)
+	t.Run("client request", testStreamableHTTPClientRequest)
+	t.Run("get", testStreamableHTTPGet)
+	t.Run("resume", testStreamableHTTPResume)
+	t.Run("delete", testStreamableHTTPDelete)
+	t.Run("sessions", testStreamableHTTPSessions)
+	t.Run("invalid", testStreamableHTTPInvalid)
+	t.Run("origin", testStreamableHTTPOrigin)
+	t.Run("idle timeout", testStreamableHTTPIdleTimeout)
+	t.Run("max sessions", testStreamableHTTPMaxSessions)
+	t.Run("cancelled post", testStreamableHTTPCancelledPost)
+	t.Run("protocol version", testStreamableHTTPProtocolVersion)
+}
+
+func testStreamableHTTPJSON(t *testing.T) {
+	c := newTestHTTPSession(t, newTestHTTPServer(), `{}`)
+
+	c.assertJSON(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`, `{"id":1,"result":{},"jsonrpc":"2.0"}`)
+	c.assertJSON(t, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"reindex"}}`,
+		`{"id":2,"result":{"structuredContent":{},"content":[{"type":"text","text":"{}"}]},"jsonrpc":"2.0"}`)
+}
+
+func testStreamableHTTPBatch(t *testing.T) {
+	c := newTestHTTPSession(t, newTestHTTPServer(), `{}`)
+
+	resp, body := c.post(t, `[{"jsonrpc":"2.0","id":1,"method":"ping"},{"jsonrpc":"1.0","id":2,"method":"ping"}]`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got %s", resp.Status)
+	}
+
+	want := `[{"id":2,"error":{"code":-32600,"message":"invalid request: invalid jsonrpc version"},"jsonrpc":"2.0"},` +
+		`{"id":1,"result":{},"jsonrpc":"2.0"}]`
+	if body != want {
+		t.Errorf("got %s, want %s", body, want)
+	}
+}
+
+func testStreamableHTTPEvents(t *testing.T) {
+	c := newTestHTTPSession(t, newTestHTTPServer(), `{}`)
+
+	resp := c.do(t, http.MethodPost, `{"jsonrpc":"2.0","id":1,"method":"tools/call",`+
+		`"params":{"name":"reindex","_meta":{"progressToken":"t1"}}}`)
+	events := newTestEvents(t, resp)
+
+	assertNotification(t, json.RawMessage(events.next(t)), MethodProgress,
+		`{"message":"half","progressToken":"t1","progress":1,"total":2}`)
+	if got, want := events.next(t), `{"id":1,"result":{"structuredContent":{},`+
+		`"content":[{"type":"text","text":"{}"}]},"jsonrpc":"2.0"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func testStreamableHTTPClientRequest(t *testing.T) {
+	c := newTestHTTPSession(t, newTestHTTPServer(), `{"sampling":{}}`)
+
+	events := newTestEvents(t, c.do(t, http.MethodPost, `{"jsonrpc":"2.0","id":1,"method":"summarise"}`))
+	assertRequest(t, json.RawMessage(events.next(t)), "1", MethodSamplingCreateMessage, summariseRequest)
+
+	resp, _ := c.post(t, `{"jsonrpc":"2.0","id":1,"result":{"role":"assistant","model":"m1",`+
+		`"content":{"type":"text","text":"Done."}}}`)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("got %s, want %d", resp.Status, http.StatusAccepted)
+	}
+
+	if got, want := events.next(t), `{"id":1,"result":{"role":"assistant","model":"m1",`+
+		`"content":{"type":"text","text":"Done."}},"jsonrpc":"2.0"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func testStreamableHTTPGet(t *testing.T) {
+	s := newTestHTTPServer()
+	c := newTestHTTPSession(t, s, `{}`)
+	c.assertJSON(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`, `{"id":1,"result":{},"jsonrpc":"2.0"}`)
+
+	first := newTestEvents(t, c.do(t, http.MethodGet, ""))
+	events := newTestEvents(t, c.do(t, http.MethodGet, ""))
+	first.assertEnd(t)
+
+	if err := s.NotifyResourceListChanged(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertNotification(t, json.RawMessage(events.next(t)), MethodResourcesListChanged, "")
+}
+
+func testStreamableHTTPResume(t *testing.T) {
+	release := make(chan struct{})
+	s := newTestHTTPServer()
+	s.AddTool(&Tool{
+		Name:        "slow",
+		InputSchema: json.RawMessage(`{"type":"object"}`),
+	}, NewMethodHandler(func(ctx context.Context, req *emptypb.Empty) (*emptypb.Empty, error) {
+		if err := ReportProgress(ctx, 1, 2, "half"); err != nil {
+			return nil, err
+		}
+		<-release
+		return req, nil
+	}))
+	c := newTestHTTPSession(t, s, `{}`)
+
+	resp := c.do(t, http.MethodPost, `{"jsonrpc":"2.0","id":1,"method":"tools/call",`+
+		`"params":{"name":"slow","_meta":{"progressToken":"t1"}}}`)
+	id, data := newTestEvents(t, resp).nextEvent(t)
+	assertNotification(t, json.RawMessage(data), MethodProgress,
+		`{"message":"half","progressToken":"t1","progress":1,"total":2}`)
+
+	_ = resp.Body.Close()
+	close(release)
+
+	req := c.newRequest(t, http.MethodGet, "")
+	req.Header.Set("Last-Event-ID", id)
+	events := newTestEvents(t, c.send(t, req))
+	if got, want := events.next(t), `{"id":1,"result":{"structuredContent":{},`+
+		`"content":[{"type":"text","text":"{}"}]},"jsonrpc":"2.0"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	events.assertEnd(t)
+
+	req = c.newRequest(t, http.MethodGet, "")
+	req.Header.Set("Last-Event-ID", "invalid")
+	if resp := c.send(t, req); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid Last-Event-ID: got %s, want %d", resp.Status, http.StatusBadRequest)
+	}
+}
+
+func testStreamableHTTPDelete(t *testing.T) {
+	c := newTestHTTPSession(t, newTestHTTPServer(), `{}`)
+
+	if resp := c.do(t, http.MethodDelete, ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("delete: got %s", resp.Status)
+	}
+	if resp, _ := c.post(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("after delete: got %s, want %d", resp.Status, http.StatusNotFound)
+	}
+}
+
+func testStreamableHTTPSessions(t *testing.T) {
+	c := newTestHTTPClient(t, newTestHTTPServer())
+
+	if resp, _ := c.post(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("no session: got %s, want %d", resp.Status, http.StatusBadRequest)
+	}
+
+	c.session = "unknown"
+	if resp, _ := c.post(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown session: got %s, want %d", resp.Status, http.StatusNotFound)
+	}
+}
+
+func testStreamableHTTPInvalid(t *testing.T) {
+	c := newTestHTTPSession(t, newTestHTTPServer(), `{}`)
+
+	resp, body := c.post(t, `{"jsonrpc":`)
+	want := `{"id":null,"error":{"code":-32700,"message":"parse error"},"jsonrpc":"2.0"}`
+	if resp.StatusCode != http.StatusBadRequest || body != want {
+		t.Errorf("got %s %s, want %d %s", resp.Status, body, http.StatusBadRequest, want)
+	}
+
+	if resp := c.do(t, http.MethodPut, ""); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("put: got %s, want %d", resp.Status, http.StatusMethodNotAllowed)
+	}
+}
+
+// pingVersion sends a ping request of the session giving a protocol
+// revision, returning the HTTP status.
+func (c *testHTTPClient) pingVersion(t *testing.T, version string) int {
+	t.Helper()
+
+	req := c.newRequest(t, http.MethodPost, `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	req.Header.Set(HeaderProtocolVersion, version)
+	return c.send(t, req).StatusCode
+}
+
+func testStreamableHTTPProtocolVersion(t *testing.T) {
+	c := newTestHTTPSession(t, newTestHTTPServer(), `{}`)
+
+	for _, tc := range []struct {
+		version string
+		want    int
+	}{
+		{ProtocolVersion20250618, http.StatusOK},
+		{ProtocolVersion20250326, http.StatusBadRequest},
+		{"2000-01-01", http.StatusBadRequest},
+	} {
+		if got := c.pingVersion(t, tc.version); got != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.version, got, tc.want)
+		}
+	}
+}
+
+func testStreamableHTTPOrigin(t *testing.T) {
+	c := newTestHTTPClient(t, newTestHTTPServer())
+
+	req := c.newRequest(t, http.MethodPost, initRequest)
+	req.Header.Set("Origin", "https://example.com")
+	if resp := c.send(t, req); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("got %s, want %d", resp.Status, http.StatusForbidden)
+	}
+
+	c.initialize(t, `{}`)
+}
+
+// ping sends a ping request of the session, returning the HTTP status.
+func (c *testHTTPClient) ping(t *testing.T) int {
+	t.Helper()
+
+	resp, _ := c.post(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	return resp.StatusCode
+}
+
+func testStreamableHTTPIdleTimeout(t *testing.T) {
+	c := newTestHTTPClient(t, newTestHTTPServer())
+	c.h.SessionIdleTimeout = 20 * time.Millisecond
+	c.initialize(t, `{}`)
+
+	// an open event stream keeps the session
+	stream := c.do(t, http.MethodGet, "")
+	time.Sleep(100 * time.Millisecond)
+	if got := c.ping(t); got != http.StatusOK {
+		t.Fatalf("streaming: got %d, want %d", got, http.StatusOK)
+	}
+	_ = stream.Body.Close()
+
+	// the session ends once idle for longer than the timeout
+	deadline := time.Now().Add(testTimeout)
+	for c.ping(t) != http.StatusNotFound {
+		if time.Now().After(deadline) {
+			t.Fatal("idle session not terminated")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func testStreamableHTTPMaxSessions(t *testing.T) {
+	c := newTestHTTPClient(t, newTestHTTPServer())
+	c.h.MaxSessions = 1
+	c.initialize(t, `{}`)
+
+	c.session = ""
+	if resp, _ := c.post(t, initRequest); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got %s, want %d", resp.Status, http.StatusServiceUnavailable)
+	}
+}
+
+func testStreamableHTTPCancelledPost(t *testing.T) {
+	h := NewStreamableHTTPHandler(stalledServer{})
+	defer func() { _ = h.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(initRequest)).WithContext(ctx)
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+// checkOriginTestCase represents a test case for checkOrigin.
+type checkOriginTestCase struct {
+	name   string
+	origin string
+	want   bool
+}
+
+func (tc checkOriginTestCase) test(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "https://mcp.example.com/mcp", nil)
+	if tc.origin != "" {
+		r.Header.Set("Origin", tc.origin)
+	}
+	if got := checkOrigin(r, nil); got != tc.want {
+		t.Errorf("checkOrigin(%q) = %v, want %v", tc.origin, got, tc.want)
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	tests := []checkOriginTestCase{
+		{"none", "", true},
+		{"same host", "https://mcp.example.com", true},
+		{"localhost", "http://localhost:3000", true},
+		{"loopback", "http://127.0.0.1:3000", true},
+		{"loopback ipv6", "http://[::1]:3000", true},
+		{"other host", "https://example.com", false},
+		{"other port", "https://mcp.example.com:8443", false},
+		{"null", "null", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "https://mcp.example.com/mcp", nil)
+	r.Header.Set("Origin", "https://example.com")
+	if !checkOrigin(r, func(*http.Request) bool { return true }) {
+		t.Error("custom check ignored")
+	}
+}
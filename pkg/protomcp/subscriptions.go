@@ -0,0 +1,258 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// MCP resource subscription methods and notifications.
+const (
+	MethodResourcesSubscribe   = "resources/subscribe"
+	MethodResourcesUnsubscribe = "resources/unsubscribe"
+	MethodResourcesUpdated     = "notifications/resources/updated"
+	MethodResourcesListChanged = "notifications/resources/list_changed"
+)
+
+// SubscribeParams are the params of resources/subscribe and
+// resources/unsubscribe.
+type SubscribeParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceUpdatedParams are the params of
+// notifications/resources/updated.
+type ResourceUpdatedParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceWatcher watches a resource while clients are subscribed to it,
+// calling updated whenever it changes. ctx is cancelled once the last
+// subscriber is gone, and the watcher should return then. A server
+// streaming RPC naturally backs a watcher, calling updated for every
+// message received.
+type ResourceWatcher func(ctx context.Context, uri string, updated func()) error
+
+// watcherEntry is a registered ResourceWatcher.
+type watcherEntry struct {
+	uri *URITemplate
+	w   ResourceWatcher
+}
+
+// subscription tracks the sessions subscribed to a resource.
+type subscription struct {
+	sessions map[*Session]struct{}
+	cancel   context.CancelFunc // stops the watcher, if any
+}
+
+// WatchResources registers a watcher for the resources whose URI matches
+// a URI template, replacing any previous one for the same template. It
+// runs once per subscribed URI.
+func (s *MCPServer) WatchResources(uriTemplate string, w ResourceWatcher) {
+	uri, err := ParseURITemplate(uriTemplate)
+	switch {
+	case err != nil:
+		panic(fmt.Errorf("protomcp: %w", err))
+	case w == nil:
+		panic(errors.New("protomcp: invalid resource watcher"))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.watchers.add(uriTemplate, &watcherEntry{uri: uri, w: w})
+}
+
+// NotifyResourceUpdated tells the sessions subscribed to a resource that
+// it has changed.
+func (s *MCPServer) NotifyResourceUpdated(ctx context.Context, uri string) error {
+	params := &ResourceUpdatedParams{URI: uri}
+
+	var errs []error
+	for _, sess := range s.subscribers(uri) {
+		if err := s.d.Notifier(sess.Conn()).Notify(ctx, MethodResourcesUpdated, params); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NotifyResourceListChanged tells all initialised sessions that the list
+// of resources has changed.
+func (s *MCPServer) NotifyResourceListChanged(ctx context.Context) error {
+	var errs []error
+	for _, sess := range s.readySessions() {
+		if err := s.d.Notifier(sess.Conn()).Notify(ctx, MethodResourcesListChanged, nil); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// subscribers returns the sessions subscribed to a resource.
+func (s *MCPServer) subscribers(uri string) []*Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[uri]
+	if !ok {
+		return nil
+	}
+
+	out := make([]*Session, 0, len(sub.sessions))
+	for sess := range sub.sessions {
+		out = append(out, sess)
+	}
+	return out
+}
+
+// readySessions returns the sessions that completed initialisation.
+func (s *MCPServer) readySessions() []*Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]*Session, 0, len(s.sessions))
+	for sess := range s.sessions {
+		if sess.Initialized() {
+			out = append(out, sess)
+		}
+	}
+	return out
+}
+
+// subscribe adds a session to the subscribers of a resource, starting
+// its watcher for the first one. It fails if the session has ended, as its
+// subscriptions may already have been dropped.
+func (s *MCPServer) subscribe(sess *Session, uri string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sess.ended() {
+		return jsonrpc2.ErrClosed
+	}
+
+	sub, ok := s.subs[uri]
+	if !ok {
+		sub = &subscription{sessions: make(map[*Session]struct{})}
+		sub.cancel = s.startWatcher(uri)
+		if s.subs == nil {
+			s.subs = make(map[string]*subscription)
+		}
+		s.subs[uri] = sub
+	}
+	sub.sessions[sess] = struct{}{}
+	return nil
+}
+
+// ended tells if the connection of a session is closed, and its
+// subscriptions dropped or about to be.
+func (s *Session) ended() bool {
+	conn := s.Conn()
+	if conn == nil {
+		// still starting
+		return false
+	}
+
+	select {
+	case <-conn.DisconnectNotify():
+		return true
+	default:
+		return false
+	}
+}
+
+// unsubscribe removes a session from the subscribers of a resource,
+// stopping its watcher after the last one. The caller must hold the lock.
+func (s *MCPServer) unsubscribe(sess *Session, uri string) {
+	sub, ok := s.subs[uri]
+	if !ok {
+		return
+	}
+
+	delete(sub.sessions, sess)
+	if len(sub.sessions) == 0 {
+		if sub.cancel != nil {
+			sub.cancel()
+		}
+		delete(s.subs, uri)
+	}
+}
+
+// dropSubscriptions removes all subscriptions of a closed session. The
+// caller must hold the lock.
+func (s *MCPServer) dropSubscriptions(sess *Session) {
+	for uri := range s.subs {
+		s.unsubscribe(sess, uri)
+	}
+}
+
+// startWatcher runs the watcher of a resource, if any, returning the
+// function stopping it. The caller must hold the lock.
+func (s *MCPServer) startWatcher(uri string) context.CancelFunc {
+	for _, e := range s.watchers.list {
+		if _, ok := e.uri.Match(uri); ok {
+			return s.runWatcher(e.w, uri)
+		}
+	}
+	return nil
+}
+
+// runWatcher runs a watcher in the background until cancelled.
+func (s *MCPServer) runWatcher(w ResourceWatcher, uri string) context.CancelFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		_ = w(ctx, uri, func() {
+			_ = s.NotifyResourceUpdated(ctx, uri)
+		})
+	}()
+	return cancel
+}
+
+// handleSubscribe implements resources/subscribe.
+func (s *MCPServer) handleSubscribe(ctx context.Context, params json.RawMessage) (any, error) {
+	sess, uri, err := s.subscriptionParams(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := s.resolveResource(uri); !ok {
+		return nil, resourceNotFound(uri)
+	}
+
+	if err := s.subscribe(sess, uri); err != nil {
+		return nil, err
+	}
+	return struct{}{}, nil
+}
+
+// handleUnsubscribe implements resources/unsubscribe.
+func (s *MCPServer) handleUnsubscribe(ctx context.Context, params json.RawMessage) (any, error) {
+	sess, uri, err := s.subscriptionParams(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unsubscribe(sess, uri)
+	return struct{}{}, nil
+}
+
+// subscriptionParams decodes the params of a subscription request, and
+// returns them with the session making it.
+func (*MCPServer) subscriptionParams(ctx context.Context, params json.RawMessage) (*Session, string, error) {
+	var p SubscribeParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, "", err
+	}
+
+	sess, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, "", NewError(CodeInternalError, "no session")
+	}
+	return sess, p.URI, nil
+}
@@ -0,0 +1,190 @@
+package protomcp
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func TestMCPServerSubscribe(t *testing.T) {
+	t.Run("updated", testMCPServerSubscribeUpdated)
+	t.Run("unsubscribe", testMCPServerSubscribeUnsubscribe)
+	t.Run("unknown resource", testMCPServerSubscribeUnknown)
+	t.Run("list changed", testMCPServerSubscribeListChanged)
+}
+
+func testMCPServerSubscribeUpdated(t *testing.T) {
+	s, p := newTestResourceServer(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"users://42"}}`)
+	resp.assertResult(t, "1", `{}`)
+
+	if err := s.NotifyResourceUpdated(context.Background(), "users://7"); err != nil {
+		t.Fatal(err)
+	}
+	errs := goNotify(func() error {
+		return s.NotifyResourceUpdated(context.Background(), "users://42")
+	})
+	assertNotification(t, p.recv(t), MethodResourcesUpdated, `{"uri":"users://42"}`)
+	if err := <-errs; err != nil {
+		t.Errorf("NotifyResourceUpdated: %v", err)
+	}
+}
+
+func testMCPServerSubscribeUnsubscribe(t *testing.T) {
+	s, p := newTestResourceServer(t)
+
+	p.call(t, `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"config://app"}}`)
+	resp := p.call(t, `{"jsonrpc":"2.0","id":2,"method":"resources/unsubscribe","params":{"uri":"config://app"}}`)
+	resp.assertResult(t, "2", `{}`)
+
+	if err := s.NotifyResourceUpdated(context.Background(), "config://app"); err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing is sent before the ping response
+	resp = p.call(t, `{"jsonrpc":"2.0","id":3,"method":"ping"}`)
+	resp.assertResult(t, "3", `{}`)
+}
+
+func testMCPServerSubscribeUnknown(t *testing.T) {
+	_, p := newTestResourceServer(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"nope://x"}}`)
+	resp.assertError(t, "1", CodeResourceNotFound)
+
+	resp = p.call(t, `{"jsonrpc":"2.0","id":2,"method":"resources/subscribe"}`)
+	resp.assertError(t, "2", CodeInvalidParams)
+}
+
+func testMCPServerSubscribeListChanged(t *testing.T) {
+	s, p := newTestResourceServer(t)
+
+	if caps := s.Capabilities(); !caps.Resources.Subscribe || !caps.Resources.ListChanged {
+		t.Errorf("resources capability = %+v", caps.Resources)
+	}
+
+	// notifications/initialized is handled before any later request
+	p.call(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+
+	errs := goNotify(func() error {
+		return s.NotifyResourceListChanged(context.Background())
+	})
+	assertNotification(t, p.recv(t), MethodResourcesListChanged, "")
+	if err := <-errs; err != nil {
+		t.Errorf("NotifyResourceListChanged: %v", err)
+	}
+}
+
+// goNotify runs a notifying function in the background, as writes block
+// until read.
+func goNotify(fn func() error) <-chan error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- fn()
+	}()
+	return errs
+}
+
+// testWatcher is a ResourceWatcher reporting its lifecycle.
+type testWatcher struct {
+	started chan string
+	stopped chan string
+	updated chan func()
+}
+
+func newTestWatcher() *testWatcher {
+	return &testWatcher{
+		started: make(chan string, 1),
+		stopped: make(chan string, 1),
+		updated: make(chan func(), 1),
+	}
+}
+
+// watch implements ResourceWatcher, handing updated over to the test.
+func (w *testWatcher) watch(ctx context.Context, uri string, updated func()) error {
+	w.updated <- updated
+	w.started <- uri
+	<-ctx.Done()
+	w.stopped <- uri
+	return ctx.Err()
+}
+
+// wait waits for a lifecycle event of the watcher.
+func (*testWatcher) wait(t *testing.T, ch chan string, want string) {
+	t.Helper()
+
+	select {
+	case uri := <-ch:
+		if uri != want {
+			t.Errorf("watcher uri = %q, want %q", uri, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for watcher")
+	}
+}
+
+func TestMCPServerWatchResources(t *testing.T) {
+	t.Run("unsubscribe", testMCPServerWatchResourcesUnsubscribe)
+	t.Run("disconnect", testMCPServerWatchResourcesDisconnect)
+	t.Run("ended session", testMCPServerWatchResourcesEnded)
+}
+
+func testMCPServerWatchResourcesUnsubscribe(t *testing.T) {
+	s, p := newTestResourceServer(t)
+	w := newTestWatcher()
+	s.WatchResources("users://{id}", w.watch)
+
+	p.call(t, `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"users://42"}}`)
+	w.wait(t, w.started, "users://42")
+	updated := <-w.updated
+	go updated()
+	assertNotification(t, p.recv(t), MethodResourcesUpdated, `{"uri":"users://42"}`)
+
+	p.call(t, `{"jsonrpc":"2.0","id":2,"method":"resources/unsubscribe","params":{"uri":"users://42"}}`)
+	w.wait(t, w.stopped, "users://42")
+}
+
+func testMCPServerWatchResourcesDisconnect(t *testing.T) {
+	s, p, conn := newTestResourceConn(t)
+	w := newTestWatcher()
+	s.WatchResources("config://app", w.watch)
+
+	p.call(t, `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"config://app"}}`)
+	w.wait(t, w.started, "config://app")
+
+	_ = conn.Close()
+	w.wait(t, w.stopped, "config://app")
+}
+
+func testMCPServerWatchResourcesEnded(t *testing.T) {
+	s, _, conn := newTestResourceConn(t)
+	w := newTestWatcher()
+	s.WatchResources("config://app", w.watch)
+
+	s.mu.Lock()
+	sessions := slices.Collect(maps.Keys(s.sessions))
+	s.mu.Unlock()
+	if len(sessions) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(sessions))
+	}
+
+	_ = conn.Close()
+	<-conn.DisconnectNotify()
+
+	// subscribing as the session ends
+	if err := s.subscribe(sessions[0], "config://app"); !errors.Is(err, jsonrpc2.ErrClosed) {
+		t.Errorf("got %v, want %v", err, jsonrpc2.ErrClosed)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.subs) != 0 {
+		t.Errorf("got %d subscriptions, want none", len(s.subs))
+	}
+}
@@ -0,0 +1,8 @@
+// Package template parses the templates of MCP resources and prompts:
+// RFC 6570 URI templates, matching the URIs of resources, and prompt
+// templates referencing their arguments as `{{name}}`.
+//
+// It has no dependencies beyond the standard library, so protoc plugins
+// can validate templates at generation time with the same rules applied
+// by the protomcp runtime.
+package template
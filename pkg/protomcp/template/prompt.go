@@ -0,0 +1,61 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// promptVarRe matches the `{{name}}` references of prompt templates.
+var promptVarRe = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// Prompt is a parsed prompt template, the text of an MCP prompt
+// referencing its arguments as `{{name}}`.
+type Prompt struct {
+	raw  string
+	vars []string
+}
+
+// ParsePrompt parses a prompt template.
+func ParsePrompt(s string) (*Prompt, error) {
+	t := &Prompt{raw: s}
+
+	for _, m := range promptVarRe.FindAllStringSubmatch(s, -1) {
+		name := m[1]
+		switch {
+		case !isVarName(name):
+			return nil, fmt.Errorf("invalid prompt template: bad variable %q", name)
+		case !slices.Contains(t.vars, name):
+			t.vars = append(t.vars, name)
+		}
+	}
+
+	if strings.Contains(promptVarRe.ReplaceAllString(s, ""), "{{") {
+		return nil, errors.New("invalid prompt template: unterminated {{")
+	}
+	return t, nil
+}
+
+// String returns the template text.
+func (t *Prompt) String() string {
+	return t.raw
+}
+
+// Variables returns the names of the arguments referenced by the
+// template, in order of first appearance.
+func (t *Prompt) Variables() []string {
+	out := make([]string, len(t.vars))
+	copy(out, t.vars)
+	return out
+}
+
+// Expand returns the template text with its references replaced by the
+// values of the arguments. Missing arguments expand to nothing.
+func (t *Prompt) Expand(args map[string]string) string {
+	return promptVarRe.ReplaceAllStringFunc(t.raw, func(ref string) string {
+		m := promptVarRe.FindStringSubmatch(ref)
+		return args[m[1]]
+	})
+}
@@ -0,0 +1,62 @@
+package template
+
+import (
+	"fmt"
+	"testing"
+)
+
+// parsePromptTestCase represents a test case for ParsePrompt
+type parsePromptTestCase struct {
+	name     string
+	template string
+	vars     string
+	ok       bool
+}
+
+// test runs the ParsePrompt test case
+func (tc parsePromptTestCase) test(t *testing.T) {
+	t.Helper()
+
+	tmpl, err := ParsePrompt(tc.template)
+	if (err == nil) != tc.ok {
+		t.Fatalf("ParsePrompt(%q) error = %v, want ok %v", tc.template, err, tc.ok)
+	}
+	if !tc.ok {
+		return
+	}
+
+	if got := fmt.Sprint(tmpl.Variables()); got != tc.vars {
+		t.Errorf("Variables() = %s, want %s", got, tc.vars)
+	}
+	if tmpl.String() != tc.template {
+		t.Errorf("String() = %q, want %q", tmpl.String(), tc.template)
+	}
+}
+
+func TestParsePrompt(t *testing.T) {
+	tests := []parsePromptTestCase{
+		{"plain", "Hello.", "[]", true},
+		{"variables", "Review {{user_id}} as {{ role }}.", "[user_id role]", true},
+		{"repeated", "{{a}} and {{a}} or {{b}}", "[a b]", true},
+		{"single braces", "{a} and }}", "[]", true},
+		{"unterminated", "Hello {{name", "", false},
+		{"empty", "Hello {{}}", "", false},
+		{"invalid name", "Hello {{first name}}", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+func TestPromptExpand(t *testing.T) {
+	tmpl, err := ParsePrompt("Summarise {{ topic }} for {{audience}}{{missing}}.")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := tmpl.Expand(map[string]string{"topic": "{{audience}}", "audience": "Go developers"})
+	if want := "Summarise {{audience}} for Go developers."; got != want {
+		t.Errorf("Expand() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,128 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// URI is a parsed RFC 6570 URI template, as used by MCP resource
+// templates. Only simple `{var}` and reserved `{+var}` expressions of a
+// single variable are supported. Simple values can't span path segments,
+// while reserved ones match any text.
+type URI struct {
+	re   *regexp.Regexp
+	raw  string
+	vars []string
+}
+
+// ParseURI parses a URI template.
+func ParseURI(s string) (*URI, error) {
+	t := &URI{raw: s}
+
+	parts := []string{"^"}
+	for rest := s; rest != ""; {
+		part, tail, err := t.next(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URI template %q: %w", s, err)
+		}
+		parts = append(parts, part)
+		rest = tail
+	}
+	parts = append(parts, "$")
+
+	t.re = regexp.MustCompile(strings.Join(parts, ""))
+	return t, nil
+}
+
+// next consumes the literal text or the expression at the start of a
+// template, and returns the pattern matching it and the rest.
+func (t *URI) next(s string) (pattern, rest string, err error) {
+	switch i := strings.IndexAny(s, "{}"); {
+	case i < 0:
+		return regexp.QuoteMeta(s), "", nil
+	case i > 0:
+		return regexp.QuoteMeta(s[:i]), s[i:], nil
+	case s[0] == '}':
+		return "", "", errors.New("unexpected '}'")
+	}
+
+	end := strings.IndexByte(s, '}')
+	switch {
+	case end < 0:
+		return "", "", errors.New("unterminated expression")
+	case end == 1:
+		return "", "", errors.New("empty expression")
+	}
+
+	pattern, err = t.addExpression(s[1:end])
+	return pattern, s[end+1:], err
+}
+
+// addExpression registers the variable of an expression and returns the
+// pattern matching its values.
+func (t *URI) addExpression(expr string) (string, error) {
+	pattern := `([^/?#]*)`
+	if name, ok := strings.CutPrefix(expr, "+"); ok {
+		expr, pattern = name, `(.*)`
+	}
+
+	if !isVarName(expr) {
+		return "", fmt.Errorf("unsupported expression {%s}", expr)
+	}
+	for _, v := range t.vars {
+		if v == expr {
+			return "", fmt.Errorf("duplicate variable %q", expr)
+		}
+	}
+
+	t.vars = append(t.vars, expr)
+	return pattern, nil
+}
+
+// isVarName tells if s is a valid variable name.
+func isVarName(s string) bool {
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// String returns the template text.
+func (t *URI) String() string {
+	return t.raw
+}
+
+// Variables returns the names of the variables of the template, in order
+// of appearance.
+func (t *URI) Variables() []string {
+	out := make([]string, len(t.vars))
+	copy(out, t.vars)
+	return out
+}
+
+// Match tells if a URI matches the template, and returns the decoded
+// values of its variables.
+func (t *URI) Match(uri string) (map[string]string, bool) {
+	m := t.re.FindStringSubmatch(uri)
+	if m == nil {
+		return nil, false
+	}
+
+	values := make(map[string]string, len(t.vars))
+	for i, name := range t.vars {
+		v, err := url.PathUnescape(m[i+1])
+		if err != nil {
+			return nil, false
+		}
+		values[name] = v
+	}
+	return values, true
+}
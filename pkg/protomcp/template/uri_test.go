@@ -0,0 +1,99 @@
+package template
+
+import (
+	"fmt"
+	"testing"
+)
+
+// parseURITestCase represents a test case for ParseURI
+type parseURITestCase struct {
+	name     string
+	template string
+	vars     string
+	ok       bool
+}
+
+// test runs the ParseURI test case
+func (tc parseURITestCase) test(t *testing.T) {
+	t.Helper()
+
+	tmpl, err := ParseURI(tc.template)
+	if (err == nil) != tc.ok {
+		t.Fatalf("ParseURI(%q) error = %v, want ok %v", tc.template, err, tc.ok)
+	}
+	if !tc.ok {
+		return
+	}
+
+	if got := fmt.Sprint(tmpl.Variables()); got != tc.vars {
+		t.Errorf("Variables() = %s, want %s", got, tc.vars)
+	}
+	if tmpl.String() != tc.template {
+		t.Errorf("String() = %q, want %q", tmpl.String(), tc.template)
+	}
+}
+
+func TestParseURI(t *testing.T) {
+	tests := []parseURITestCase{
+		{"fixed", "config://app", "[]", true},
+		{"simple", "users://{user_id}", "[user_id]", true},
+		{"several", "orgs://{org}/users/{id}", "[org id]", true},
+		{"reserved", "file:///{+path}", "[path]", true},
+		{"unterminated", "users://{id", "", false},
+		{"stray brace", "users://id}", "", false},
+		{"empty", "users://{}", "", false},
+		{"operator", "users://{/id}", "", false},
+		{"list", "users://{a,b}", "", false},
+		{"modifier", "users://{id*}", "", false},
+		{"duplicate", "users://{id}/{id}", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
+
+// uriMatchTestCase represents a test case for URI.Match
+type uriMatchTestCase struct {
+	name     string
+	template string
+	uri      string
+	want     string
+	ok       bool
+}
+
+// test runs the URI.Match test case
+func (tc uriMatchTestCase) test(t *testing.T) {
+	t.Helper()
+
+	tmpl, err := ParseURI(tc.template)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, ok := tmpl.Match(tc.uri)
+	if ok != tc.ok {
+		t.Fatalf("Match(%q) = %v, want %v", tc.uri, ok, tc.ok)
+	}
+	if got := fmt.Sprint(values); tc.ok && got != tc.want {
+		t.Errorf("Match(%q) = %s, want %s", tc.uri, got, tc.want)
+	}
+}
+
+func TestURIMatch(t *testing.T) {
+	tests := []uriMatchTestCase{
+		{"fixed", "config://app", "config://app", "map[]", true},
+		{"simple", "users://{id}", "users://42", "map[id:42]", true},
+		{"escaped", "users://{id}", "users://a%20b", "map[id:a b]", true},
+		{"several", "orgs://{org}/users/{id}", "orgs://x/users/y", "map[id:y org:x]", true},
+		{"reserved", "file:///{+path}", "file:///a/b.txt", "map[path:a/b.txt]", true},
+		{"segment", "users://{id}", "users://a/b", "", false},
+		{"literal", "users://{id}", "groups://42", "", false},
+		{"regexp literal", "a.b://{id}", "aXb://1", "", false},
+		{"bad escape", "users://{id}", "users://%zz", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, tc.test)
+	}
+}
@@ -0,0 +1,245 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// MCP tools methods.
+const (
+	MethodToolsList = "tools/list"
+	MethodToolsCall = "tools/call"
+)
+
+// Tool describes an MCP tool. Generated code describes each unary method
+// of a service as a tool, with its schemas derived from the request and
+// response messages.
+type Tool struct {
+	// Annotations optionally describe the behaviour of the tool.
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+
+	// Name uniquely identifies the tool.
+	Name string `json:"name"`
+	// Title is an optional human readable name.
+	Title string `json:"title,omitempty"`
+	// Description tells what the tool does.
+	Description string `json:"description,omitempty"`
+
+	// InputSchema is the JSON Schema of the arguments.
+	InputSchema json.RawMessage `json:"inputSchema"`
+	// OutputSchema is the optional JSON Schema of the structured content
+	// of the results. It must describe an object.
+	OutputSchema json.RawMessage `json:"outputSchema,omitempty"`
+}
+
+// ToolAnnotations are hints about the behaviour of a tool. They aren't
+// guaranteed to be accurate, and clients shouldn't trust them from
+// untrusted servers. Unset hints take the defaults of the MCP
+// specification.
+type ToolAnnotations struct {
+	// ReadOnlyHint tells the tool doesn't modify its environment.
+	// Defaults to false.
+	ReadOnlyHint *bool `json:"readOnlyHint,omitempty"`
+	// DestructiveHint tells the tool may perform destructive updates,
+	// only meaningful when not read-only. Defaults to true.
+	DestructiveHint *bool `json:"destructiveHint,omitempty"`
+	// IdempotentHint tells repeated calls with the same arguments have
+	// no additional effect, only meaningful when not read-only.
+	// Defaults to false.
+	IdempotentHint *bool `json:"idempotentHint,omitempty"`
+	// OpenWorldHint tells the tool may interact with external entities.
+	// Defaults to true.
+	OpenWorldHint *bool `json:"openWorldHint,omitempty"`
+	// Title is an optional human readable name.
+	Title string `json:"title,omitempty"`
+}
+
+// Bool returns a pointer to v, for the hints of ToolAnnotations.
+func Bool(v bool) *bool {
+	return &v
+}
+
+// ListToolsResult is the result of tools/list.
+type ListToolsResult struct {
+	NextCursor string  `json:"nextCursor,omitempty"`
+	Tools      []*Tool `json:"tools"`
+}
+
+// CallToolParams are the params of tools/call.
+type CallToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// CallToolResult is the result of tools/call. The response message is
+// returned as text and, when encoded as a JSON object and the negotiated
+// protocol revision allows it, as structured content.
+type CallToolResult struct {
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	Content           []Content       `json:"content"`
+	IsError           bool            `json:"isError,omitempty"`
+}
+
+// toolEntry is a registered tool.
+type toolEntry struct {
+	tool *Tool
+	h    MethodHandler
+}
+
+// AddTool registers a tool, replacing any previous one of the same name.
+// Arguments are decoded into the request message of h, and the response
+// message returned as result.
+func (s *MCPServer) AddTool(tool *Tool, h MethodHandler) {
+	if tool == nil || tool.Name == "" || h == nil {
+		panic(errors.New("protomcp: invalid tool"))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tools.len() == 0 {
+		s.d.HandleFunc(MethodToolsList, s.handleListTools)
+		s.d.HandleFunc(MethodToolsCall, s.handleCallTool)
+	}
+	if s.caps.Tools == nil {
+		s.caps.Tools = &ToolsCapability{}
+	}
+
+	s.tools.add(tool.Name, &toolEntry{tool: tool, h: h})
+}
+
+// Tools returns the registered tools.
+func (s *MCPServer) Tools() []*Tool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return mapValues(&s.tools, func(e *toolEntry) *Tool { return e.tool })
+}
+
+// tool returns a registered tool by name.
+func (s *MCPServer) tool(name string) (*toolEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.tools.get(name)
+}
+
+// handleListTools implements tools/list.
+func (s *MCPServer) handleListTools(ctx context.Context, params json.RawMessage) (any, error) {
+	var p PaginatedParams
+	if err := unmarshalOptionalParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	tools, next, err := paginate(s.Tools(), p.Cursor, s.pageSize())
+	if err != nil {
+		return nil, err
+	}
+	return &ListToolsResult{Tools: compatibleTools(ctx, tools), NextCursor: next}, nil
+}
+
+// handleCallTool implements tools/call. Errors decoding the arguments are
+// reported as protocol errors, while those returned by the tool are
+// reported in the result so the model can see them.
+func (s *MCPServer) handleCallTool(ctx context.Context, params json.RawMessage) (any, error) {
+	var p CallToolParams
+	if err := unmarshalParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	e, ok := s.tool(p.Name)
+	if !ok {
+		return nil, NewError(CodeInvalidParams, "unknown tool: %s", p.Name)
+	}
+
+	resp, err := e.h(ctx, s.d.decoder(p.Arguments))
+	switch {
+	case isInvalidParams(err):
+		return nil, err
+	case err != nil:
+		return toolError(err), nil
+	}
+
+	raw, err := s.d.marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CallToolResult{
+		Content: []Content{TextContent(string(raw))},
+	}
+	if jsonKind(raw) == '{' && structuredOutput(ctx) {
+		result.StructuredContent = raw
+	}
+	return result, nil
+}
+
+// structuredOutput tells if the protocol revision negotiated by the
+// session supports output schemas and structured content.
+func structuredOutput(ctx context.Context) bool {
+	return sessionVersion(ctx) >= ProtocolVersion20250618
+}
+
+// sessionVersion returns the protocol revision negotiated by the session,
+// or the latest if there is none.
+func sessionVersion(ctx context.Context) string {
+	if sess, ok := SessionFromContext(ctx); ok {
+		return sess.ProtocolVersion()
+	}
+	return LatestProtocolVersion
+}
+
+// compatibleTools returns the tools as understood by the protocol
+// revision negotiated by the session, copying those needing changes.
+func compatibleTools(ctx context.Context, tools []*Tool) []*Tool {
+	version := sessionVersion(ctx)
+	if version >= ProtocolVersion20250618 {
+		return tools
+	}
+
+	out := make([]*Tool, len(tools))
+	for i, t := range tools {
+		c := *t
+		c.Title = ""
+		c.OutputSchema = nil
+		if version < ProtocolVersion20250326 {
+			c.Annotations = nil
+		}
+		out[i] = &c
+	}
+	return out
+}
+
+// toolError returns the result reporting a tool failure.
+func toolError(err error) *CallToolResult {
+	return &CallToolResult{
+		Content: []Content{TextContent(err.Error())},
+		IsError: true,
+	}
+}
+
+// isInvalidParams tells if an error is a CodeInvalidParams Error.
+func isInvalidParams(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == CodeInvalidParams
+}
+
+// pageSize returns the effective PageSize.
+func (s *MCPServer) pageSize() int {
+	if s.PageSize > 0 {
+		return s.PageSize
+	}
+	return DefaultPageSize
+}
+
+// unmarshalOptionalParams decodes the params of an MCP request, if any.
+func unmarshalOptionalParams(params json.RawMessage, v any) error {
+	if k := jsonKind(params); k == 0 || k == 'n' {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return NewError(CodeInvalidParams, "invalid params: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package protomcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// newTestToolServer returns an initialised MCP peer with n echo tools
+// plus a failing one.
+func newTestToolServer(t *testing.T, n int, pageSize int) (*MCPServer, *testPeer) {
+	t.Helper()
+
+	s := newTestTools(n, pageSize)
+	p := newTestPeer(t, s)
+	p.initialize(t)
+	return s, p
+}
+
+// newTestTools returns an MCPServer with n echo tools plus a failing one.
+func newTestTools(n int, pageSize int) *MCPServer {
+	s := newTestMCPServer()
+	s.PageSize = pageSize
+	for i := range n {
+		s.AddTool(&Tool{
+			Name:         fmt.Sprintf("echo%d", i),
+			Description:  "Echoes its arguments.",
+			InputSchema:  json.RawMessage(`{"type":"object"}`),
+			OutputSchema: json.RawMessage(`{"type":"object"}`),
+		}, NewMethodHandler(func(_ context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+			return req, nil
+		}))
+	}
+	s.AddTool(&Tool{
+		Name:        "fail",
+		InputSchema: json.RawMessage(`{"type":"object"}`),
+	}, NewMethodHandler(func(context.Context, *structpb.Struct) (*structpb.Struct, error) {
+		return nil, errors.New("boom")
+	}))
+	return s
+}
+
+func TestMCPServerAddTool(t *testing.T) {
+	s, _ := newTestToolServer(t, 2, 0)
+
+	if caps := s.Capabilities(); caps.Tools == nil {
+		t.Error("tools capability not advertised")
+	}
+
+	// replacing keeps the position
+	s.AddTool(&Tool{Name: "echo0", Title: "Echo"}, NewMethodHandler(
+		func(_ context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+			return req, nil
+		}))
+
+	tools := s.Tools()
+	if len(tools) != 3 || tools[0].Title != "Echo" {
+		t.Errorf("Tools() = %v", tools)
+	}
+}
+
+func TestMCPServerListTools(t *testing.T) {
+	t.Run("single page", testMCPServerListToolsSingle)
+	t.Run("paginated", testMCPServerListToolsPaginated)
+	t.Run("invalid cursor", testMCPServerListToolsInvalidCursor)
+	t.Run("annotations", testMCPServerListToolsAnnotations)
+}
+
+func testMCPServerListToolsSingle(t *testing.T) {
+	_, p := newTestToolServer(t, 1, 0)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	resp.assertResult(t, "1", `{"tools":[`+
+		`{"name":"echo0","description":"Echoes its arguments.",`+
+		`"inputSchema":{"type":"object"},"outputSchema":{"type":"object"}},`+
+		`{"name":"fail","inputSchema":{"type":"object"}}]}`)
+}
+
+func testMCPServerListToolsPaginated(t *testing.T) {
+	_, p := newTestToolServer(t, 4, 2)
+
+	var names []string
+	cursor := ""
+	for range 5 {
+		result := p.listTools(t, cursor)
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+
+		cursor = result.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	if got := fmt.Sprint(names); got != "[echo0 echo1 echo2 echo3 fail]" {
+		t.Errorf("tools = %s", got)
+	}
+}
+
+// listTools requests a page of tools.
+func (p *testPeer) listTools(t *testing.T, cursor string) *ListToolsResult {
+	t.Helper()
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"tools/list","params":{"cursor":"`+cursor+`"}}`)
+	if resp.Error != nil {
+		t.Fatalf("tools/list: %v", resp.Error)
+	}
+
+	var result ListToolsResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("tools/list: %v", err)
+	}
+	return &result
+}
+
+func testMCPServerListToolsInvalidCursor(t *testing.T) {
+	_, p := newTestToolServer(t, 1, 0)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"tools/list","params":{"cursor":"!!"}}`)
+	resp.assertError(t, "1", CodeInvalidParams)
+}
+
+// listToolsAnnotationsTestCase represents a test case for the tools/list
+// annotations, title and output schema of each protocol revision
+type listToolsAnnotationsTestCase struct {
+	version string
+	want    string
+}
+
+// test runs the tools/list annotations test case
+func (tc listToolsAnnotationsTestCase) test(t *testing.T) {
+	s := NewMCPServer("test-server", "0.1.0")
+	s.ProtocolVersions = []string{tc.version}
+	s.AddTool(&Tool{
+		Name:         "get",
+		Title:        "Get",
+		InputSchema:  json.RawMessage(`{"type":"object"}`),
+		OutputSchema: json.RawMessage(`{"type":"object"}`),
+		Annotations: &ToolAnnotations{
+			ReadOnlyHint:  Bool(true),
+			OpenWorldHint: Bool(false),
+		},
+	}, NewMethodHandler(func(_ context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+		return req, nil
+	}))
+
+	p := newTestPeer(t, s)
+	p.initialize(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	resp.assertResult(t, "1", tc.want)
+}
+
+func testMCPServerListToolsAnnotations(t *testing.T) {
+	tests := []listToolsAnnotationsTestCase{
+		{ProtocolVersion20250618, `{"tools":[{"annotations":{"readOnlyHint":true,"openWorldHint":false},` +
+			`"name":"get","title":"Get","inputSchema":{"type":"object"},"outputSchema":{"type":"object"}}]}`},
+		{ProtocolVersion20250326, `{"tools":[{"annotations":{"readOnlyHint":true,"openWorldHint":false},` +
+			`"name":"get","inputSchema":{"type":"object"}}]}`},
+		{ProtocolVersion20241105, `{"tools":[{"name":"get","inputSchema":{"type":"object"}}]}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.version, tc.test)
+	}
+}
+
+func TestMCPServerCallTool(t *testing.T) {
+	t.Run("success", testMCPServerCallToolSuccess)
+	t.Run("tool error", testMCPServerCallToolError)
+	t.Run("unknown tool", testMCPServerCallToolUnknown)
+	t.Run("invalid arguments", testMCPServerCallToolInvalidArguments)
+	t.Run("non-object result", testMCPServerCallToolNonObject)
+	t.Run("older revision", testMCPServerCallToolOlderRevision)
+}
+
+func testMCPServerCallToolSuccess(t *testing.T) {
+	_, p := newTestToolServer(t, 1, 0)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"tools/call",`+
+		`"params":{"name":"echo0","arguments":{"a":"b"}}}`)
+	resp.assertResult(t, "1", `{"structuredContent":{"a":"b"},`+
+		`"content":[{"type":"text","text":"{\"a\":\"b\"}"}]}`)
+}
+
+func testMCPServerCallToolError(t *testing.T) {
+	_, p := newTestToolServer(t, 0, 0)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"fail"}}`)
+	resp.assertResult(t, "1", `{"content":[{"type":"text","text":"boom"}],"isError":true}`)
+}
+
+func testMCPServerCallToolUnknown(t *testing.T) {
+	_, p := newTestToolServer(t, 0, 0)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"nope"}}`)
+	resp.assertError(t, "1", CodeInvalidParams)
+}
+
+func testMCPServerCallToolInvalidArguments(t *testing.T) {
+	_, p := newTestToolServer(t, 1, 0)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo0","arguments":[1]}}`)
+	resp.assertError(t, "1", CodeInvalidParams)
+}
+
+func testMCPServerCallToolNonObject(t *testing.T) {
+	s, p := newTestToolServer(t, 0, 0)
+	s.AddTool(&Tool{
+		Name:        "hello",
+		InputSchema: json.RawMessage(`{"type":"object"}`),
+	}, NewMethodHandler(func(context.Context, *emptypb.Empty) (*wrapperspb.StringValue, error) {
+		return wrapperspb.String("hi"), nil
+	}))
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"hello"}}`)
+	resp.assertResult(t, "1", `{"content":[{"type":"text","text":"\"hi\""}]}`)
+}
+
+func testMCPServerCallToolOlderRevision(t *testing.T) {
+	s := newTestTools(1, 0)
+	s.ProtocolVersions = []string{ProtocolVersion20250326}
+	p := newTestPeer(t, s)
+	p.initialize(t)
+
+	resp := p.call(t, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	resp.assertResult(t, "1", `{"tools":[`+
+		`{"name":"echo0","description":"Echoes its arguments.","inputSchema":{"type":"object"}},`+
+		`{"name":"fail","inputSchema":{"type":"object"}}]}`)
+
+	resp = p.call(t, `{"jsonrpc":"2.0","id":2,"method":"tools/call",`+
+		`"params":{"name":"echo0","arguments":{"a":"b"}}}`)
+	resp.assertResult(t, "2", `{"content":[{"type":"text","text":"{\"a\":\"b\"}"}]}`)
+
+	if tools := s.Tools(); tools[0].OutputSchema == nil {
+		t.Error("registered tool modified")
+	}
+}
+
+func TestTextContent(t *testing.T) {
+	b, err := json.Marshal(TextContent("hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"type":"text","text":"hi"}`; got != want {
+		t.Errorf("TextContent() = %s, want %s", got, want)
+	}
+}
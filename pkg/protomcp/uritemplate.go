@@ -0,0 +1,14 @@
+package protomcp
+
+import "protomcp.org/protomcp/pkg/protomcp/template"
+
+// URITemplate is a parsed RFC 6570 URI template, as used by MCP resource
+// templates. Only simple `{var}` and reserved `{+var}` expressions of a
+// single variable are supported. Simple values can't span path segments,
+// while reserved ones match any text.
+type URITemplate = template.URI
+
+// ParseURITemplate parses a URI template.
+func ParseURITemplate(s string) (*URITemplate, error) {
+	return template.ParseURI(s)
+}
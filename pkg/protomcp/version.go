@@ -0,0 +1,39 @@
+package protomcp
+
+import "slices"
+
+// MCP specification revisions supported by this package.
+const (
+	ProtocolVersion20250618 = "2025-06-18"
+	ProtocolVersion20250326 = "2025-03-26"
+	ProtocolVersion20241105 = "2024-11-05"
+
+	// LatestProtocolVersion is the most recent supported revision.
+	LatestProtocolVersion = ProtocolVersion20250618
+)
+
+// SupportedProtocolVersions returns the MCP revisions supported by this
+// package, most recent first.
+func SupportedProtocolVersions() []string {
+	return []string{
+		ProtocolVersion20250618,
+		ProtocolVersion20250326,
+		ProtocolVersion20241105,
+	}
+}
+
+// NegotiateProtocolVersion picks the revision to use for a session given
+// the one requested by the client and those supported by the server, most
+// preferred first. As mandated by the specification, the requested revision
+// is used if supported, otherwise the server's preferred one is offered and
+// it's up to the client to disconnect if it can't use it.
+func NegotiateProtocolVersion(requested string, supported []string) string {
+	switch {
+	case len(supported) == 0:
+		return ""
+	case slices.Contains(supported, requested):
+		return requested
+	default:
+		return supported[0]
+	}
+}
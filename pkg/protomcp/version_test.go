@@ -0,0 +1,39 @@
+package protomcp
+
+import "testing"
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		want      string
+		supported []string
+	}{
+		{"supported", "2025-03-26", "2025-03-26", SupportedProtocolVersions()},
+		{"unsupported", "2023-01-01", LatestProtocolVersion, SupportedProtocolVersions()},
+		{"empty", "", LatestProtocolVersion, SupportedProtocolVersions()},
+		{"preference", "2025-06-18", "2024-11-05", []string{"2024-11-05"}},
+		{"none supported", "2025-06-18", "", nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := NegotiateProtocolVersion(tc.requested, tc.supported); got != tc.want {
+				t.Errorf("NegotiateProtocolVersion() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSupportedProtocolVersions(t *testing.T) {
+	versions := SupportedProtocolVersions()
+	if len(versions) == 0 || versions[0] != LatestProtocolVersion {
+		t.Fatalf("SupportedProtocolVersions() = %v, want %q first", versions, LatestProtocolVersion)
+	}
+
+	// callers can't alter the list
+	versions[0] = "bogus"
+	if SupportedProtocolVersions()[0] != LatestProtocolVersion {
+		t.Error("SupportedProtocolVersions() returned shared storage")
+	}
+}
@@ -0,0 +1,246 @@
+package protomcp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+var (
+	_ http.Handler          = (*WebSocketHandler)(nil)
+	_ jsonrpc2.ObjectStream = (*wsStream)(nil)
+)
+
+// Keepalive of WebSocket connections when WebSocketHandler.PingInterval
+// and WebSocketHandler.PongTimeout aren't set.
+const (
+	DefaultPingInterval = 30 * time.Second
+	DefaultPongTimeout  = 10 * time.Second
+)
+
+// WebSocketSubprotocols are the WebSocket subprotocols accepted by a
+// WebSocketHandler, if the client asks for one.
+var WebSocketSubprotocols = []string{"mcp", "jsonrpc"}
+
+// WebSocketHandler serves JSON-RPC 2.0 and MCP over WebSocket, a
+// connection per client carrying a JSON-RPC message or batch per
+// WebSocket message in each direction, so browsers can hold a session
+// receiving the requests and notifications of the server.
+//
+// Clients are pinged every PingInterval, and their connection is closed
+// if they don't reply within PongTimeout.
+//
+// The exported fields must not be changed once the handler is serving.
+type WebSocketHandler struct {
+	server ConnServer
+	conns  map[*jsonrpc2.Conn]struct{}
+
+	// CheckOrigin tells if a WebSocket request is accepted from the
+	// origin of a browser. Nil only accepts requests from the same
+	// host.
+	CheckOrigin func(r *http.Request) bool
+
+	// MaxMessageSize is the maximum size of the messages of clients.
+	// Zero means DefaultMaxBodySize.
+	MaxMessageSize int64
+
+	// PingInterval is the time between pings. Zero means
+	// DefaultPingInterval, and negative disables pings.
+	PingInterval time.Duration
+
+	// PongTimeout is how long the response to a ping is waited for.
+	// Zero means DefaultPongTimeout.
+	PongTimeout time.Duration
+
+	// WriteTimeout bounds the writing of each message. Zero means no
+	// timeout.
+	WriteTimeout time.Duration
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewWebSocketHandler returns a WebSocketHandler serving server, usually a
+// Dispatcher or an MCPServer.
+func NewWebSocketHandler(server ConnServer) *WebSocketHandler {
+	return &WebSocketHandler{
+		server: server,
+		conns:  make(map[*jsonrpc2.Conn]struct{}),
+	}
+}
+
+// ServeHTTP implements http.Handler, serving the connection of a
+// WebSocket request until closed.
+func (h *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u := websocket.Upgrader{
+		Subprotocols: WebSocketSubprotocols,
+		CheckOrigin:  h.CheckOrigin,
+	}
+
+	ws, err := u.Upgrade(w, r, nil)
+	if err != nil {
+		// already replied
+		return
+	}
+
+	ws.SetReadLimit(h.maxMessageSize())
+	s := &wsStream{ws: ws, done: make(chan struct{}), writeTimeout: h.WriteTimeout}
+	if interval := h.pingInterval(); interval > 0 {
+		s.keepalive(interval, h.pongTimeout())
+	}
+
+	conn := h.server.ServeConn(r.Context(), s)
+	if !h.track(conn) {
+		_ = conn.Close()
+	}
+	defer h.untrack(conn)
+
+	<-conn.DisconnectNotify()
+}
+
+// maxMessageSize returns the effective MaxMessageSize.
+func (h *WebSocketHandler) maxMessageSize() int64 {
+	if h.MaxMessageSize <= 0 {
+		return DefaultMaxBodySize
+	}
+	return h.MaxMessageSize
+}
+
+// pingInterval returns the effective PingInterval.
+func (h *WebSocketHandler) pingInterval() time.Duration {
+	if h.PingInterval == 0 {
+		return DefaultPingInterval
+	}
+	return h.PingInterval
+}
+
+// pongTimeout returns the effective PongTimeout.
+func (h *WebSocketHandler) pongTimeout() time.Duration {
+	if h.PongTimeout <= 0 {
+		return DefaultPongTimeout
+	}
+	return h.PongTimeout
+}
+
+// track records a connection, unless the handler is closed.
+func (h *WebSocketHandler) track(conn *jsonrpc2.Conn) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return false
+	}
+	h.conns[conn] = struct{}{}
+	return true
+}
+
+// untrack forgets a connection.
+func (h *WebSocketHandler) untrack(conn *jsonrpc2.Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.conns, conn)
+}
+
+// Close closes all connections, and rejects new ones.
+func (h *WebSocketHandler) Close() error {
+	h.mu.Lock()
+	h.closed = true
+	conns := slices.Collect(maps.Keys(h.conns))
+	h.mu.Unlock()
+
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+	return nil
+}
+
+// wsStream is the jsonrpc2.ObjectStream of a WebSocket connection, a
+// JSON-RPC message or batch per WebSocket message.
+type wsStream struct {
+	ws           *websocket.Conn
+	done         chan struct{}
+	writeTimeout time.Duration
+	mu           sync.Mutex // serialises writes
+	closeOnce    sync.Once
+}
+
+// ReadObject implements jsonrpc2.ObjectStream. Clients closing the
+// connection end the stream.
+func (s *wsStream) ReadObject(v any) error {
+	_, data, err := s.ws.ReadMessage()
+	var ce *websocket.CloseError
+	switch {
+	case errors.As(err, &ce) && slices.Contains([]int{
+		websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived,
+	}, ce.Code):
+		return io.EOF
+	case err != nil:
+		return err
+	}
+	return decodeMessage(data, v)
+}
+
+// WriteObject implements jsonrpc2.ObjectStream.
+func (s *wsStream) WriteObject(obj any) error {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.writeTimeout > 0 {
+		_ = s.ws.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+	}
+	return s.ws.WriteMessage(websocket.TextMessage, b)
+}
+
+// Close implements jsonrpc2.ObjectStream, telling the client the
+// connection is closing.
+func (s *wsStream) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		_ = s.ws.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		err = s.ws.Close()
+	})
+	return err
+}
+
+// keepalive pings the client every interval, making reads fail unless it
+// replies within timeout. Must be called before reading.
+func (s *wsStream) keepalive(interval, timeout time.Duration) {
+	extend := func(string) error {
+		return s.ws.SetReadDeadline(time.Now().Add(interval + timeout))
+	}
+	s.ws.SetPongHandler(extend)
+	_ = extend("")
+
+	go s.ping(interval, timeout)
+}
+
+// ping pings the client every interval until the stream is closed.
+func (s *wsStream) ping(interval, timeout time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			_ = s.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(timeout))
+		case <-s.done:
+			return
+		}
+	}
+}
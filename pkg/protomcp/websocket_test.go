@@ -0,0 +1,240 @@
+package protomcp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// testWebSocket is a WebSocket client of a WebSocketHandler.
+type testWebSocket struct {
+	h  *WebSocketHandler
+	ws *websocket.Conn
+}
+
+// newTestWebSocket connects to a WebSocketHandler serving s.
+func newTestWebSocket(t *testing.T, h *WebSocketHandler, subprotocols ...string) *testWebSocket {
+	t.Helper()
+
+	ts := httptest.NewServer(h)
+	t.Cleanup(func() {
+		_ = h.Close()
+		ts.Close()
+	})
+
+	d := websocket.Dialer{Subprotocols: subprotocols, HandshakeTimeout: testTimeout}
+	ws, resp, err := d.Dial("ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	t.Cleanup(func() { _ = ws.Close() })
+	return &testWebSocket{h: h, ws: ws}
+}
+
+// send sends a message.
+func (c *testWebSocket) send(t *testing.T, msg string) {
+	t.Helper()
+
+	if err := c.ws.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// recv receives a message.
+func (c *testWebSocket) recv(t *testing.T) json.RawMessage {
+	t.Helper()
+
+	_ = c.ws.SetReadDeadline(time.Now().Add(testTimeout))
+	_, data, err := c.ws.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// assertClosed checks the server closes the connection with a code.
+func (c *testWebSocket) assertClosed(t *testing.T, code int) {
+	t.Helper()
+
+	_ = c.ws.SetReadDeadline(time.Now().Add(testTimeout))
+	_, data, err := c.ws.ReadMessage()
+	var ce *websocket.CloseError
+	if !errors.As(err, &ce) || ce.Code != code {
+		t.Errorf("got %s %v, want close %d", data, err, code)
+	}
+}
+
+func TestWebSocketHandler(t *testing.T) {
+	t.Run("jsonrpc", testWebSocketJSONRPC)
+	t.Run("mcp", testWebSocketMCP)
+	t.Run("client request", testWebSocketClientRequest)
+	t.Run("subprotocol", testWebSocketSubprotocol)
+	t.Run("max message size", testWebSocketMaxMessageSize)
+	t.Run("keepalive", testWebSocketKeepalive)
+	t.Run("pong timeout", testWebSocketPongTimeout)
+	t.Run("close", testWebSocketClose)
+}
+
+func testWebSocketJSONRPC(t *testing.T) {
+	c := newTestWebSocket(t, NewWebSocketHandler(newTestDispatcher()))
+
+	c.send(t, `{"jsonrpc":"2.0","id":1,"method":"test.Echo/Echo","params":{"a":"b"}}`)
+	if got, want := string(c.recv(t)), `{"id":1,"result":{"a":"b"},"jsonrpc":"2.0"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	c.send(t, `{"jsonrpc":`)
+	want := `{"id":null,"error":{"code":-32700,"message":"parse error"},"jsonrpc":"2.0"}`
+	if got := string(c.recv(t)); got != want {
+		t.Errorf("parse error: got %s, want %s", got, want)
+	}
+}
+
+// initialize initialises the MCP session of the connection.
+func (c *testWebSocket) initialize(t *testing.T, caps string) {
+	t.Helper()
+
+	c.send(t, `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{`+
+		`"protocolVersion":"2025-06-18","capabilities":`+caps+`,`+
+		`"clientInfo":{"name":"test","version":"1.0"}}}`)
+	assertResponseID(t, c.recv(t), "0")
+	c.send(t, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+}
+
+func testWebSocketMCP(t *testing.T) {
+	c := newTestWebSocket(t, NewWebSocketHandler(newTestHTTPServer()))
+	c.initialize(t, `{}`)
+
+	c.send(t, `{"jsonrpc":"2.0","id":1,"method":"tools/call",`+
+		`"params":{"name":"reindex","_meta":{"progressToken":"t1"}}}`)
+	assertNotification(t, c.recv(t), MethodProgress,
+		`{"message":"half","progressToken":"t1","progress":1,"total":2}`)
+	assertResponseID(t, c.recv(t), "1")
+}
+
+func testWebSocketClientRequest(t *testing.T) {
+	c := newTestWebSocket(t, NewWebSocketHandler(newTestHTTPServer()))
+	c.initialize(t, `{"sampling":{}}`)
+
+	c.send(t, `{"jsonrpc":"2.0","id":1,"method":"summarise"}`)
+	assertRequest(t, c.recv(t), "1", MethodSamplingCreateMessage,
+		`{"messages":[{"role":"user","content":{"type":"text","text":"Summarise this."}}],"maxTokens":100}`)
+
+	c.send(t, `{"jsonrpc":"2.0","id":1,"result":{"role":"assistant",`+
+		`"content":{"type":"text","text":"Done."},"model":"test"}}`)
+	assertResponseID(t, c.recv(t), "1")
+}
+
+func testWebSocketSubprotocol(t *testing.T) {
+	c := newTestWebSocket(t, NewWebSocketHandler(newTestMCPServer()), "mcp")
+
+	if got := c.ws.Subprotocol(); got != "mcp" {
+		t.Errorf("got %q, want %q", got, "mcp")
+	}
+}
+
+func testWebSocketMaxMessageSize(t *testing.T) {
+	h := NewWebSocketHandler(newTestDispatcher())
+	h.MaxMessageSize = 64
+	c := newTestWebSocket(t, h)
+
+	c.send(t, `{"jsonrpc":"2.0","id":1,"method":"test.Echo/Echo","params":{"a":"`+strings.Repeat("b", 64)+`"}}`)
+	c.assertClosed(t, websocket.CloseMessageTooBig)
+}
+
+// readLoop reads messages in the background, answering pings, until the
+// connection is closed. Pings received are reported to pings.
+func (c *testWebSocket) readLoop(pings chan<- struct{}) <-chan json.RawMessage {
+	c.ws.SetPingHandler(func(data string) error {
+		select {
+		case pings <- struct{}{}:
+		default:
+		}
+		return c.ws.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(testTimeout))
+	})
+
+	messages := make(chan json.RawMessage)
+	go func() {
+		defer close(messages)
+		for {
+			_, data, err := c.ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			messages <- data
+		}
+	}()
+	return messages
+}
+
+func testWebSocketKeepalive(t *testing.T) {
+	h := NewWebSocketHandler(newTestDispatcher())
+	h.PingInterval = 10 * time.Millisecond
+	h.PongTimeout = 10 * time.Millisecond
+	c := newTestWebSocket(t, h)
+
+	pings := make(chan struct{}, 1)
+	messages := c.readLoop(pings)
+
+	time.Sleep(100 * time.Millisecond)
+	c.send(t, `{"jsonrpc":"2.0","id":1,"method":"test.Echo/Echo"}`)
+	got, ok := <-messages
+	if !ok {
+		t.Fatal("connection closed")
+	}
+	assertResponseID(t, got, "1")
+
+	select {
+	case <-pings:
+	default:
+		t.Error("no ping received")
+	}
+}
+
+func testWebSocketPongTimeout(t *testing.T) {
+	h := NewWebSocketHandler(newTestDispatcher())
+	h.PingInterval = 10 * time.Millisecond
+	h.PongTimeout = 10 * time.Millisecond
+	c := newTestWebSocket(t, h)
+
+	// pings aren't answered while not reading
+	time.Sleep(100 * time.Millisecond)
+	_ = c.ws.SetReadDeadline(time.Now().Add(testTimeout))
+	if _, _, err := c.ws.ReadMessage(); err == nil || errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("got %v, want connection closed", err)
+	}
+}
+
+func testWebSocketClose(t *testing.T) {
+	c := newTestWebSocket(t, NewWebSocketHandler(newTestMCPServer()))
+	c.send(t, `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	assertResponseID(t, c.recv(t), "1")
+
+	if err := c.h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	c.assertClosed(t, websocket.CloseNormalClosure)
+}
+
+func TestWebSocketHandlerOrigin(t *testing.T) {
+	h := NewWebSocketHandler(newTestDispatcher())
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	hdr := http.Header{"Origin": []string{"https://example.com"}}
+	_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http"), hdr)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("got %v, want %d", err, http.StatusForbidden)
+	}
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+}